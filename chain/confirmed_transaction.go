@@ -1,18 +1,55 @@
 package chain
 
 import (
+	"io"
+
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/kyokan/plasma/util"
 )
 
+// ConfirmedTransaction holds one confirm signature per input of
+// Transaction. Legacy transactions always have two, even if their second
+// input is a zero input.
 type ConfirmedTransaction struct {
 	Transaction Transaction
-	Signatures  [2]Signature
+	Signatures  []Signature
 }
 
 type rlpConfirmedTransaction struct {
-	Transaction rlpTransaction
-	Signatures  [2]Signature
+	Transaction interface{}
+	Signatures  []Signature
+}
+
+// ConfirmSigAt returns the confirm signature of input idx, or an empty
+// signature if it hasn't been set.
+func (c *ConfirmedTransaction) ConfirmSigAt(idx uint8) Signature {
+	if int(idx) >= len(c.Signatures) {
+		return Signature{}
+	}
+	return c.Signatures[idx]
+}
+
+// SetConfirmSig sets the confirm signature of input idx.
+func (c *ConfirmedTransaction) SetConfirmSig(idx uint8, sig Signature) {
+	c.Signatures = c.confirmSigs()
+	c.Signatures[idx] = sig
+}
+
+// confirmSigs returns a copy of Signatures with exactly one entry per input.
+func (c *ConfirmedTransaction) confirmSigs() []Signature {
+	sigs := make([]Signature, c.Transaction.NumInputs())
+	copy(sigs, c.Signatures)
+	return sigs
+}
+
+func (c *ConfirmedTransaction) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, struct {
+		Transaction *Transaction
+		Signatures  []Signature
+	}{
+		Transaction: &c.Transaction,
+		Signatures:  c.confirmSigs(),
+	})
 }
 
 func (c *ConfirmedTransaction) RLPHash(hasher util.Hasher) util.Hash {
@@ -22,8 +59,8 @@ func (c *ConfirmedTransaction) RLPHash(hasher util.Hasher) util.Hash {
 
 func (c *ConfirmedTransaction) RLP() []byte {
 	bytes, err := rlp.EncodeToBytes(rlpConfirmedTransaction{
-		Transaction: c.Transaction.rlpRepresentation(),
-		Signatures:  c.Signatures,
+		Transaction: c.Transaction.rlpValue(),
+		Signatures:  c.confirmSigs(),
 	})
 
 	if err != nil {
@@ -0,0 +1,118 @@
+// +build gofuzz
+
+package chain
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kyokan/plasma/util"
+)
+
+// The functions below are go-fuzz entry points, built with:
+//
+//   go-fuzz-build -func FuzzTransaction github.com/kyokan/plasma/chain
+//   go-fuzz -bin chain-fuzz.zip -workdir fuzz/transaction
+//
+// Each decodes data and panics if encoding the result again isn't stable.
+
+// FuzzTransaction decodes data as a stored confirmed transaction. Amounts
+// above 2^63 don't survive decoding, so stability is checked from the decoded
+// form rather than data.
+func FuzzTransaction(data []byte) int {
+	var confirmed ConfirmedTransaction
+	if err := rlp.DecodeBytes(data, &confirmed); err != nil {
+		return 0
+	}
+	enc := mustEncode(&confirmed)
+	var decoded ConfirmedTransaction
+	if err := rlp.DecodeBytes(enc, &decoded); err != nil {
+		panic(err)
+	}
+	if !bytes.Equal(enc, mustEncode(&decoded)) {
+		panic("storage encoding changed")
+	}
+	if !bytes.Equal(confirmed.RLP(), decoded.RLP()) {
+		panic("contract encoding changed")
+	}
+	if !bytes.Equal(confirmed.Transaction.SignatureHash(), decoded.Transaction.SignatureHash()) {
+		panic("signature hash changed")
+	}
+	if !bytes.Equal(confirmed.RLPHash(util.Sha256), decoded.RLPHash(util.Sha256)) {
+		panic("transaction hash changed")
+	}
+	return 1
+}
+
+func FuzzInput(data []byte) int {
+	var input Input
+	if err := rlp.DecodeBytes(data, &input); err != nil {
+		return 0
+	}
+	var decoded Input
+	if err := rlp.DecodeBytes(mustEncode(&input), &decoded); err != nil {
+		panic(err)
+	}
+	if !bytes.Equal(mustEncode(&input), mustEncode(&decoded)) {
+		panic("input encoding changed")
+	}
+	if !bytes.Equal(input.SignatureHash(), decoded.SignatureHash()) {
+		panic("input signature hash changed")
+	}
+	return 1
+}
+
+func FuzzBlockMetadata(data []byte) int {
+	var meta BlockMetadata
+	if err := meta.FromRLP(data); err != nil {
+		return 0
+	}
+	enc, err := meta.RLP()
+	if err != nil {
+		panic(err)
+	}
+	var decoded BlockMetadata
+	if err := decoded.FromRLP(enc); err != nil {
+		panic(err)
+	}
+	reEnc, err := decoded.RLP()
+	if err != nil {
+		panic(err)
+	}
+	if !bytes.Equal(enc, reEnc) {
+		panic("block metadata encoding changed")
+	}
+	return 1
+}
+
+func FuzzSpendIdentifier(data []byte) int {
+	var id SpendIdentifier
+	if err := id.UnmarshalBinary(data); err != nil {
+		return 0
+	}
+	var decoded SpendIdentifier
+	if err := rlp.DecodeBytes(mustEncode(&id), &decoded); err != nil {
+		panic(err)
+	}
+	bin, err := decoded.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	var unmarshaled SpendIdentifier
+	if err := unmarshaled.UnmarshalBinary(bin); err != nil {
+		panic(err)
+	}
+	if !reflect.DeepEqual(id, decoded) || !reflect.DeepEqual(id, unmarshaled) {
+		panic("spend identifier changed")
+	}
+	return 1
+}
+
+func mustEncode(val interface{}) []byte {
+	enc, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
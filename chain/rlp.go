@@ -1,6 +1,8 @@
 package chain
 
 import (
+	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"io"
@@ -66,7 +68,31 @@ type rlpTransactionHelper struct {
 	Fee *UInt256 // transaction
 }
 
+// Multi transaction storage encoding. Unlike the legacy encoding, its first
+// element is the version byte rather than a 32-byte string:
+// [Version, [[Blknum, TxIndex, Oindex, DepositNonce, Owner], ...], [Sig, ...],
+//  [[Owner, Denom], ...], Fee]
+type rlpMultiTransactionHelper struct {
+	Version uint8
+	Inputs  []rlpMultiInputHelper
+	Sigs    []Signature
+	Outputs []rlpMultiOutput
+	Fee     *UInt256
+}
+
+type rlpMultiInputHelper struct {
+	BlkNum       *UInt256
+	TxIdx        *UInt256
+	OutIdx       *UInt256
+	DepositNonce *UInt256
+	Owner        common.Address
+}
+
 func (tx *Transaction) EncodeRLP(w io.Writer) error {
+	if tx.IsMulti() {
+		return tx.encodeMultiRLP(w)
+	}
+
 	var itf rlpTransactionHelper
 	if tx.Input0 != nil {
 		itf.BlkNum0 = NewUint256(util.Uint642Big(tx.Input0.BlkNum))
@@ -111,12 +137,68 @@ func (tx *Transaction) EncodeRLP(w io.Writer) error {
 	return rlp.Encode(w, &itf)
 }
 
+func (tx *Transaction) encodeMultiRLP(w io.Writer) error {
+	itf := rlpMultiTransactionHelper{
+		Version: tx.Version,
+		Inputs:  make([]rlpMultiInputHelper, len(tx.Inputs)),
+		Sigs:    tx.Sigs,
+		Outputs: make([]rlpMultiOutput, len(tx.Outputs)),
+		Fee:     NewUint256(tx.Fee),
+	}
+	if itf.Sigs == nil {
+		itf.Sigs = []Signature{}
+	}
+	for i, input := range tx.Inputs {
+		itf.Inputs[i] = rlpMultiInputHelper{
+			BlkNum:       NewUint256(util.Uint642Big(input.BlkNum)),
+			TxIdx:        NewUint256(util.Uint322Big(input.TxIdx)),
+			OutIdx:       NewUint256(util.Uint82Big(input.OutIdx)),
+			DepositNonce: NewUint256(input.DepositNonce),
+			Owner:        input.Owner,
+		}
+	}
+	for i, output := range tx.Outputs {
+		itf.Outputs[i] = rlpMultiOutput{
+			Owner:  output.Owner,
+			Amount: NewUint256(output.Denom),
+		}
+	}
+
+	return rlp.Encode(w, &itf)
+}
+
 func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
+	raw, err := s.Raw()
+	if err != nil {
+		return err
+	}
+	content, _, err := rlp.SplitList(raw)
+	if err != nil {
+		return err
+	}
+	kind, _, _, err := rlp.Split(content)
+	if err != nil {
+		return err
+	}
+	if kind == rlp.Byte {
+		return tx.decodeMultiRLP(raw)
+	}
+
 	var itf rlpTransactionHelper
-	err := s.Decode(&itf)
+	err = rlp.DecodeBytes(raw, &itf)
 	if err != nil {
 		return err
 	}
+	for _, idx := range []*UInt256{itf.TxIdx0, itf.TxIdx1} {
+		if idx.ToBig().Cmp(util.MaxUint32) == 1 {
+			return errors.New("transaction index overflows uint32")
+		}
+	}
+	for _, idx := range []*UInt256{itf.OutIdx0, itf.OutIdx1} {
+		if idx.ToBig().Cmp(util.MaxUint8) == 1 {
+			return errors.New("output index overflows uint8")
+		}
+	}
 	tx.Input0 = NewInput(
 		util.Big2Uint64(itf.BlkNum0.ToBig()),
 		util.Big2Uint32(itf.TxIdx0.ToBig()),
@@ -131,6 +213,10 @@ func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
 		itf.DepositNonce1.ToBig(),
 		itf.Owner1,
 	)
+	tx.Version = TxVersionLegacy
+	tx.Inputs = nil
+	tx.Sigs = nil
+	tx.Outputs = nil
 	tx.Output0 = NewOutput(itf.NewOwner0, itf.Denom0.ToBig(), Zero())
 	tx.Output1 = NewOutput(itf.NewOwner1, itf.Denom1.ToBig(), Zero())
 	tx.Sig0 = itf.Sig0
@@ -139,3 +225,40 @@ func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
 
 	return nil
 }
+
+func (tx *Transaction) decodeMultiRLP(raw []byte) error {
+	var itf rlpMultiTransactionHelper
+	if err := rlp.DecodeBytes(raw, &itf); err != nil {
+		return err
+	}
+	if itf.Version != TxVersionMulti {
+		return fmt.Errorf("unsupported transaction version %d", itf.Version)
+	}
+	if len(itf.Inputs) > MaxMultiInputs || len(itf.Outputs) > MaxMultiOutputs {
+		return errors.New("too many inputs or outputs")
+	}
+
+	inputs := make([]*Input, len(itf.Inputs))
+	for i, input := range itf.Inputs {
+		if input.TxIdx.ToBig().Cmp(util.MaxUint32) == 1 {
+			return errors.New("transaction index overflows uint32")
+		}
+		if input.OutIdx.ToBig().Cmp(util.MaxUint8) == 1 {
+			return errors.New("output index overflows uint8")
+		}
+		inputs[i] = NewInput(
+			util.Big2Uint64(input.BlkNum.ToBig()),
+			util.Big2Uint32(input.TxIdx.ToBig()),
+			util.Big2Uint8(input.OutIdx.ToBig()),
+			input.DepositNonce.ToBig(),
+			input.Owner,
+		)
+	}
+	outputs := make([]*Output, len(itf.Outputs))
+	for i, output := range itf.Outputs {
+		outputs[i] = NewOutput(output.Owner, output.Amount.ToBig(), Zero())
+	}
+
+	*tx = *NewMultiTransaction(inputs, itf.Sigs, outputs, itf.Fee.ToBig())
+	return nil
+}
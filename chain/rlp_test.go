@@ -1,14 +1,18 @@
 package chain
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"io/ioutil"
 	"math/big"
 	"math/rand"
 	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kyokan/plasma/util"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
@@ -67,8 +71,8 @@ func Test_TransactionFullRLP(t *testing.T) {
 		Output0: RandomOutput(),
 		Output1: RandomOutput(),
 		Fee:     big.NewInt(rand.Int63()),
-		BlkNum:  nil, // Not encoded in RLP
-		TxIdx:   nil, // Not encoded in RLP
+		BlkNum:  0, // Not encoded in RLP
+		TxIdx:   0, // Not encoded in RLP
 	}
 	encodeAndDecode(t, &tx)
 }
@@ -81,8 +85,8 @@ func Test_TransactionFirstInputRLP(t *testing.T) {
 		Output0: RandomOutput(),
 		Output1: ZeroOutput(),
 		Fee:     big.NewInt(rand.Int63()),
-		BlkNum:  nil,
-		TxIdx:   nil,
+		BlkNum:  0,
+		TxIdx:   0,
 	}
 	encodeAndDecode(t, &tx)
 }
@@ -95,8 +99,8 @@ func Test_TransactionSecondInputRLP(t *testing.T) {
 		Output0: ZeroOutput(),
 		Output1: RandomOutput(),
 		Fee:     big.NewInt(rand.Int63()),
-		BlkNum:  nil,
-		TxIdx:   nil,
+		BlkNum:  0,
+		TxIdx:   0,
 	}
 	encodeAndDecode(t, &tx)
 }
@@ -111,10 +115,10 @@ func Test_ConfirmedTransactionRLP(t *testing.T) {
 			Output0: RandomOutput(),
 			Output1: RandomOutput(),
 			Fee:     big.NewInt(rand.Int63()),
-			BlkNum:  nil, // Not encoded in RLP
-			TxIdx:   nil, // Not encoded in RLP
+			BlkNum:  0, // Not encoded in RLP
+			TxIdx:   0, // Not encoded in RLP
 		},
-		Signatures: [2]Signature{RandomConfirmationSig(), RandomConfirmationSig()},
+		Signatures: []Signature{RandomConfirmationSig(), RandomConfirmationSig()},
 	}
 	encodeAndDecode(t, &confirmed)
 }
@@ -128,10 +132,10 @@ func Test_ConfirmedTransactionFirstInputRLP(t *testing.T) {
 			Output0: RandomOutput(),
 			Output1: RandomOutput(),
 			Fee:     big.NewInt(rand.Int63()),
-			BlkNum:  nil, // Not encoded in RLP
-			TxIdx:   nil, // Not encoded in RLP
+			BlkNum:  0, // Not encoded in RLP
+			TxIdx:   0, // Not encoded in RLP
 		},
-		Signatures: [2]Signature{RandomConfirmationSig(), },
+		Signatures: []Signature{RandomConfirmationSig(), {}},
 	}
 	encodeAndDecode(t, &confirmed)
 }
@@ -169,7 +173,295 @@ func Test_BlockRLP(t *testing.T) {
 	encodeAndDecode(t, &blk)
 }
 
+// The vectors in testdata/transactions.json were generated independently of
+// this package and pin the encoding the rootchain contract decodes.
+func TestGoldenTransactions(t *testing.T) {
+	for _, golden := range loadGoldenTransactions(t) {
+		confirmed := golden.confirmed(t)
+		tx := &confirmed.Transaction
+		require.Equal(t, golden.RLP, hex.EncodeToString(tx.RLP()), golden.Name)
+		require.Equal(t, golden.SignatureHash, hex.EncodeToString(tx.SignatureHash()), golden.Name)
+		require.Equal(t, golden.ConfirmedRLP, hex.EncodeToString(confirmed.RLP()), golden.Name)
+		require.Equal(t, golden.ConfirmedHash, hex.EncodeToString(confirmed.RLPHash(util.Sha256)), golden.Name)
+		requireStableEncoding(t, confirmed)
+	}
+}
+
+func TestTransactionRLPProperties(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		requireStableEncoding(t, randomConfirmedTransaction())
+	}
+}
+
+func TestInputRLPProperties(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		requireStableInput(t, RandomInput())
+	}
+}
+
+func TestBlockMetadataRLPProperties(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		requireStableBlockMetadata(t, &BlockMetadata{
+			CreatedAt:        rand.Uint64(),
+			TransactionCount: rand.Uint32(),
+			Fees:             new(big.Int).SetBytes(RandomSig()),
+		})
+	}
+}
+
+func TestSpendIdentifierProperties(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		requireStableSpendIdentifier(t, &SpendIdentifier{
+			BlockNumber:      rand.Uint64(),
+			TransactionIndex: rand.Uint32(),
+			InputIndex:       uint8(rand.Intn(2)),
+		})
+	}
+}
+
+func TestTransactionDecodeOverflow(t *testing.T) {
+	tx := randomConfirmedTransaction().Transaction
+	enc, err := rlp.EncodeToBytes(&tx)
+	require.NoError(t, err)
+
+	var itf rlpTransactionHelper
+	require.NoError(t, rlp.DecodeBytes(enc, &itf))
+	itf.OutIdx1 = NewUint256(big.NewInt(256))
+	enc, err = rlp.EncodeToBytes(&itf)
+	require.NoError(t, err)
+
+	var decoded Transaction
+	require.Error(t, rlp.DecodeBytes(enc, &decoded))
+}
+
+func TestMultiTransactionRLP(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		confirmed := randomMultiTransaction()
+		require.NoError(t, confirmed.Transaction.Validate())
+		requireStableEncoding(t, confirmed)
+
+		enc, err := rlp.EncodeToBytes(confirmed)
+		require.NoError(t, err)
+		var decoded ConfirmedTransaction
+		require.NoError(t, rlp.DecodeBytes(enc, &decoded))
+		require.True(t, decoded.Transaction.IsMulti())
+		require.Equal(t, confirmed.Transaction.NumInputs(), decoded.Transaction.NumInputs())
+		require.Equal(t, confirmed.Transaction.NumOutputs(), decoded.Transaction.NumOutputs())
+		require.Equal(t, confirmed.Transaction.Sigs, decoded.Transaction.Sigs)
+		require.Equal(t, confirmed.Signatures, decoded.Signatures)
+		require.Equal(t, confirmed.Transaction.SigningHash(), decoded.Transaction.SigningHash())
+		for j := uint8(0); j < decoded.Transaction.NumInputs(); j++ {
+			require.Equal(t, confirmed.Transaction.InputAt(j).Owner, decoded.Transaction.InputAt(j).Owner)
+		}
+	}
+}
+
+func TestMultiTransactionValidate(t *testing.T) {
+	tx := randomMultiTransaction().Transaction
+	tx.Sigs = tx.Sigs[1:]
+	require.Error(t, tx.Validate())
+
+	tx = randomMultiTransaction().Transaction
+	tx.Inputs = append(tx.Inputs, tx.Inputs[0])
+	tx.Sigs = append(tx.Sigs, tx.Sigs[0])
+	require.Error(t, tx.Validate())
+
+	tx = randomMultiTransaction().Transaction
+	tx.Outputs[0].Denom = big.NewInt(0)
+	require.Error(t, tx.Validate())
+
+	tx = randomMultiTransaction().Transaction
+	tx.Fee = big.NewInt(-1)
+	require.Error(t, tx.Validate())
+}
+
 //Helpers
+type goldenInput struct {
+	BlkNum       uint64 `json:"blkNum"`
+	TxIdx        uint32 `json:"txIdx"`
+	OutIdx       uint8  `json:"outIdx"`
+	DepositNonce string `json:"depositNonce"`
+}
+
+type goldenOutput struct {
+	Owner  string `json:"owner"`
+	Amount string `json:"amount"`
+}
+
+type goldenTransaction struct {
+	Name          string       `json:"name"`
+	Input0        goldenInput  `json:"input0"`
+	Sig0          string       `json:"sig0"`
+	Input1        goldenInput  `json:"input1"`
+	Sig1          string       `json:"sig1"`
+	Output0       goldenOutput `json:"output0"`
+	Output1       goldenOutput `json:"output1"`
+	Fee           string       `json:"fee"`
+	ConfirmSig0   string       `json:"confirmSig0"`
+	ConfirmSig1   string       `json:"confirmSig1"`
+	RLP           string       `json:"rlp"`
+	SignatureHash string       `json:"signatureHash"`
+	ConfirmedRLP  string       `json:"confirmedRLP"`
+	ConfirmedHash string       `json:"confirmedHash"`
+}
+
+func loadGoldenTransactions(t require.TestingT) []goldenTransaction {
+	data, err := ioutil.ReadFile("testdata/transactions.json")
+	require.NoError(t, err)
+	var res []goldenTransaction
+	require.NoError(t, json.Unmarshal(data, &res))
+	return res
+}
+
+func (g *goldenTransaction) confirmed(t require.TestingT) *ConfirmedTransaction {
+	return &ConfirmedTransaction{
+		Transaction: Transaction{
+			Input0:  g.Input0.input(t),
+			Sig0:    goldenSignature(t, g.Sig0),
+			Input1:  g.Input1.input(t),
+			Sig1:    goldenSignature(t, g.Sig1),
+			Output0: g.Output0.output(t),
+			Output1: g.Output1.output(t),
+			Fee:     goldenBig(t, g.Fee),
+		},
+		Signatures: []Signature{
+			goldenSignature(t, g.ConfirmSig0),
+			goldenSignature(t, g.ConfirmSig1),
+		},
+	}
+}
+
+func (g *goldenInput) input(t require.TestingT) *Input {
+	return NewInput(g.BlkNum, g.TxIdx, g.OutIdx, goldenBig(t, g.DepositNonce), common.Address{})
+}
+
+func (g *goldenOutput) output(t require.TestingT) *Output {
+	owner, err := hex.DecodeString(g.Owner)
+	require.NoError(t, err)
+	return NewOutput(common.BytesToAddress(owner), goldenBig(t, g.Amount), Zero())
+}
+
+func goldenBig(t require.TestingT, s string) *big.Int {
+	res, ok := new(big.Int).SetString(s, 10)
+	require.True(t, ok, "invalid number %s", s)
+	return res
+}
+
+func goldenSignature(t require.TestingT, s string) Signature {
+	var sig Signature
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	require.Len(t, b, len(sig))
+	copy(sig[:], b)
+	return sig
+}
+
+func randomConfirmedTransaction() *ConfirmedTransaction {
+	tx := Transaction{
+		Input0:  RandomInput(),
+		Sig0:    RandomConfirmationSig(),
+		Input1:  ZeroInput(),
+		Output0: RandomOutput(),
+		Output1: ZeroOutput(),
+		Fee:     big.NewInt(rand.Int63()),
+	}
+	if rand.Intn(2) == 1 {
+		tx.Input1 = RandomInput()
+		tx.Sig1 = RandomConfirmationSig()
+	}
+	if rand.Intn(2) == 1 {
+		tx.Output1 = RandomOutput()
+	}
+	return &ConfirmedTransaction{
+		Transaction: tx,
+		Signatures:  []Signature{RandomConfirmationSig(), RandomConfirmationSig()},
+	}
+}
+
+func randomMultiTransaction() *ConfirmedTransaction {
+	var inputs []*Input
+	var sigs []Signature
+	for i := 0; i < 1+rand.Intn(MaxMultiInputs); i++ {
+		input := RandomInput()
+		input.DepositNonce = big.NewInt(0)
+		input.TxIdx = uint32(i)
+		inputs = append(inputs, input)
+		sigs = append(sigs, RandomConfirmationSig())
+	}
+	var outputs []*Output
+	for i := 0; i < 1+rand.Intn(MaxMultiOutputs); i++ {
+		output := RandomOutput()
+		output.Denom = big.NewInt(1 + rand.Int63())
+		outputs = append(outputs, output)
+	}
+	confirmSigs := make([]Signature, len(inputs))
+	for i := range confirmSigs {
+		confirmSigs[i] = RandomConfirmationSig()
+	}
+	return &ConfirmedTransaction{
+		Transaction: *NewMultiTransaction(inputs, sigs, outputs, big.NewInt(rand.Int63())),
+		Signatures:  confirmSigs,
+	}
+}
+
+// requireStableEncoding checks that a round trip through the storage encoding
+// changes neither that encoding nor the contract encoding of confirmed.
+func requireStableEncoding(t *testing.T, confirmed *ConfirmedTransaction) {
+	enc, err := rlp.EncodeToBytes(confirmed)
+	require.NoError(t, err)
+	var decoded ConfirmedTransaction
+	require.NoError(t, rlp.DecodeBytes(enc, &decoded))
+	reEnc, err := rlp.EncodeToBytes(&decoded)
+	require.NoError(t, err)
+
+	require.Equal(t, enc, reEnc)
+	require.Equal(t, confirmed.RLP(), decoded.RLP())
+	require.Equal(t, confirmed.Transaction.SignatureHash(), decoded.Transaction.SignatureHash())
+	require.Equal(t, confirmed.RLPHash(util.Sha256), decoded.RLPHash(util.Sha256))
+}
+
+func requireStableInput(t *testing.T, input *Input) {
+	enc, err := rlp.EncodeToBytes(input)
+	require.NoError(t, err)
+	var decoded Input
+	require.NoError(t, rlp.DecodeBytes(enc, &decoded))
+
+	require.Equal(t, input.BlkNum, decoded.BlkNum)
+	require.Equal(t, input.TxIdx, decoded.TxIdx)
+	require.Equal(t, input.OutIdx, decoded.OutIdx)
+	require.Equal(t, input.Owner, decoded.Owner)
+	require.Equal(t, 0, input.DepositNonce.Cmp(decoded.DepositNonce))
+	require.Equal(t, input.SignatureHash(), decoded.SignatureHash())
+}
+
+func requireStableBlockMetadata(t *testing.T, meta *BlockMetadata) {
+	enc, err := meta.RLP()
+	require.NoError(t, err)
+	var decoded BlockMetadata
+	require.NoError(t, decoded.FromRLP(enc))
+	reEnc, err := decoded.RLP()
+	require.NoError(t, err)
+
+	require.Equal(t, enc, reEnc)
+	require.Equal(t, meta.CreatedAt, decoded.CreatedAt)
+	require.Equal(t, meta.TransactionCount, decoded.TransactionCount)
+	require.Equal(t, 0, meta.Fees.Cmp(decoded.Fees))
+}
+
+func requireStableSpendIdentifier(t *testing.T, id *SpendIdentifier) {
+	enc, err := rlp.EncodeToBytes(id)
+	require.NoError(t, err)
+	var decoded SpendIdentifier
+	require.NoError(t, rlp.DecodeBytes(enc, &decoded))
+	require.Equal(t, *id, decoded)
+
+	bin, err := id.MarshalBinary()
+	require.NoError(t, err)
+	var unmarshaled SpendIdentifier
+	require.NoError(t, unmarshaled.UnmarshalBinary(bin))
+	require.Equal(t, *id, unmarshaled)
+}
+
 func encodeAndDecode(t *testing.T, itf interface{}) {
 	v := reflect.ValueOf(itf)
 	kind := v.Type().Kind()
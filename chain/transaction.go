@@ -1,7 +1,8 @@
 package chain
 
 import (
-				"fmt"
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -9,18 +10,63 @@ import (
 	"github.com/kyokan/plasma/util"
 		)
 
+const (
+	// TxVersionLegacy transactions have exactly two inputs and two outputs,
+	// some of which may be zero, and are the only ones the rootchain
+	// contract can decode.
+	TxVersionLegacy uint8 = 0
+	// TxVersionMulti transactions have between one and MaxMultiInputs
+	// inputs and between one and MaxMultiOutputs outputs.
+	TxVersionMulti uint8 = 1
+)
+
+const MaxMultiInputs = 16
+
+const MaxMultiOutputs = 16
+
+// Transaction is either a legacy transaction, which uses the numbered input,
+// signature and output fields, or a multi transaction, which uses Inputs,
+// Sigs and Outputs. The numbered fields of a multi transaction are zero
+// inputs and outputs, so it is never a deposit or an exit.
 type Transaction struct {
+	Version uint8
 	Input0  *Input
 	Sig0    Signature
 	Input1  *Input
 	Sig1    Signature
 	Output0 *Output
 	Output1 *Output
+	Inputs  []*Input
+	Sigs    []Signature
+	Outputs []*Output
 	Fee     *big.Int
 	BlkNum  uint64
 	TxIdx   uint32
 }
 
+// Multi transaction encoding:
+// [Version, [[Blknum, TxIndex, Oindex, DepositNonce], ...], [Sig, ...],
+//  [[Owner, Denom], ...], Fee]
+type rlpMultiTransaction struct {
+	Version uint8
+	Inputs  []rlpMultiInput
+	Sigs    []Signature
+	Outputs []rlpMultiOutput
+	Fee     *UInt256
+}
+
+type rlpMultiInput struct {
+	BlkNum       *UInt256
+	TxIdx        *UInt256
+	OutIdx       *UInt256
+	DepositNonce *UInt256
+}
+
+type rlpMultiOutput struct {
+	Owner  common.Address
+	Amount *UInt256
+}
+
 type rlpTransaction struct {
 	BlkNum0       *UInt256
 	TxIdx0        *UInt256
@@ -49,6 +95,40 @@ func ZeroTransaction() *Transaction {
 	}
 }
 
+// NewMultiTransaction returns a TxVersionMulti transaction. sigs may be nil
+// if the transaction is going to be signed later with SigningHash.
+func NewMultiTransaction(inputs []*Input, sigs []Signature, outputs []*Output, fee *big.Int) *Transaction {
+	tx := ZeroTransaction()
+	tx.Version = TxVersionMulti
+	tx.Inputs = inputs
+	tx.Sigs = sigs
+	tx.Outputs = outputs
+	tx.Fee = fee
+	return tx
+}
+
+func (tx *Transaction) IsMulti() bool {
+	return tx.Version == TxVersionMulti
+}
+
+// NumInputs returns the number of input slots. Legacy transactions always
+// have two, either of which may be a zero input.
+func (tx *Transaction) NumInputs() uint8 {
+	if tx.IsMulti() {
+		return uint8(len(tx.Inputs))
+	}
+	return 2
+}
+
+// NumOutputs returns the number of output slots. Legacy transactions always
+// have two, either of which may be a zero output.
+func (tx *Transaction) NumOutputs() uint8 {
+	if tx.IsMulti() {
+		return uint8(len(tx.Outputs))
+	}
+	return 2
+}
+
 func (tx *Transaction) IsDeposit() bool {
 	return tx.Output0.IsDeposit()
 }
@@ -65,6 +145,9 @@ func (tx *Transaction) GetFee() *big.Int {
 }
 
 func (tx *Transaction) IsZeroTransaction() bool {
+	if tx.IsMulti() {
+		return false
+	}
 	if tx.IsDeposit() {
 		return false
 	}
@@ -75,6 +158,13 @@ func (tx *Transaction) IsZeroTransaction() bool {
 }
 
 func (tx *Transaction) InputAt(idx uint8) *Input {
+	if tx.IsMulti() {
+		if int(idx) >= len(tx.Inputs) {
+			panic(fmt.Sprint("Invalid input index: ", idx))
+		}
+		return tx.Inputs[idx]
+	}
+
 	if idx != 0 && idx != 1 {
 		panic(fmt.Sprint("Invalid input index: ", idx))
 	}
@@ -87,6 +177,13 @@ func (tx *Transaction) InputAt(idx uint8) *Input {
 }
 
 func (tx *Transaction) OutputAt(idx uint8) *Output {
+	if tx.IsMulti() {
+		if int(idx) >= len(tx.Outputs) {
+			panic(fmt.Sprint("Invalid output index: ", idx))
+		}
+		return tx.Outputs[idx]
+	}
+
 	if idx == 0 {
 		return tx.Output0
 	}
@@ -94,30 +191,69 @@ func (tx *Transaction) OutputAt(idx uint8) *Output {
 	return tx.Output1
 }
 
-func (tx *Transaction) lookupOutput(addr *common.Address) (*Output, uint8) {
-	output := tx.OutputAt(0)
-
-	if util.AddressesEqual(&output.Owner, addr) {
-		return output, 0
+// SigAt returns the signature over input idx.
+func (tx *Transaction) SigAt(idx uint8) Signature {
+	if tx.IsMulti() {
+		if int(idx) >= len(tx.Sigs) {
+			return Signature{}
+		}
+		return tx.Sigs[idx]
 	}
-
-	output = tx.OutputAt(1)
-
-	if util.AddressesEqual(&output.Owner, addr) {
-		return output, 1
+	if idx == 0 {
+		return tx.Sig0
 	}
-
-	panic(fmt.Sprint("No output found for address: ", addr.Hex()))
+	return tx.Sig1
 }
 
-func (tx *Transaction) OutputFor(addr *common.Address) *Output {
-	out, _ := tx.lookupOutput(addr)
-	return out
+// InputSigHash returns the hash the owner of input idx signs. Legacy inputs
+// sign only themselves, while every input of a multi transaction signs the
+// whole transaction.
+func (tx *Transaction) InputSigHash(idx uint8) util.Hash {
+	if tx.IsMulti() {
+		return tx.SigningHash()
+	}
+	return tx.InputAt(idx).SignatureHash()
 }
 
-func (tx *Transaction) OutputIndexFor(addr *common.Address) uint8 {
-	_, idx := tx.lookupOutput(addr)
-	return idx
+// Validate checks the structure of a multi transaction. Legacy transactions
+// are always structurally valid.
+func (tx *Transaction) Validate() error {
+	if !tx.IsMulti() {
+		return nil
+	}
+	if len(tx.Inputs) == 0 || len(tx.Inputs) > MaxMultiInputs {
+		return fmt.Errorf("transactions must have between 1 and %d inputs", MaxMultiInputs)
+	}
+	if len(tx.Outputs) == 0 || len(tx.Outputs) > MaxMultiOutputs {
+		return fmt.Errorf("transactions must have between 1 and %d outputs", MaxMultiOutputs)
+	}
+	if len(tx.Sigs) != len(tx.Inputs) {
+		return errors.New("transactions must have one signature per input")
+	}
+	if tx.Fee == nil || tx.Fee.Sign() < 0 {
+		return errors.New("fee must not be negative")
+	}
+
+	seen := make(map[string]bool)
+	for i, input := range tx.Inputs {
+		if input.IsZeroInput() {
+			return fmt.Errorf("input %d is empty", i)
+		}
+		key := fmt.Sprintf("%d:%d:%d", input.BlkNum, input.TxIdx, input.OutIdx)
+		if seen[key] {
+			return fmt.Errorf("input %d is spent twice", i)
+		}
+		seen[key] = true
+	}
+	for i, output := range tx.Outputs {
+		if output == nil || output.Denom == nil || output.Denom.Sign() <= 0 {
+			return fmt.Errorf("output %d must have a positive amount", i)
+		}
+		if output.IsExit() || output.IsDeposit() {
+			return fmt.Errorf("output %d cannot be an exit or a deposit", i)
+		}
+	}
+	return nil
 }
 
 func (tx *Transaction) rlpRepresentation() rlpTransaction {
@@ -140,12 +276,59 @@ func (tx *Transaction) rlpRepresentation() rlpTransaction {
 	}
 }
 
+func (tx *Transaction) multiRLPRepresentation(withSigs bool) rlpMultiTransaction {
+	res := rlpMultiTransaction{
+		Version: tx.Version,
+		Inputs:  make([]rlpMultiInput, len(tx.Inputs)),
+		Sigs:    []Signature{},
+		Outputs: make([]rlpMultiOutput, len(tx.Outputs)),
+		Fee:     NewUint256(tx.Fee),
+	}
+	for i, input := range tx.Inputs {
+		res.Inputs[i] = rlpMultiInput{
+			BlkNum:       NewUint256(util.Uint642Big(input.BlkNum)),
+			TxIdx:        NewUint256(util.Uint322Big(input.TxIdx)),
+			OutIdx:       NewUint256(util.Uint82Big(input.OutIdx)),
+			DepositNonce: NewUint256(input.DepositNonce),
+		}
+	}
+	if withSigs {
+		res.Sigs = tx.Sigs
+	}
+	for i, output := range tx.Outputs {
+		res.Outputs[i] = rlpMultiOutput{
+			Owner:  output.Owner,
+			Amount: NewUint256(output.Denom),
+		}
+	}
+	return res
+}
+
+// rlpValue returns the value whose RLP encoding is hashed into block merkle
+// roots and signed by confirmations.
+func (tx *Transaction) rlpValue() interface{} {
+	if tx.IsMulti() {
+		return tx.multiRLPRepresentation(true)
+	}
+	return tx.rlpRepresentation()
+}
+
+// SigningHash is the hash signed by every input of a multi transaction. It
+// covers the whole transaction except the input signatures.
+func (tx *Transaction) SigningHash() util.Hash {
+	bytes, err := rlp.EncodeToBytes(tx.multiRLPRepresentation(false))
+	if err != nil {
+		panic(err)
+	}
+	return util.Keccak256(bytes)
+}
+
 func (tx *Transaction) SignatureHash() util.Hash {
 	return tx.RLPHash(util.Keccak256)
 }
 
 func (tx *Transaction) RLP() []byte {
-	bytes, err := rlp.EncodeToBytes(tx.rlpRepresentation())
+	bytes, err := rlp.EncodeToBytes(tx.rlpValue())
 	if err != nil {
 		panic(err)
 	}
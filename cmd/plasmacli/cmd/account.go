@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type accountCmdOutput struct {
+	Address string `json:"address"`
+	Path    string `json:"path"`
+}
+
+func newAccountCmdOutput(acct accounts.Account) *accountCmdOutput {
+	return &accountCmdOutput{
+		Address: acct.Address.Hex(),
+		Path:    acct.URL.Path,
+	}
+}
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Manages encrypted keystore accounts",
+}
+
+var accountNewCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Creates a new account in the keystore",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ks, err := OpenKeystore(cmd)
+		if err != nil {
+			return err
+		}
+		pass, err := PassphraseSource(cmd).Passphrase("New passphrase: ", true)
+		if err != nil {
+			return err
+		}
+
+		acct, err := ks.NewAccount(pass)
+		if err != nil {
+			return errors.Wrap(err, "failed to create account")
+		}
+		return PrintJSON(newAccountCmdOutput(acct))
+	},
+}
+
+var accountImportCmd = &cobra.Command{
+	Use:   "import keyfile",
+	Short: "Encrypts a hex private key file into the keystore",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := homedir.Expand(args[0])
+		if err != nil {
+			return errors.Wrap(err, "couldn't expand homedir")
+		}
+		keyBytes, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read private key")
+		}
+		privKey, err := crypto.HexToECDSA(strings.TrimSpace(string(keyBytes)))
+		if err != nil {
+			return errors.Wrap(err, "failed to parse private key")
+		}
+
+		ks, err := OpenKeystore(cmd)
+		if err != nil {
+			return err
+		}
+		pass, err := PassphraseSource(cmd).Passphrase("New passphrase: ", true)
+		if err != nil {
+			return err
+		}
+
+		acct, err := ks.ImportECDSA(privKey, pass)
+		if err != nil {
+			return errors.Wrap(err, "failed to import private key")
+		}
+		return PrintJSON(newAccountCmdOutput(acct))
+	},
+}
+
+var accountListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the accounts in the keystore",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ks, err := OpenKeystore(cmd)
+		if err != nil {
+			return err
+		}
+
+		out := []*accountCmdOutput{}
+		for _, acct := range ks.Accounts() {
+			out = append(out, newAccountCmdOutput(acct))
+		}
+		return PrintJSON(out)
+	},
+}
+
+func init() {
+	accountCmd.AddCommand(accountNewCmd)
+	accountCmd.AddCommand(accountImportCmd)
+	accountCmd.AddCommand(accountListCmd)
+	rootCmd.AddCommand(accountCmd)
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/rpc"
+	"github.com/kyokan/plasma/rpc/pb"
+)
+
+const (
+	CoinSelectionBranchAndBound     = "branch-and-bound"
+	CoinSelectionSmallestSufficient = "smallest-sufficient"
+	CoinSelectionOldestFirst        = "oldest-first"
+	CoinSelectionLargestFirst       = "largest-first"
+)
+
+// bnbMaxTries bounds the branch-and-bound search so that wallets with many
+// outputs still select coins quickly.
+const bnbMaxTries = 100000
+
+// utxo is a single spendable output owned by the sender.
+type utxo struct {
+	BlkNum       uint64
+	TxIdx        uint32
+	OutIdx       uint8
+	Amount       *big.Int
+	DepositNonce *big.Int
+}
+
+func (u *utxo) String() string {
+	return fmt.Sprintf("%d:%d:%d", u.BlkNum, u.TxIdx, u.OutIdx)
+}
+
+// legacyMaxInputs is the number of inputs a legacy transaction can spend.
+// Coin selection uses no more unless multi-input transactions are allowed.
+const legacyMaxInputs = 2
+
+// coinSelector picks outputs whose amounts add up to at least target, using
+// at most maxInputs of them.
+type coinSelector func(utxos []utxo, target *big.Int, maxInputs int) ([]utxo, error)
+
+var coinSelectors = map[string]coinSelector{
+	CoinSelectionBranchAndBound:     selectBranchAndBound,
+	CoinSelectionSmallestSufficient: selectSmallestSufficient,
+	CoinSelectionOldestFirst:        selectOldestFirst,
+	CoinSelectionLargestFirst:       selectLargestFirst,
+}
+
+func coinSelectorNames() []string {
+	var names []string
+	for name := range coinSelectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// toUTXOs flattens the node's spendable transactions into outputs owned by
+// addr. The node lists a transaction once for each of its outputs that addr
+// owns, along with the index of that output. Nodes that predate output
+// indexes are handled by mapping repeated transactions to successive owned
+// outputs.
+func toUTXOs(res *pb.GetOutputsResponse, addr common.Address) []utxo {
+	confirmedTxs := rpc.DeserializeConfirmedTxs(res.ConfirmedTransactions)
+	if len(res.OutputIndexes) == len(confirmedTxs) {
+		ret := make([]utxo, 0, len(confirmedTxs))
+		for i, confirmed := range confirmedTxs {
+			tx := confirmed.Transaction
+			outIdx := uint8(res.OutputIndexes[i])
+			if outIdx >= tx.NumOutputs() || tx.OutputAt(outIdx).Owner != addr {
+				continue
+			}
+			ret = append(ret, newUTXO(&tx, outIdx))
+		}
+		return ret
+	}
+
+	seen := make(map[string]int)
+	var ret []utxo
+	for _, confirmed := range confirmedTxs {
+		tx := confirmed.Transaction
+		var owned []uint8
+		for i := uint8(0); i < tx.NumOutputs(); i++ {
+			if tx.OutputAt(i).Owner == addr {
+				owned = append(owned, i)
+			}
+		}
+		key := fmt.Sprintf("%d:%d", tx.BlkNum, tx.TxIdx)
+		n := seen[key]
+		seen[key]++
+		if n >= len(owned) {
+			continue
+		}
+
+		ret = append(ret, newUTXO(&tx, owned[n]))
+	}
+	return ret
+}
+
+func newUTXO(tx *chain.Transaction, outIdx uint8) utxo {
+	output := tx.OutputAt(outIdx)
+	return utxo{
+		BlkNum:       tx.BlkNum,
+		TxIdx:        tx.TxIdx,
+		OutIdx:       outIdx,
+		Amount:       output.Denom,
+		DepositNonce: output.DepositNonce,
+	}
+}
+
+// parseUTXO parses an output reference in blk:tx:out form.
+func parseUTXO(s string) (uint64, uint32, uint8, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, 0, 0, errors.New(fmt.Sprintf("invalid output %s, expected blk:tx:out", s))
+	}
+	blkNum, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, 0, errors.New(fmt.Sprintf("invalid block number in %s", s))
+	}
+	txIdx, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return 0, 0, 0, errors.New(fmt.Sprintf("invalid transaction index in %s", s))
+	}
+	outIdx, err := strconv.ParseUint(parts[2], 10, 8)
+	if err != nil {
+		return 0, 0, 0, errors.New(fmt.Sprintf("invalid output index in %s", s))
+	}
+	return blkNum, uint32(txIdx), uint8(outIdx), nil
+}
+
+// selectManual looks up the outputs named with --utxo among the spendable
+// ones, and checks that together they cover target.
+func selectManual(utxos []utxo, refs []string, target *big.Int, maxInputs int) ([]utxo, error) {
+	if len(refs) > maxInputs {
+		return nil, errors.New(fmt.Sprintf("cannot spend more than %d outputs", maxInputs))
+	}
+
+	var selected []utxo
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		blkNum, txIdx, outIdx, err := parseUTXO(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		var found *utxo
+		for i := range utxos {
+			u := &utxos[i]
+			if u.BlkNum == blkNum && u.TxIdx == txIdx && u.OutIdx == outIdx {
+				found = u
+				break
+			}
+		}
+		if found == nil {
+			return nil, errors.New(fmt.Sprintf("output %s is not spendable", ref))
+		}
+		if seen[found.String()] {
+			return nil, errors.New(fmt.Sprintf("output %s is selected twice", ref))
+		}
+		seen[found.String()] = true
+		selected = append(selected, *found)
+	}
+
+	if sumUTXOs(selected).Cmp(target) < 0 {
+		return nil, errors.New("selected outputs do not cover value plus fee")
+	}
+	return selected, nil
+}
+
+// selectBranchAndBound searches for outputs that add up to exactly target,
+// so that no change output is needed. If there is no exact match it falls
+// back to selectSmallestSufficient.
+func selectBranchAndBound(utxos []utxo, target *big.Int, maxInputs int) ([]utxo, error) {
+	sorted := sortedUTXOs(utxos, func(a, b *utxo) bool {
+		return a.Amount.Cmp(b.Amount) > 0
+	})
+
+	// remaining[i] is the sum of sorted[i:], used to prune branches that can
+	// no longer reach target.
+	remaining := make([]*big.Int, len(sorted)+1)
+	remaining[len(sorted)] = big.NewInt(0)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		remaining[i] = new(big.Int).Add(remaining[i+1], sorted[i].Amount)
+	}
+
+	var best []utxo
+	var picked []utxo
+	tries := 0
+	var search func(i int, sum *big.Int)
+	search = func(i int, sum *big.Int) {
+		tries++
+		if tries > bnbMaxTries {
+			return
+		}
+		if sum.Cmp(target) == 0 {
+			if best == nil || len(picked) < len(best) {
+				best = append([]utxo{}, picked...)
+			}
+			return
+		}
+		if i == len(sorted) || sum.Cmp(target) > 0 || len(picked) == maxInputs {
+			return
+		}
+		if best != nil && len(picked)+1 >= len(best) {
+			return
+		}
+		if new(big.Int).Add(sum, remaining[i]).Cmp(target) < 0 {
+			return
+		}
+
+		picked = append(picked, sorted[i])
+		search(i+1, new(big.Int).Add(sum, sorted[i].Amount))
+		picked = picked[:len(picked)-1]
+		search(i+1, sum)
+	}
+	search(0, big.NewInt(0))
+
+	if best != nil {
+		return best, nil
+	}
+	return selectSmallestSufficient(utxos, target, maxInputs)
+}
+
+// selectSmallestSufficient prefers the smallest single output that covers
+// target, then the pair with the smallest sum that does, and only spends
+// more outputs when no pair is enough.
+func selectSmallestSufficient(utxos []utxo, target *big.Int, maxInputs int) ([]utxo, error) {
+	sorted := sortedUTXOs(utxos, func(a, b *utxo) bool {
+		return a.Amount.Cmp(b.Amount) < 0
+	})
+
+	for _, u := range sorted {
+		if u.Amount.Cmp(target) >= 0 {
+			return []utxo{u}, nil
+		}
+	}
+
+	var best []utxo
+	var bestSum *big.Int
+	for i := 0; i < len(sorted) && maxInputs >= 2; i++ {
+		for j := i + 1; j < len(sorted); j++ {
+			sum := new(big.Int).Add(sorted[i].Amount, sorted[j].Amount)
+			if sum.Cmp(target) < 0 {
+				continue
+			}
+			if bestSum == nil || sum.Cmp(bestSum) < 0 {
+				best = []utxo{sorted[i], sorted[j]}
+				bestSum = sum
+			}
+			break
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+
+	return selectLargestFirst(utxos, target, maxInputs)
+}
+
+// selectOldestFirst spends the oldest outputs first. Old outputs are the
+// ones most exposed to a fraudulent exit, so moving them reduces the amount
+// at risk.
+func selectOldestFirst(utxos []utxo, target *big.Int, maxInputs int) ([]utxo, error) {
+	sorted := sortedUTXOs(utxos, func(a, b *utxo) bool {
+		if a.BlkNum != b.BlkNum {
+			return a.BlkNum < b.BlkNum
+		}
+		if a.TxIdx != b.TxIdx {
+			return a.TxIdx < b.TxIdx
+		}
+		return a.OutIdx < b.OutIdx
+	})
+	return accumulate(sorted, target, maxInputs)
+}
+
+// selectLargestFirst spends the largest outputs first, which uses as few
+// inputs as possible.
+func selectLargestFirst(utxos []utxo, target *big.Int, maxInputs int) ([]utxo, error) {
+	sorted := sortedUTXOs(utxos, func(a, b *utxo) bool {
+		return a.Amount.Cmp(b.Amount) > 0
+	})
+	return accumulate(sorted, target, maxInputs)
+}
+
+func accumulate(sorted []utxo, target *big.Int, maxInputs int) ([]utxo, error) {
+	var selected []utxo
+	sum := big.NewInt(0)
+	for i := 0; i < len(sorted) && i < maxInputs; i++ {
+		selected = append(selected, sorted[i])
+		sum = sum.Add(sum, sorted[i].Amount)
+		if sum.Cmp(target) >= 0 {
+			return selected, nil
+		}
+	}
+
+	return nil, errors.New("no suitable UTXOs found")
+}
+
+func sortedUTXOs(utxos []utxo, less func(a, b *utxo) bool) []utxo {
+	sorted := append([]utxo{}, utxos...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(&sorted[i], &sorted[j])
+	})
+	return sorted
+}
+
+func sumUTXOs(utxos []utxo) *big.Int {
+	sum := big.NewInt(0)
+	for _, u := range utxos {
+		sum = sum.Add(sum, u.Amount)
+	}
+	return sum
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/rpc"
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	alice = common.HexToAddress("0x627306090abab3a6e1400e9345bc60c78a8bef57")
+	bob   = common.HexToAddress("0xf17f52151ebef6c7334fad080c5704d77216b732")
+)
+
+// testUTXOs returns one output per amount, the first in block 1, the
+// second in block 2 and so on.
+func testUTXOs(amounts ...int64) []utxo {
+	utxos := make([]utxo, len(amounts))
+	for i, amount := range amounts {
+		utxos[i] = utxo{
+			BlkNum: uint64(i + 1),
+			Amount: big.NewInt(amount),
+		}
+	}
+	return utxos
+}
+
+func utxoRefs(utxos []utxo) []string {
+	var refs []string
+	for _, u := range utxos {
+		refs = append(refs, u.String())
+	}
+	return refs
+}
+
+func TestCoinSelection(t *testing.T) {
+	utxos := testUTXOs(10, 30, 50, 20)
+	many := testUTXOs(1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1)
+
+	legacy, multi := legacyMaxInputs, chain.MaxMultiInputs
+	tests := []struct {
+		name      string
+		strategy  string
+		utxos     []utxo
+		target    int64
+		maxInputs int
+		expected  []string
+	}{
+		{"exact single output", CoinSelectionBranchAndBound, utxos, 50, legacy, []string{"3:0:0"}},
+		{"exact pair", CoinSelectionBranchAndBound, utxos, 60, legacy, []string{"3:0:0", "1:0:0"}},
+		{"no exact match", CoinSelectionBranchAndBound, utxos, 55, legacy, []string{"1:0:0", "3:0:0"}},
+		{"exact match needs three outputs", CoinSelectionBranchAndBound, utxos, 100, legacy, nil},
+		{"exact match of three outputs", CoinSelectionBranchAndBound, utxos, 100, multi, []string{"3:0:0", "2:0:0", "4:0:0"}},
+		{"smallest single output", CoinSelectionSmallestSufficient, utxos, 25, legacy, []string{"2:0:0"}},
+		{"smallest pair", CoinSelectionSmallestSufficient, utxos, 75, legacy, []string{"2:0:0", "3:0:0"}},
+		{"no sufficient pair", CoinSelectionSmallestSufficient, utxos, 100, legacy, nil},
+		{"no sufficient pair with multi inputs", CoinSelectionSmallestSufficient, utxos, 100, multi, []string{"3:0:0", "2:0:0", "4:0:0"}},
+		{"oldest first", CoinSelectionOldestFirst, utxos, 35, legacy, []string{"1:0:0", "2:0:0"}},
+		{"oldest first needs three outputs", CoinSelectionOldestFirst, utxos, 85, legacy, nil},
+		{"largest first", CoinSelectionLargestFirst, utxos, 35, legacy, []string{"3:0:0"}},
+		{"insufficient funds", CoinSelectionLargestFirst, utxos, 111, multi, nil},
+		{"too many inputs", CoinSelectionOldestFirst, many, 17, multi, nil},
+		{"too many inputs for an exact match", CoinSelectionBranchAndBound, many, 17, multi, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := coinSelectors[tt.strategy](tt.utxos, big.NewInt(tt.target), tt.maxInputs)
+			if tt.expected == nil {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, utxoRefs(selected))
+		})
+	}
+}
+
+func TestSelectManual(t *testing.T) {
+	utxos := testUTXOs(10, 30, 50)
+
+	tests := []struct {
+		name     string
+		refs     []string
+		target   int64
+		expected []string
+	}{
+		{"covers target", []string{"1:0:0", "2:0:0"}, 40, []string{"1:0:0", "2:0:0"}},
+		{"keeps the given order", []string{"3:0:0", "1:0:0"}, 40, []string{"3:0:0", "1:0:0"}},
+		{"does not cover target", []string{"1:0:0", "2:0:0"}, 41, nil},
+		{"not spendable", []string{"4:0:0"}, 0, nil},
+		{"selected twice", []string{"1:0:0", "1:0:0"}, 0, nil},
+		{"more than two outputs", []string{"1:0:0", "2:0:0", "3:0:0"}, 0, nil},
+		{"malformed", []string{"1:0"}, 0, nil},
+		{"invalid output index", []string{"1:0:256"}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := selectManual(utxos, tt.refs, big.NewInt(tt.target), legacyMaxInputs)
+			if tt.expected == nil {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, utxoRefs(selected))
+		})
+	}
+}
+
+func TestToUTXOs(t *testing.T) {
+	// alice owns both outputs of the first transaction and one of the second
+	split := chain.ZeroTransaction()
+	split.BlkNum = 3
+	split.Output0 = chain.NewOutput(alice, big.NewInt(25), big.NewInt(0))
+	split.Output1 = chain.NewOutput(alice, big.NewInt(35), big.NewInt(0))
+	payment := chain.ZeroTransaction()
+	payment.BlkNum = 4
+	payment.Output0 = chain.NewOutput(bob, big.NewInt(10), big.NewInt(0))
+	payment.Output1 = chain.NewOutput(alice, big.NewInt(5), big.NewInt(0))
+	txs := []chain.ConfirmedTransaction{
+		{Transaction: *split},
+		{Transaction: *split},
+		{Transaction: *payment},
+	}
+
+	tests := []struct {
+		name     string
+		indexes  []uint32
+		expected []string
+	}{
+		{"with output indexes", []uint32{0, 1, 1}, []string{"3:0:0", "3:0:1", "4:0:1"}},
+		{"skips outputs owned by others", []uint32{1, 1, 0}, []string{"3:0:1", "3:0:1"}},
+		{"from an older node", nil, []string{"3:0:0", "3:0:1", "4:0:1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &pb.GetOutputsResponse{
+				ConfirmedTransactions: rpc.SerializeConfirmedTxs(txs),
+				OutputIndexes:         tt.indexes,
+			}
+			utxos := toUTXOs(res, alice)
+			require.Equal(t, tt.expected, utxoRefs(utxos))
+			for _, u := range utxos {
+				tx := txs[0].Transaction
+				if u.BlkNum == payment.BlkNum {
+					tx = txs[2].Transaction
+				}
+				require.Equal(t, tx.OutputAt(u.OutIdx).Denom.String(), u.Amount.String())
+			}
+		})
+	}
+}
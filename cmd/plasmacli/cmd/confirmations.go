@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/eth"
+	"github.com/kyokan/plasma/rpc"
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type confirmationsCmdOutput struct {
+	BlockNumber      uint64   `json:"blockNumber"`
+	TransactionIndex uint32   `json:"transactionIndex"`
+	AuthSignatures   []string `json:"authSignatures"`
+}
+
+var confirmationsCmd = &cobra.Command{
+	Use:   "confirmations [blockNumber] [transactionIndex]",
+	Short: "Fetches the confirmation signatures you sent or received for a transaction",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		blkNum, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "invalid block number")
+		}
+		txIdx, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return errors.Wrap(err, "invalid transaction index")
+		}
+
+		signer, err := ParseSigner(cmd)
+		if err != nil {
+			return err
+		}
+		client, conn, err := CreateRootClient(cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		var res *pb.GetConfirmationsResponse
+		if cmd.Flags().Changed(FlagOutput) {
+			outIdx, _ := cmd.Flags().GetUint8(FlagOutput)
+			res, err = receivedConfirmations(client, signer, blkNum, uint32(txIdx), outIdx)
+		} else {
+			res, err = ownConfirmations(client, signer, blkNum, uint32(txIdx))
+		}
+		if err != nil {
+			return err
+		}
+
+		out := &confirmationsCmdOutput{
+			BlockNumber:      blkNum,
+			TransactionIndex: uint32(txIdx),
+		}
+		for _, authSig := range res.AuthSigs {
+			out.AuthSignatures = append(out.AuthSignatures, hexutil.Encode(authSig))
+		}
+		return PrintJSON(out)
+	},
+}
+
+// ownConfirmationsRequestTTL is how long a signed own confirmations request
+// stays valid. The node accepts up to five minutes.
+const ownConfirmationsRequestTTL = time.Minute
+
+// ownConfirmations fetches the confirmation signatures the signer sent for
+// the inputs it owns, by signing a request for the transaction as the owner
+// of the output spent by the first of them.
+func ownConfirmations(client pb.RootClient, signer eth.Signer, blkNum uint64, txIdx uint32) (*pb.GetConfirmationsResponse, error) {
+	ctx, _ := context.WithTimeout(context.Background(), time.Second*5)
+	blockRes, err := client.GetBlock(ctx, &pb.GetBlockRequest{
+		Number: blkNum,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if uint64(txIdx) >= uint64(len(blockRes.ConfirmedTransactions)) {
+		return nil, errors.New("transaction not found")
+	}
+	tx := rpc.DeserializeConfirmedTx(blockRes.ConfirmedTransactions[txIdx]).Transaction
+	inputIdx, ok := ownInput(&tx, signer.Address())
+	if !ok {
+		return nil, errors.New("transaction spends none of your outputs")
+	}
+
+	ctx, _ = context.WithTimeout(context.Background(), time.Second*5)
+	info, err := client.GetNodeInfo(ctx, &pb.EmptyRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if info.ChainId == nil {
+		return nil, errors.New("node does not support typed signatures")
+	}
+
+	domain := eth.NewPlasmaDomain(rpc.DeserializeBig(info.ChainId), common.BytesToAddress(info.ContractAddress))
+	expiresAt := uint64(time.Now().Add(ownConfirmationsRequestTTL).Unix())
+	data := eth.OwnConfirmationsRequestTypedData(domain, blkNum, txIdx, inputIdx, tx.SignatureHash(), expiresAt)
+	sig, err := signer.SignTypedData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, _ = context.WithTimeout(context.Background(), time.Second*5)
+	return client.GetOwnConfirmations(ctx, &pb.GetOwnConfirmationsRequest{
+		Sig:              sig[:],
+		ExpiresAt:        expiresAt,
+		BlockNumber:      blkNum,
+		TransactionIndex: txIdx,
+		InputIndex:       uint32(inputIdx),
+	})
+}
+
+// ownInput returns the index of the first input of tx owned by addr.
+func ownInput(tx *chain.Transaction, addr common.Address) (uint8, bool) {
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if !input.IsZeroInput() && input.Owner == addr {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// receivedConfirmations fetches the confirmation signatures of the
+// transaction that paid the signer output outIdx, by answering a challenge
+// from the node.
+func receivedConfirmations(client pb.RootClient, signer eth.Signer, blkNum uint64, txIdx uint32, outIdx uint8) (*pb.GetConfirmationsResponse, error) {
+	ctx, _ := context.WithTimeout(context.Background(), time.Second*5)
+	challenge, err := client.GetConfirmationsChallenge(ctx, &pb.GetConfirmationsChallengeRequest{
+		BlockNumber:      blkNum,
+		TransactionIndex: txIdx,
+		OutputIndex:      uint32(outIdx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	domain := eth.NewPlasmaDomain(rpc.DeserializeBig(challenge.ChainId), common.BytesToAddress(challenge.ContractAddress))
+	data := eth.ConfirmationsRequestTypedData(domain, blkNum, txIdx, outIdx, challenge.Nonce)
+	sig, err := signer.SignTypedData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, _ = context.WithTimeout(context.Background(), time.Second*5)
+	return client.GetConfirmations(ctx, &pb.GetConfirmationsRequest{
+		Sig:              sig[:],
+		Nonce:            challenge.Nonce,
+		BlockNumber:      blkNum,
+		TransactionIndex: txIdx,
+		OutputIndex:      uint32(outIdx),
+	})
+}
+
+func init() {
+	confirmationsCmd.Flags().Uint8(FlagOutput, 0, "Fetch the confirmations of the transaction that created this output of yours, instead of the ones you sent.")
+	rootCmd.AddCommand(confirmationsCmd)
+}
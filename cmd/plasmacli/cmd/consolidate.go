@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/kyokan/plasma/log"
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type consolidateTxOutput struct {
+	BlockNumber      uint64   `json:"blockNumber"`
+	TransactionIndex uint32   `json:"transactionIndex"`
+	Amount           string   `json:"amount"`
+	MerkleRoot       string   `json:"merkleRoot"`
+	AuthSignatures   []string `json:"authSignatures"`
+}
+
+type consolidateCmdOutput struct {
+	Transactions     []consolidateTxOutput `json:"transactions"`
+	RemainingOutputs int                   `json:"remainingOutputs"`
+	Pending          *consolidateTxOutput  `json:"pending,omitempty"`
+	StopReason       string                `json:"stopReason,omitempty"`
+}
+
+var consolidateCmdLog = log.ForSubsystem("ConsolidateCmd")
+
+var consolidateCmd = &cobra.Command{
+	Use:   "consolidate",
+	Short: "Merges pairs of spendable outputs into single outputs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer, err := ParseSigner(cmd)
+		if err != nil {
+			return err
+		}
+		addr := signer.Address()
+		maxTxs, err := cmd.Flags().GetUint(FlagMaxTxs)
+		if err != nil {
+			return err
+		}
+		minAmount, ok := new(big.Int).SetString(cmd.Flag(FlagMinAmount).Value.String(), 10)
+		if !ok || minAmount.Sign() < 0 {
+			return errors.New("invalid minimum amount")
+		}
+
+		client, conn, err := CreateRootClient(cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		domain, err := signingDomain(cmd, client)
+		if err != nil {
+			return err
+		}
+
+		out := &consolidateCmdOutput{
+			Transactions: []consolidateTxOutput{},
+		}
+		for uint(len(out.Transactions)) < maxTxs {
+			utxos, err := consolidationCandidates(client, addr, minAmount)
+			if err != nil {
+				return err
+			}
+			out.RemainingOutputs = len(utxos)
+			if len(utxos) < 2 {
+				break
+			}
+
+			pair := utxos[:2]
+			total := sumUTXOs(pair)
+			confirmed, err := buildTx(addr, addr, total, big.NewInt(0), pair, false)
+			if err != nil {
+				return err
+			}
+			if err := signFully(signer, domain, confirmed); err != nil {
+				return err
+			}
+
+			consolidateCmdLog.WithFields(logrus.Fields{
+				"transaction": len(out.Transactions) + 1,
+				"maxTxs":      maxTxs,
+				"remaining":   len(utxos),
+				"amount":      total.Text(10),
+			}).Info("merging outputs")
+
+			sendRes, authSigs, err := sendAndConfirm(client, signer, domain, confirmed)
+			if err != nil && sendRes == nil {
+				// nothing was included, so the outputs are most likely
+				// still being spent by a transaction awaiting confirmation
+				if len(out.Transactions) == 0 {
+					return err
+				}
+				out.StopReason = fmt.Sprintf("failed to send transaction: %s", err)
+				break
+			}
+
+			txOut := consolidateTxOutput{
+				BlockNumber:      sendRes.Inclusion.BlockNumber,
+				TransactionIndex: sendRes.Inclusion.TransactionIndex,
+				Amount:           total.Text(10),
+				MerkleRoot:       hexutil.Encode(sendRes.Inclusion.MerkleRoot),
+				AuthSignatures:   authSigs,
+			}
+			if err != nil {
+				out.Pending = &txOut
+				out.StopReason = fmt.Sprintf("transaction is pending confirmation: %s", err)
+				break
+			}
+			out.Transactions = append(out.Transactions, txOut)
+		}
+
+		return PrintJSON(out)
+	},
+}
+
+// consolidationCandidates returns the address's spendable outputs worth at
+// least minAmount, smallest first.
+func consolidationCandidates(client pb.RootClient, addr common.Address, minAmount *big.Int) ([]utxo, error) {
+	ctx, _ := context.WithTimeout(context.Background(), time.Second*5)
+	res, err := client.GetOutputs(ctx, &pb.GetOutputsRequest{
+		Address:   addr.Bytes(),
+		Spendable: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var utxos []utxo
+	for _, u := range toUTXOs(res, addr) {
+		if u.Amount.Cmp(minAmount) >= 0 {
+			utxos = append(utxos, u)
+		}
+	}
+	return sortedUTXOs(utxos, func(a, b *utxo) bool {
+		return a.Amount.Cmp(b.Amount) < 0
+	}), nil
+}
+
+func init() {
+	consolidateCmd.Flags().Uint(FlagMaxTxs, 10, "Maximum number of consolidation transactions to send.")
+	consolidateCmd.Flags().String(FlagMinAmount, "0", "Ignore outputs worth less than this amount.")
+	rootCmd.AddCommand(consolidateCmd)
+}
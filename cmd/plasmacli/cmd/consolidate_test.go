@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/eth"
+	"github.com/kyokan/plasma/rpc"
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// outputsClient answers GetOutputs with a fixed response.
+type outputsClient struct {
+	pb.RootClient
+	res *pb.GetOutputsResponse
+	req *pb.GetOutputsRequest
+}
+
+func (c *outputsClient) GetOutputs(ctx context.Context, in *pb.GetOutputsRequest, opts ...grpc.CallOption) (*pb.GetOutputsResponse, error) {
+	c.req = in
+	return c.res, nil
+}
+
+// testOutputsResponse lists one legacy transaction per amount, each paying
+// its amount to owner in output 1.
+func testOutputsResponse(owner *eth.LocalSigner, amounts ...int64) *pb.GetOutputsResponse {
+	var txs []chain.ConfirmedTransaction
+	var indexes []uint32
+	for i, amount := range amounts {
+		tx := chain.ZeroTransaction()
+		tx.BlkNum = uint64(i + 1)
+		tx.Output0 = chain.NewOutput(bob, big.NewInt(1), big.NewInt(0))
+		tx.Output1 = chain.NewOutput(owner.Address(), big.NewInt(amount), big.NewInt(0))
+		txs = append(txs, chain.ConfirmedTransaction{Transaction: *tx})
+		indexes = append(indexes, 1)
+	}
+	return &pb.GetOutputsResponse{
+		ConfirmedTransactions: rpc.SerializeConfirmedTxs(txs),
+		OutputIndexes:         indexes,
+	}
+}
+
+func newTestSigner(t *testing.T) *eth.LocalSigner {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return eth.NewLocalSigner(key)
+}
+
+func TestConsolidationCandidates(t *testing.T) {
+	signer := newTestSigner(t)
+	client := &outputsClient{res: testOutputsResponse(signer, 40, 10, 30, 5)}
+
+	tests := []struct {
+		name      string
+		minAmount int64
+		expected  []string
+	}{
+		{"smallest first", 0, []string{"4:0:1", "2:0:1", "3:0:1", "1:0:1"}},
+		{"skips small outputs", 10, []string{"2:0:1", "3:0:1", "1:0:1"}},
+		{"nothing left", 41, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utxos, err := consolidationCandidates(client, signer.Address(), big.NewInt(tt.minAmount))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, utxoRefs(utxos))
+			require.True(t, client.req.Spendable)
+			require.Equal(t, signer.Address().Bytes(), client.req.Address)
+		})
+	}
+}
+
+func TestConsolidationTransaction(t *testing.T) {
+	signer := newTestSigner(t)
+	utxos, err := consolidationCandidates(&outputsClient{res: testOutputsResponse(signer, 40, 10)}, signer.Address(), big.NewInt(0))
+	require.NoError(t, err)
+
+	pair := utxos[:2]
+	total := sumUTXOs(pair)
+	confirmed, err := buildTx(signer.Address(), signer.Address(), total, big.NewInt(0), pair, false)
+	require.NoError(t, err)
+	require.NoError(t, signFully(signer, nil, confirmed))
+	require.Empty(t, missingSigs(nil, confirmed))
+
+	// merging two outputs needs no change, so it stays a legacy transaction
+	tx := &confirmed.Transaction
+	require.False(t, tx.IsMulti())
+	require.Equal(t, signer.Address(), tx.Output0.Owner)
+	require.Equal(t, int64(50), tx.Output0.Denom.Int64())
+	require.True(t, tx.Output1.IsZeroOutput())
+	for i := uint8(0); i < 2; i++ {
+		input := tx.InputAt(i)
+		require.Equal(t, pair[i].BlkNum, input.BlkNum)
+		require.Equal(t, pair[i].OutIdx, input.OutIdx)
+
+		sigHash := input.SignatureHash()
+		sig := tx.SigAt(i)
+		require.NoError(t, eth.ValidateSignature(sigHash[:], sig[:], signer.Address()))
+		confirmHash := tx.SignatureHash()
+		confirmSig := confirmed.ConfirmSigAt(i)
+		require.NoError(t, eth.ValidateSignature(confirmHash[:], confirmSig[:], signer.Address()))
+	}
+}
@@ -23,12 +23,12 @@ var depositCmd = &cobra.Command{
 			return errors.New("invalid amount")
 		}
 
-		privKey, err := ParsePrivateKey(cmd)
+		signer, err := ParseSigner(cmd)
 		if err != nil {
 			return err
 		}
 
-		client, err := eth.NewClient(cmd.Flag(FlagEthereumNodeUrl).Value.String(), args[0], privKey)
+		client, err := eth.NewClient(cmd.Flag(FlagEthereumNodeUrl).Value.String(), args[0], signer)
 		if err != nil {
 			return err
 		}
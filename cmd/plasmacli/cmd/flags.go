@@ -4,4 +4,33 @@ const (
 	FlagPrivateKeyPath = "private-key-path"
 	FlagNodeURL = "node-url"
 	FlagEthereumNodeUrl = "ethereum-node-url"
-)
\ No newline at end of file
+	FlagContract = "contract"
+	FlagMaxTxs = "max-txs"
+	FlagMinAmount = "min-amount"
+	FlagCoinSelection = "coin-selection"
+	FlagUTXO = "utxo"
+	FlagFee = "fee"
+	FlagKeystore = "keystore"
+	FlagAccount = "account"
+	FlagPasswordFile = "password-file"
+	FlagSigner = "signer"
+	FlagFrom = "from"
+	FlagOut = "out"
+	FlagFormat = "format"
+	FlagInputAmount = "input-amount"
+	FlagPay = "pay"
+	FlagAmount = "amount"
+	FlagOutput = "output"
+	FlagTypedSignatures = "typed-signatures"
+	FlagMultiInput = "multi-input"
+	FlagTLSCA = "tls-ca"
+	FlagTLSCert = "tls-cert"
+	FlagTLSKey = "tls-key"
+)
+
+// PasswordEnvVar holds the keystore passphrase when neither a password file
+// nor a prompt should be used.
+const PasswordEnvVar = "PLASMACLI_PASSWORD"
+
+// FeeAuto makes send ask the node for a fee estimate.
+const FeeAuto = "auto"
@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/eth"
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/spf13/cobra"
+)
+
+// Transactions spending inputs of several owners are put together in the
+// same file format as tx build uses. One party creates the transaction with
+// its outputs, every party funds it with their own inputs, and then each of
+// them signs it with tx sign. Legacy confirm signatures cover every input
+// signature, so with two owners the first to sign has to sign again once the
+// second has. Typed signatures don't, so a transaction created with
+// --typed-signatures only needs one round of signing. Copies signed in
+// parallel can be merged with tx combine.
+
+var txCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Starts a transaction to be funded and signed by several parties",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pays, _ := cmd.Flags().GetStringSlice(FlagPay)
+		if len(pays) == 0 {
+			return errors.New("at least one output is required")
+		}
+		var outputs []*chain.Output
+		for _, pay := range pays {
+			output, err := parsePayment(pay)
+			if err != nil {
+				return err
+			}
+			outputs = append(outputs, output)
+		}
+		if len(outputs) > chain.MaxMultiOutputs {
+			return errors.New(fmt.Sprintf("transactions can have at most %d outputs", chain.MaxMultiOutputs))
+		}
+
+		var client pb.RootClient
+		typed, _ := cmd.Flags().GetBool(FlagTypedSignatures)
+		if cmd.Flag(FlagFee).Value.String() == FeeAuto || typed {
+			c, conn, err := CreateRootClient(cmd)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			client = c
+		}
+		fee, err := sendFee(cmd, client)
+		if err != nil {
+			return err
+		}
+		domain, err := signingDomain(cmd, client)
+		if err != nil {
+			return err
+		}
+
+		confirmed, err := assembleTx(nil, outputs, fee, allowMulti(cmd))
+		if err != nil {
+			return err
+		}
+		f, err := newTxFile(confirmed, nil)
+		if err != nil {
+			return err
+		}
+		f.Domain = domain
+		return writeTxFile(cmd, f, TxFormatJSON)
+	},
+}
+
+// parsePayment parses an output given as address:amount.
+func parsePayment(pay string) (*chain.Output, error) {
+	parts := strings.Split(pay, ":")
+	if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+		return nil, errors.New(fmt.Sprintf("invalid output %s, expected address:amount", pay))
+	}
+	amount, ok := new(big.Int).SetString(parts[1], 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, errors.New(fmt.Sprintf("invalid amount in output %s", pay))
+	}
+	return &chain.Output{
+		Owner:        common.HexToAddress(parts[0]),
+		Denom:        amount,
+		DepositNonce: big.NewInt(0),
+	}, nil
+}
+
+var txFundCmd = &cobra.Command{
+	Use:   "fund file",
+	Short: "Adds one party's inputs, and their change, to a transaction",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, format, err := readTxFile(args[0])
+		if err != nil {
+			return err
+		}
+		if f.Inclusion != nil {
+			return errors.New("transaction has already been submitted")
+		}
+		confirmed, err := f.confirmedTransaction()
+		if err != nil {
+			return err
+		}
+		inputs, outputs := txParts(&confirmed.Transaction)
+		if len(missingInputSigs(confirmed)) < len(inputs) {
+			return errors.New("transaction is already signed, every party has to fund it before anyone signs")
+		}
+
+		from, err := spenderAddress(cmd)
+		if err != nil {
+			return err
+		}
+		amount, ok := new(big.Int).SetString(cmd.Flag(FlagAmount).Value.String(), 10)
+		if !ok || amount.Sign() <= 0 {
+			return errors.New("invalid amount")
+		}
+
+		client, conn, err := CreateRootClient(cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		multi := allowMulti(cmd)
+		selectedUtxos, err := selectUTXOs(cmd, client, from, amount, multi)
+		if err != nil {
+			return err
+		}
+
+		// keep the reviewed amounts lined up with the inputs, even if the
+		// file did not list them
+		for i := len(f.Inputs); i < len(inputs); i++ {
+			f.Inputs = append(f.Inputs, txFileInput{Owner: inputs[i].Owner.Hex()})
+		}
+		f.Inputs = f.Inputs[:len(inputs)]
+
+		for _, u := range selectedUtxos {
+			for _, input := range inputs {
+				if input.BlkNum == u.BlkNum && input.TxIdx == u.TxIdx && input.OutIdx == u.OutIdx {
+					return errors.New(fmt.Sprintf("output %s is already spent by this transaction", u.String()))
+				}
+			}
+			inputs = append(inputs, chain.NewInput(u.BlkNum, u.TxIdx, u.OutIdx, big.NewInt(0), from))
+			f.Inputs = append(f.Inputs, txFileInput{
+				Owner:  from.Hex(),
+				Amount: u.Amount.Text(10),
+			})
+		}
+		change := sumUTXOs(selectedUtxos)
+		change = change.Sub(change, amount)
+		if change.Sign() > 0 {
+			outputs = append(outputs, &chain.Output{Owner: from, Denom: change, DepositNonce: big.NewInt(0)})
+		}
+		if len(inputs) > chain.MaxMultiInputs || len(outputs) > chain.MaxMultiOutputs {
+			return errors.New(fmt.Sprintf("transactions can have at most %d inputs and %d outputs", chain.MaxMultiInputs, chain.MaxMultiOutputs))
+		}
+
+		funded, err := assembleTx(inputs, outputs, confirmed.Transaction.Fee, multi)
+		if err != nil {
+			return err
+		}
+		if err := f.setTransaction(funded); err != nil {
+			return err
+		}
+		return writeTxFile(cmd, f, format)
+	},
+}
+
+var txCombineCmd = &cobra.Command{
+	Use:   "combine file file...",
+	Short: "Merges the signatures of several copies of the same transaction",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var merged *txFile
+		var mergedTx *chain.ConfirmedTransaction
+		var mergedAuthSigs []chain.Signature
+		var mergedUnsigned []byte
+		format := TxFormatRLP
+
+		for _, path := range args {
+			f, fileFormat, err := readTxFile(path)
+			if err != nil {
+				return err
+			}
+			confirmed, err := f.confirmedTransaction()
+			if err != nil {
+				return err
+			}
+			authSigs, err := f.authSigs(confirmed)
+			if err != nil {
+				return err
+			}
+			unsigned, err := unsignedRLP(confirmed)
+			if err != nil {
+				return err
+			}
+			if fileFormat == TxFormatJSON {
+				format = TxFormatJSON
+			}
+
+			if merged == nil {
+				merged, mergedTx, mergedAuthSigs, mergedUnsigned = f, confirmed, authSigs, unsigned
+				continue
+			}
+			if !bytes.Equal(unsigned, mergedUnsigned) {
+				return errors.New(fmt.Sprintf("%s holds a different transaction", path))
+			}
+			if !sameDomain(f.Domain, merged.Domain) {
+				return errors.New(fmt.Sprintf("%s is signed in a different domain", path))
+			}
+			if f.Inclusion != nil {
+				if merged.Inclusion != nil && *merged.Inclusion != *f.Inclusion {
+					return errors.New(fmt.Sprintf("%s was included elsewhere", path))
+				}
+				merged.Inclusion = f.Inclusion
+			}
+			if len(f.Inputs) > len(merged.Inputs) {
+				merged.Inputs = f.Inputs
+			}
+			mergeSigs(mergedTx, confirmed)
+			for i := range mergedAuthSigs {
+				if mergedAuthSigs[i] == (chain.Signature{}) {
+					mergedAuthSigs[i] = authSigs[i]
+				}
+			}
+		}
+
+		if err := merged.setTransaction(mergedTx); err != nil {
+			return err
+		}
+		for _, sig := range mergedAuthSigs {
+			if sig != (chain.Signature{}) {
+				merged.AuthSignatures = encodeSigs(mergedAuthSigs)
+				break
+			}
+		}
+		if merged.Inclusion != nil {
+			format = TxFormatJSON
+		}
+		return writeTxFile(cmd, merged, format)
+	},
+}
+
+// unsignedRLP encodes confirmed's transaction without any signatures, so
+// that copies signed by different parties can be compared.
+func unsignedRLP(confirmed *chain.ConfirmedTransaction) ([]byte, error) {
+	tx := confirmed.Transaction
+	tx.Sig0 = chain.Signature{}
+	tx.Sig1 = chain.Signature{}
+	tx.Sigs = make([]chain.Signature, len(tx.Sigs))
+	return rlp.EncodeToBytes(&tx)
+}
+
+// sameDomain reports whether a and b are both legacy, or both the same
+// EIP-712 domain.
+func sameDomain(a *eth.TypedDataDomain, b *eth.TypedDataDomain) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Name == b.Name &&
+		a.Version == b.Version &&
+		a.ChainID.Cmp(b.ChainID) == 0 &&
+		a.VerifyingContract == b.VerifyingContract
+}
+
+// mergeSigs copies every input and confirm signature that dst lacks from
+// src, which must be a copy of the same transaction.
+func mergeSigs(dst *chain.ConfirmedTransaction, src *chain.ConfirmedTransaction) {
+	tx := &dst.Transaction
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		if tx.SigAt(i) != (chain.Signature{}) {
+			continue
+		}
+		sig := src.Transaction.SigAt(i)
+		switch {
+		case tx.IsMulti():
+			tx.Sigs[i] = sig
+		case i == 0:
+			tx.Sig0 = sig
+		default:
+			tx.Sig1 = sig
+		}
+	}
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		if dst.ConfirmSigAt(i) == (chain.Signature{}) {
+			dst.SetConfirmSig(i, src.ConfirmSigAt(i))
+		}
+	}
+}
+
+type txInspectCmdOutput struct {
+	Version         string                 `json:"version"`
+	TypedSignatures bool                   `json:"typedSignatures"`
+	Inputs          []txInspectCmdInput    `json:"inputs"`
+	Outputs         []txInspectCmdOutputTo `json:"outputs"`
+	Fee             string                 `json:"fee"`
+	Balanced        bool                   `json:"balanced"`
+	Inclusion       *txFileInclusion       `json:"inclusion,omitempty"`
+}
+
+type txInspectCmdInput struct {
+	Output    string `json:"output"`
+	Owner     string `json:"owner"`
+	Amount    string `json:"amount,omitempty"`
+	Signed    bool   `json:"signed"`
+	Confirmed bool   `json:"confirmed"`
+}
+
+type txInspectCmdOutputTo struct {
+	Owner  string `json:"owner"`
+	Amount string `json:"amount"`
+}
+
+var txInspectCmd = &cobra.Command{
+	Use:   "inspect file",
+	Short: "Shows a transaction file and which signatures it still needs",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, _, err := readTxFile(args[0])
+		if err != nil {
+			return err
+		}
+		confirmed, err := f.confirmedTransaction()
+		if err != nil {
+			return err
+		}
+		authSigs, err := f.authSigs(confirmed)
+		if err != nil {
+			return err
+		}
+
+		tx := &confirmed.Transaction
+		out := &txInspectCmdOutput{
+			Version:         "legacy",
+			TypedSignatures: f.Domain != nil,
+			Fee:             tx.Fee.Text(10),
+			Balanced:        f.checkBalance(confirmed) == nil,
+			Inclusion:       f.Inclusion,
+		}
+		if tx.IsMulti() {
+			out.Version = "multi"
+		}
+
+		unsigned := make(map[uint8]bool)
+		for _, i := range missingSigs(f.Domain, confirmed) {
+			unsigned[i] = true
+		}
+		for i := uint8(0); i < tx.NumInputs(); i++ {
+			input := tx.InputAt(i)
+			if input.IsZeroInput() {
+				continue
+			}
+			in := txInspectCmdInput{
+				Output:    fmt.Sprintf("%d:%d:%d", input.BlkNum, input.TxIdx, input.OutIdx),
+				Owner:     input.Owner.Hex(),
+				Signed:    !unsigned[i],
+				Confirmed: authSigs[i] != (chain.Signature{}),
+			}
+			if int(i) < len(f.Inputs) {
+				in.Amount = f.Inputs[i].Amount
+			}
+			out.Inputs = append(out.Inputs, in)
+		}
+		_, outputs := txParts(tx)
+		for _, output := range outputs {
+			out.Outputs = append(out.Outputs, txInspectCmdOutputTo{
+				Owner:  output.Owner.Hex(),
+				Amount: output.Denom.Text(10),
+			})
+		}
+		return PrintJSON(out)
+	},
+}
+
+func init() {
+	txCreateCmd.Flags().StringSlice(FlagPay, nil, "Output to create, given as address:amount. Can be repeated.")
+	txCreateCmd.Flags().String(FlagFee, "0", fmt.Sprintf("Fee to pay, or %s to use the node's estimate.", FeeAuto))
+	txFundCmd.Flags().String(FlagFrom, "", "Address to spend from. Defaults to the signer's address.")
+	txFundCmd.Flags().String(FlagAmount, "", "Amount to contribute. Anything selected beyond it is returned as change.")
+	addCoinSelectionFlags(txFundCmd)
+	for _, c := range []*cobra.Command{txCreateCmd, txFundCmd, txCombineCmd} {
+		c.Flags().String(FlagOut, "", "File to write the transaction to. Defaults to standard output.")
+	}
+
+	txCmd.AddCommand(txCreateCmd)
+	txCmd.AddCommand(txFundCmd)
+	txCmd.AddCommand(txCombineCmd)
+	txCmd.AddCommand(txInspectCmd)
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/eth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePayment(t *testing.T) {
+	tests := []struct {
+		name   string
+		pay    string
+		amount int64
+	}{
+		{"valid", bob.Hex() + ":25", 25},
+		{"no amount", bob.Hex(), 0},
+		{"zero amount", bob.Hex() + ":0", 0},
+		{"negative amount", bob.Hex() + ":-5", 0},
+		{"invalid amount", bob.Hex() + ":lots", 0},
+		{"invalid address", "0x1234:25", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := parsePayment(tt.pay)
+			if tt.amount == 0 {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, bob, output.Owner)
+			require.Equal(t, tt.amount, output.Denom.Int64())
+			require.False(t, output.IsDeposit())
+		})
+	}
+}
+
+// testMultiPartyTx has first spend two outputs and second spend one, to pay
+// bob 70 with a fee of 2.
+func testMultiPartyTx(t *testing.T, first common.Address, second common.Address) *chain.ConfirmedTransaction {
+	inputs := []*chain.Input{
+		chain.NewInput(1, 0, 0, big.NewInt(0), first),
+		chain.NewInput(2, 0, 0, big.NewInt(0), first),
+		chain.NewInput(3, 0, 1, big.NewInt(0), second),
+	}
+	outputs := []*chain.Output{
+		{Owner: bob, Denom: big.NewInt(70), DepositNonce: big.NewInt(0)},
+	}
+	confirmed, err := assembleTx(inputs, outputs, big.NewInt(2), true)
+	require.NoError(t, err)
+	return confirmed
+}
+
+// copyTx returns a copy of confirmed that shares none of its signatures.
+func copyTx(t *testing.T, confirmed *chain.ConfirmedTransaction) *chain.ConfirmedTransaction {
+	f, err := newTxFile(confirmed, nil)
+	require.NoError(t, err)
+	copied, err := f.confirmedTransaction()
+	require.NoError(t, err)
+	return copied
+}
+
+// signCopies has each signer sign its own copy of confirmed, as tx sign
+// does, and merges the copies back into confirmed.
+func signCopies(t *testing.T, confirmed *chain.ConfirmedTransaction, signers ...eth.Signer) {
+	var copies []*chain.ConfirmedTransaction
+	for _, signer := range signers {
+		copied := copyTx(t, confirmed)
+		_, err := signInputs(signer, nil, copied)
+		require.NoError(t, err)
+		_, err = signConfirmations(signer, nil, copied)
+		require.NoError(t, err)
+		copies = append(copies, copied)
+	}
+	for _, copied := range copies {
+		unsigned, err := unsignedRLP(copied)
+		require.NoError(t, err)
+		expected, err := unsignedRLP(confirmed)
+		require.NoError(t, err)
+		require.Equal(t, expected, unsigned)
+		mergeSigs(confirmed, copied)
+	}
+}
+
+func TestMultiPartySigning(t *testing.T) {
+	first := newTestSigner(t)
+	second := newTestSigner(t)
+	confirmed := testMultiPartyTx(t, first.Address(), second.Address())
+	tx := &confirmed.Transaction
+	require.True(t, tx.IsMulti())
+	require.Equal(t, []uint8{0, 1, 2}, missingSigs(nil, confirmed))
+
+	// confirm signatures cover every input signature, so the first round
+	// only adds input signatures
+	signCopies(t, confirmed, first, second)
+	require.Empty(t, missingInputSigs(confirmed))
+	require.Equal(t, []uint8{0, 1, 2}, missingSigs(nil, confirmed))
+
+	signCopies(t, confirmed, first, second)
+	require.Empty(t, missingSigs(nil, confirmed))
+
+	sigHash := tx.SigningHash()
+	confirmHash := tx.SignatureHash()
+	owners := []common.Address{first.Address(), first.Address(), second.Address()}
+	for i, owner := range owners {
+		sig := tx.SigAt(uint8(i))
+		require.NoError(t, eth.ValidateSignature(sigHash[:], sig[:], owner))
+		confirmSig := confirmed.ConfirmSigAt(uint8(i))
+		require.NoError(t, eth.ValidateSignature(confirmHash[:], confirmSig[:], owner))
+	}
+}
+
+func TestTypedMultiPartySigning(t *testing.T) {
+	first := newTestSigner(t)
+	second := newTestSigner(t)
+	domain := eth.NewPlasmaDomain(big.NewInt(1), chain.RandomAddress())
+	confirmed := testMultiPartyTx(t, first.Address(), second.Address())
+	tx := &confirmed.Transaction
+
+	// typed inputs are not confirmed until the transaction is included, so
+	// one round of signing is enough to send it
+	for _, signer := range []eth.Signer{first, second} {
+		_, err := signInputs(signer, &domain, confirmed)
+		require.NoError(t, err)
+		signed, err := signConfirmations(signer, &domain, confirmed)
+		require.NoError(t, err)
+		require.Zero(t, signed)
+	}
+	require.Empty(t, missingSigs(&domain, confirmed))
+	require.NotEmpty(t, missingSigs(nil, confirmed))
+
+	typed := eth.TransactionTypedData(domain, tx)
+	owners := []common.Address{first.Address(), first.Address(), second.Address()}
+	for i, owner := range owners {
+		sig := tx.SigAt(uint8(i))
+		require.NoError(t, eth.ValidateTypedDataSignature(typed, sig[:], owner))
+		require.Equal(t, chain.Signature{}, confirmed.ConfirmSigAt(uint8(i)))
+	}
+
+	// once included, every input confirms the transaction at its position
+	tx.BlkNum = 5
+	tx.TxIdx = 2
+	root := make([]byte, 32)
+	authSigs := make([]chain.Signature, tx.NumInputs())
+	for _, signer := range []eth.Signer{first, second} {
+		_, err := signAuthSigs(signer, &domain, confirmed, root, authSigs)
+		require.NoError(t, err)
+	}
+	confirmation := eth.ConfirmationTypedData(domain, tx, 5, 2, root)
+	for i, owner := range owners {
+		require.NoError(t, eth.ValidateTypedDataSignature(confirmation, authSigs[i][:], owner))
+		require.Error(t, eth.ValidateTypedDataSignature(typed, authSigs[i][:], owner))
+	}
+}
+
+func TestMergeSigsKeepsExistingSignatures(t *testing.T) {
+	dst := testMultiPartyTx(t, alice, bob)
+	src := copyTx(t, dst)
+	kept := chain.Signature(chain.RandomConfirmationSig())
+	dst.Transaction.Sigs[0] = kept
+	dst.SetConfirmSig(2, kept)
+	for i := uint8(0); i < 3; i++ {
+		src.Transaction.Sigs[i] = chain.RandomConfirmationSig()
+		src.SetConfirmSig(i, chain.RandomConfirmationSig())
+	}
+
+	mergeSigs(dst, src)
+	require.Equal(t, kept, dst.Transaction.SigAt(0))
+	require.Equal(t, src.Transaction.SigAt(1), dst.Transaction.SigAt(1))
+	require.Equal(t, src.Transaction.SigAt(2), dst.Transaction.SigAt(2))
+	require.Equal(t, src.ConfirmSigAt(0), dst.ConfirmSigAt(0))
+	require.Equal(t, src.ConfirmSigAt(1), dst.ConfirmSigAt(1))
+	require.Equal(t, kept, dst.ConfirmSigAt(2))
+}
+
+func TestSameDomain(t *testing.T) {
+	contract := common.HexToAddress("0xc5fdf4076b8f3a5357c5e395ab970b5b54098fef")
+	domain := eth.NewPlasmaDomain(big.NewInt(1), contract)
+	sameAgain := eth.NewPlasmaDomain(big.NewInt(1), contract)
+	otherChain := eth.NewPlasmaDomain(big.NewInt(3), contract)
+	otherContract := eth.NewPlasmaDomain(big.NewInt(1), bob)
+
+	require.True(t, sameDomain(nil, nil))
+	require.True(t, sameDomain(&domain, &sameAgain))
+	require.False(t, sameDomain(&domain, nil))
+	require.False(t, sameDomain(nil, &domain))
+	require.False(t, sameDomain(&domain, &otherChain))
+	require.False(t, sameDomain(&domain, &otherContract))
+}
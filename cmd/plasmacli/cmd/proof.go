@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/kyokan/plasma/eth"
+	"github.com/kyokan/plasma/merkle"
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/kyokan/plasma/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type proofCmdOutput struct {
+	BlockNumber      uint64 `json:"blockNumber"`
+	TransactionIndex uint32 `json:"transactionIndex"`
+	Root             string `json:"root"`
+	CommittedRoot    string `json:"committedRoot,omitempty"`
+	Proof            string `json:"proof"`
+	Transaction      string `json:"transaction"`
+	Valid            bool   `json:"valid"`
+}
+
+var proofCmd = &cobra.Command{
+	Use:   "proof [blockNumber] [transactionIndex]",
+	Short: "Fetches and verifies a transaction's merkle inclusion proof",
+	Long: `Fetches a transaction's merkle inclusion proof and verifies it against the
+merkle root in the block's header. With --contract, the proof is verified
+against the root the block was submitted to the root chain with instead.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		blkNum, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "invalid block number")
+		}
+		txIdx, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return errors.Wrap(err, "invalid transaction index")
+		}
+
+		client, conn, err := CreateRootClient(cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		ctx, _ := context.WithTimeout(context.Background(), time.Second*5)
+		res, err := client.GetInclusionProof(ctx, &pb.GetInclusionProofRequest{
+			BlockNumber:      blkNum,
+			TransactionIndex: uint32(txIdx),
+		})
+		if err != nil {
+			return err
+		}
+
+		ctx, _ = context.WithTimeout(context.Background(), time.Second*5)
+		blockRes, err := client.GetBlock(ctx, &pb.GetBlockRequest{
+			Number: blkNum,
+		})
+		if err != nil {
+			return err
+		}
+		root := blockRes.Block.Header.MerkleRoot
+
+		out := &proofCmdOutput{
+			BlockNumber:      blkNum,
+			TransactionIndex: uint32(txIdx),
+			Root:             hexutil.Encode(root),
+			Proof:            hexutil.Encode(res.Proof),
+			Transaction:      hexutil.Encode(res.Transaction),
+		}
+
+		if contractAddr := cmd.Flag(FlagContract).Value.String(); contractAddr != "" {
+			if !common.IsHexAddress(contractAddr) {
+				return errors.New("invalid contract address")
+			}
+			ethClient, err := eth.NewClient(cmd.Flag(FlagEthereumNodeUrl).Value.String(), contractAddr, nil)
+			if err != nil {
+				return err
+			}
+			committed, err := ethClient.Block(blkNum)
+			if err != nil {
+				return errors.Wrap(err, "failed to fetch the committed block")
+			}
+			if len(committed.Root) == 0 {
+				return errors.New(fmt.Sprintf("block %d has not been submitted to the root chain", blkNum))
+			}
+			out.CommittedRoot = hexutil.Encode(committed.Root)
+			root = committed.Root
+		}
+
+		leaf := util.Sha256(res.Transaction)
+		out.Valid = bytes.Equal(res.Root, root) && merkle.VerifyProof(root, leaf, txIdx, res.Proof)
+		if err := PrintJSON(out); err != nil {
+			return err
+		}
+		if !out.Valid {
+			return errors.New("inclusion proof is invalid")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(proofCmd)
+	proofCmd.Flags().String(FlagContract, "", "address of the Plasma contract to verify the proof against")
+	proofCmd.Flags().StringP(FlagEthereumNodeUrl, "e", "http://localhost:8545", "URL to a running Ethereum node.")
+}
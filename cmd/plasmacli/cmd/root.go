@@ -4,21 +4,62 @@ import (
 	"github.com/spf13/cobra"
 	"fmt"
 	"os"
+	"strings"
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/status"
 )
 
 var rootCmd = &cobra.Command{
 	Use: "plasmacli",
 	Short: "Interacts with a running plasmad instance.",
+	SilenceErrors: true,
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringP(FlagPrivateKeyPath, "p", "~/.plasma/key", "Path to your private key.")
 	rootCmd.PersistentFlags().StringP(FlagNodeURL, "u", "localhost:6545", "URL to a running plasmad instance.")
+	rootCmd.PersistentFlags().String(FlagKeystore, "~/.plasma/keystore", "Directory holding encrypted keystores.")
+	rootCmd.PersistentFlags().String(FlagAccount, "", "Address of the keystore account to use instead of the private key file.")
+	rootCmd.PersistentFlags().String(FlagSigner, "", "Unix socket of a Clef-style external signer. Signs as --account.")
+	rootCmd.PersistentFlags().String(FlagPasswordFile, "", fmt.Sprintf("File containing the keystore passphrase. Falls back to $%s, then to a prompt.", PasswordEnvVar))
+	rootCmd.PersistentFlags().String(FlagTLSCA, "", "PEM CA to verify the node's TLS certificate with. Enables TLS.")
+	rootCmd.PersistentFlags().String(FlagTLSCert, "", "PEM client certificate to present to the node, for operator RPCs. Enables TLS.")
+	rootCmd.PersistentFlags().String(FlagTLSKey, "", "PEM private key of --tls-cert.")
+	rootCmd.PersistentFlags().Bool(FlagTypedSignatures, false, "Sign transactions and confirmations as EIP-712 typed data instead of legacy hashes. UNSAFE for exits: the root chain contract only verifies legacy signatures.")
+	rootCmd.PersistentFlags().Bool(FlagMultiInput, false, "Allow transactions with more than two inputs or outputs. UNSAFE for exits: the root chain contract cannot decode them, and nodes only accept them with --multi-transactions.")
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-	    fmt.Println(err)
+	    fmt.Fprintln(os.Stderr, renderError(err))
 	    os.Exit(1)
 	}
+}
+
+// renderError describes errors returned by the node by their status code and
+// reason, rather than as raw gRPC errors.
+func renderError(err error) string {
+	st, ok := status.FromError(errors.Cause(err))
+	if !ok {
+		return fmt.Sprintf("Error: %s", err)
+	}
+
+	msg := err.Error()
+	if cause := errors.Cause(err); cause != err {
+		msg = strings.TrimSuffix(msg, cause.Error()) + st.Message()
+	} else {
+		msg = st.Message()
+	}
+	for _, detail := range st.Details() {
+		d, ok := detail.(*pb.ErrorDetail)
+		if !ok {
+			continue
+		}
+		if d.HasInput {
+			return fmt.Sprintf("Error: %s (%s, %s on input %d)", msg, st.Code(), d.Reason, d.InputIndex)
+		}
+		return fmt.Sprintf("Error: %s (%s, %s)", msg, st.Code(), d.Reason)
+	}
+	return fmt.Sprintf("Error: %s (%s)", msg, st.Code())
 }
\ No newline at end of file
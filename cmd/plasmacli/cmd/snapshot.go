@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [file]",
+	Short: "Downloads a database snapshot from a running node",
+	Long: `Downloads a snapshot of a running node's database, which plasmad db import
+loads into a new node. This is an operator RPC, so the node only serves it to
+clients presenting a certificate signed by its client CA with --tls-cert.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, conn, err := CreateRootClient(cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		f, err := os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		stream, err := client.ExportSnapshot(context.Background(), &pb.EmptyRequest{})
+		if err != nil {
+			return err
+		}
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if _, err := f.Write(chunk.Data); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("Wrote snapshot to %s.\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+}
@@ -0,0 +1,487 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/eth"
+	"github.com/kyokan/plasma/rpc"
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/kyokan/plasma/util"
+	"github.com/spf13/cobra"
+)
+
+// addSpendFlags adds the flags used by buildSpend.
+func addSpendFlags(cmd *cobra.Command) {
+	cmd.Flags().String(FlagFee, "0", fmt.Sprintf("Fee to pay, or %s to use the node's estimate.", FeeAuto))
+	addCoinSelectionFlags(cmd)
+}
+
+// addCoinSelectionFlags adds the flags used by selectUTXOs.
+func addCoinSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().String(FlagCoinSelection, CoinSelectionBranchAndBound, fmt.Sprintf("Coin selection strategy, one of %s.", strings.Join(coinSelectorNames(), ", ")))
+	cmd.Flags().StringSlice(FlagUTXO, nil, "Spend this output, given as blk:tx:out. Can be repeated.")
+}
+
+// buildSpend selects outputs of addr worth at least value plus the fee, and
+// builds an unsigned transaction paying value to to. Whatever is left over
+// is sent back to addr as change. The selected outputs are returned in input
+// order.
+func buildSpend(cmd *cobra.Command, client pb.RootClient, addr common.Address, to common.Address, value *big.Int) (*chain.ConfirmedTransaction, []utxo, error) {
+	fee, err := sendFee(cmd, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	multi := allowMulti(cmd)
+	selectedUtxos, err := selectUTXOs(cmd, client, addr, new(big.Int).Add(value, fee), multi)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	confirmed, err := buildTx(addr, to, value, fee, selectedUtxos, multi)
+	if err != nil {
+		return nil, nil, err
+	}
+	return confirmed, selectedUtxos, nil
+}
+
+// selectUTXOs picks spendable outputs of addr worth at least target, using
+// either the outputs given with --utxo or the --coin-selection strategy. It
+// picks at most two unless multi is set.
+func selectUTXOs(cmd *cobra.Command, client pb.RootClient, addr common.Address, target *big.Int, multi bool) ([]utxo, error) {
+	sendCmdLog.Info("selecting outputs")
+
+	ctx, _ := context.WithTimeout(context.Background(), time.Second*5)
+	res, err := client.GetOutputs(ctx, &pb.GetOutputsRequest{
+		Address:   addr.Bytes(),
+		Spendable: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	utxos := toUTXOs(res, addr)
+	if len(utxos) == 0 {
+		return nil, errors.New("no spendable outputs")
+	}
+
+	maxInputs := legacyMaxInputs
+	if multi {
+		maxInputs = chain.MaxMultiInputs
+	}
+	if refs, _ := cmd.Flags().GetStringSlice(FlagUTXO); len(refs) > 0 {
+		return selectManual(utxos, refs, target, maxInputs)
+	}
+	strategy := cmd.Flag(FlagCoinSelection).Value.String()
+	selector, ok := coinSelectors[strategy]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("unknown coin selection strategy %s, expected one of %s", strategy, strings.Join(coinSelectorNames(), ", ")))
+	}
+	return selector(utxos, target, maxInputs)
+}
+
+// sendFee parses --fee, asking the node for an estimate if it is "auto".
+func sendFee(cmd *cobra.Command, client pb.RootClient) (*big.Int, error) {
+	feeStr := cmd.Flag(FlagFee).Value.String()
+	if feeStr != FeeAuto {
+		fee, ok := new(big.Int).SetString(feeStr, 10)
+		if !ok || fee.Sign() < 0 {
+			return nil, errors.New("invalid fee")
+		}
+		return fee, nil
+	}
+
+	ctx, _ := context.WithTimeout(context.Background(), time.Second*5)
+	res, err := client.EstimateFee(ctx, &pb.EmptyRequest{})
+	if err != nil {
+		return nil, err
+	}
+	fee := rpc.DeserializeBig(res.Fee)
+	sendCmdLog.WithField("fee", fee.Text(10)).Info("estimated fee")
+	return fee, nil
+}
+
+// buildTx builds an unsigned transaction spending selectedUtxos, which are
+// owned by addr. More than two outputs can only be spent if multi is set.
+func buildTx(addr common.Address, to common.Address, value *big.Int, fee *big.Int, selectedUtxos []utxo, multi bool) (*chain.ConfirmedTransaction, error) {
+	change, err := changeFor(selectedUtxos, value, fee)
+	if err != nil {
+		return nil, err
+	}
+
+	var inputs []*chain.Input
+	for _, utxo := range selectedUtxos {
+		inputs = append(inputs, chain.NewInput(utxo.BlkNum, utxo.TxIdx, utxo.OutIdx, big.NewInt(0), addr))
+	}
+	outputs := []*chain.Output{
+		{Owner: to, Denom: value, DepositNonce: big.NewInt(0)},
+	}
+	if change.Sign() > 0 {
+		outputs = append(outputs, &chain.Output{Owner: addr, Denom: change, DepositNonce: big.NewInt(0)})
+	}
+	return assembleTx(inputs, outputs, fee, multi)
+}
+
+// assembleTx builds an unsigned transaction from inputs and outputs. It is a
+// legacy transaction if they fit. Otherwise it is a multi transaction if
+// multi is set, and an error if not.
+func assembleTx(inputs []*chain.Input, outputs []*chain.Output, fee *big.Int, multi bool) (*chain.ConfirmedTransaction, error) {
+	if len(inputs) > 2 || len(outputs) > 2 {
+		if !multi {
+			return nil, errors.New(fmt.Sprintf("%d inputs and %d outputs need a multi-input transaction, set --%s to build one", len(inputs), len(outputs), FlagMultiInput))
+		}
+		tx := chain.NewMultiTransaction(inputs, make([]chain.Signature, len(inputs)), outputs, new(big.Int).Set(fee))
+		return &chain.ConfirmedTransaction{
+			Transaction: *tx,
+		}, nil
+	}
+
+	tx := chain.ZeroTransaction()
+	tx.Fee = new(big.Int).Set(fee)
+	for i, input := range inputs {
+		if i == 0 {
+			tx.Input0 = input
+		} else {
+			tx.Input1 = input
+		}
+	}
+	for i, output := range outputs {
+		if i == 0 {
+			tx.Output0 = output
+		} else {
+			tx.Output1 = output
+		}
+	}
+	return &chain.ConfirmedTransaction{
+		Transaction: *tx,
+	}, nil
+}
+
+// allowMulti reports whether --multi-input is set, and warns that the
+// transactions it allows can't be challenged on the root chain.
+func allowMulti(cmd *cobra.Command) bool {
+	if multi, _ := cmd.Flags().GetBool(FlagMultiInput); !multi {
+		return false
+	}
+	sendCmdLog.Warn("the root chain contract cannot decode multi-input transactions, so exits of outputs they spend can't be challenged")
+	return true
+}
+
+// txParts returns the non-empty inputs and outputs of tx, in order.
+func txParts(tx *chain.Transaction) ([]*chain.Input, []*chain.Output) {
+	var inputs []*chain.Input
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		if input := tx.InputAt(i); !input.IsZeroInput() {
+			inputs = append(inputs, input)
+		}
+	}
+	var outputs []*chain.Output
+	for i := uint8(0); i < tx.NumOutputs(); i++ {
+		if output := tx.OutputAt(i); !output.IsZeroOutput() {
+			outputs = append(outputs, output)
+		}
+	}
+	return inputs, outputs
+}
+
+// changeFor returns what is left of the selected outputs after paying value
+// and fee.
+func changeFor(selectedUtxos []utxo, value *big.Int, fee *big.Int) (*big.Int, error) {
+	if fee.Sign() < 0 {
+		return nil, errors.New("fee must not be negative")
+	}
+	change := sumUTXOs(selectedUtxos)
+	change = change.Sub(change, value)
+	change = change.Sub(change, fee)
+	if change.Sign() < 0 {
+		return nil, errors.New("selected outputs do not cover value plus fee")
+	}
+	return change, nil
+}
+
+// signingDomain returns the EIP-712 domain of the node's contract if
+// --typed-signatures is set, and nil otherwise. The signing functions below
+// sign typed data in the domain they are given, and the legacy hashes when
+// it is nil.
+func signingDomain(cmd *cobra.Command, client pb.RootClient) (*eth.TypedDataDomain, error) {
+	if typed, _ := cmd.Flags().GetBool(FlagTypedSignatures); !typed {
+		return nil, nil
+	}
+
+	ctx, _ := context.WithTimeout(context.Background(), time.Second*5)
+	info, err := client.GetNodeInfo(ctx, &pb.EmptyRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if info.ChainId == nil {
+		return nil, errors.New("node does not support typed signatures")
+	}
+	domain := eth.NewPlasmaDomain(rpc.DeserializeBig(info.ChainId), common.BytesToAddress(info.ContractAddress))
+	sendCmdLog.Warn("the root chain contract does not verify typed signatures, so outputs of this transaction can't be exited")
+	return &domain, nil
+}
+
+// signInputs adds the signer's signature to every input it owns that is not
+// signed yet, and returns how many it signed. Existing signatures are kept,
+// since other parties' confirm signatures may already cover them.
+func signInputs(signer eth.Signer, domain *eth.TypedDataDomain, confirmed *chain.ConfirmedTransaction) (int, error) {
+	tx := &confirmed.Transaction
+	addr := signer.Address()
+	signed := 0
+	var typed *eth.TypedData
+	if domain != nil {
+		typed = eth.TransactionTypedData(*domain, tx)
+	}
+
+	if tx.IsMulti() {
+		if len(tx.Sigs) != len(tx.Inputs) {
+			return 0, errors.New("transaction must have one signature slot per input")
+		}
+		sigHash := tx.SigningHash()
+		for i, input := range tx.Inputs {
+			if input.Owner != addr || tx.Sigs[i] != (chain.Signature{}) {
+				continue
+			}
+			sig, err := signWith(signer, typed, sigHash)
+			if err != nil {
+				return 0, err
+			}
+			tx.Sigs[i] = sig
+			signed++
+		}
+		return signed, nil
+	}
+
+	for i := uint8(0); i < 2; i++ {
+		input := tx.InputAt(i)
+		if input.IsZeroInput() || input.Owner != addr || tx.SigAt(i) != (chain.Signature{}) {
+			continue
+		}
+		sig, err := signWith(signer, typed, input.SignatureHash())
+		if err != nil {
+			return 0, err
+		}
+		if i == 0 {
+			tx.Sig0 = sig
+		} else {
+			tx.Sig1 = sig
+		}
+		signed++
+	}
+	return signed, nil
+}
+
+// signConfirmations adds the signer's missing confirm signatures, one per
+// input. Legacy confirm signatures cover the input signatures, so every
+// input has to be signed first. Typed inputs are not confirmed when they are
+// sent, only once the transaction is included, over
+// eth.ConfirmationTypedData, so nothing is signed if domain is set.
+func signConfirmations(signer eth.Signer, domain *eth.TypedDataDomain, confirmed *chain.ConfirmedTransaction) (int, error) {
+	tx := &confirmed.Transaction
+	if domain != nil || len(missingInputSigs(confirmed)) > 0 {
+		return 0, nil
+	}
+
+	addr := signer.Address()
+	sigHash := tx.SignatureHash()
+	signed := 0
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if input.IsZeroInput() || input.Owner != addr || confirmed.ConfirmSigAt(i) != (chain.Signature{}) {
+			continue
+		}
+		sig, err := signer.SignHash(sigHash)
+		if err != nil {
+			return 0, err
+		}
+		confirmed.SetConfirmSig(i, sig)
+		signed++
+	}
+	// single input legacy transactions have always repeated the first
+	// confirm signature in the second slot
+	if !tx.IsMulti() && tx.Input1.IsZeroInput() {
+		confirmed.SetConfirmSig(1, confirmed.ConfirmSigAt(0))
+	}
+	return signed, nil
+}
+
+// signFully signs every input and confirmation, and fails if the signer
+// does not own all of the inputs.
+func signFully(signer eth.Signer, domain *eth.TypedDataDomain, confirmed *chain.ConfirmedTransaction) error {
+	if _, err := signInputs(signer, domain, confirmed); err != nil {
+		return err
+	}
+	if _, err := signConfirmations(signer, domain, confirmed); err != nil {
+		return err
+	}
+	if missing := missingSigs(domain, confirmed); len(missing) > 0 {
+		return errors.New(fmt.Sprintf("missing signatures for inputs %v", missing))
+	}
+	return nil
+}
+
+// missingInputSigs returns the indexes of inputs that are not signed yet.
+func missingInputSigs(confirmed *chain.ConfirmedTransaction) []uint8 {
+	tx := &confirmed.Transaction
+	var missing []uint8
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		if tx.InputAt(i).IsZeroInput() {
+			continue
+		}
+		if tx.SigAt(i) == (chain.Signature{}) {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// ownsInput reports whether addr owns any input of confirmed.
+func ownsInput(confirmed *chain.ConfirmedTransaction, addr common.Address) bool {
+	tx := &confirmed.Transaction
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if !input.IsZeroInput() && input.Owner == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// missingSigs returns the indexes of inputs that lack either their input or
+// their confirm signature. Inputs signed in a typed data domain have no
+// confirm signature until the transaction is included.
+func missingSigs(domain *eth.TypedDataDomain, confirmed *chain.ConfirmedTransaction) []uint8 {
+	missing := missingInputSigs(confirmed)
+	tx := &confirmed.Transaction
+	if len(missing) > 0 || domain != nil {
+		return missing
+	}
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		if !tx.InputAt(i).IsZeroInput() && confirmed.ConfirmSigAt(i) == (chain.Signature{}) {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// authSigHash is the hash signed by confirmations once a transaction has
+// been included in a block with the given merkle root.
+func authSigHash(confirmed *chain.ConfirmedTransaction, merkleRoot []byte) util.Hash {
+	var buf bytes.Buffer
+	buf.Write(confirmed.RLPHash(util.Sha256))
+	buf.Write(merkleRoot)
+	return util.Sha256(buf.Bytes())
+}
+
+// signAuthSigs fills in the signer's confirmation signature for every input
+// it owns that is not confirmed yet. authSigs has one slot per input.
+func signAuthSigs(signer eth.Signer, domain *eth.TypedDataDomain, confirmed *chain.ConfirmedTransaction, merkleRoot []byte, authSigs []chain.Signature) (int, error) {
+	tx := &confirmed.Transaction
+	addr := signer.Address()
+	sigHash := authSigHash(confirmed, merkleRoot)
+	var typed *eth.TypedData
+	if domain != nil {
+		typed = eth.ConfirmationTypedData(*domain, tx, tx.BlkNum, tx.TxIdx, merkleRoot)
+	}
+	signed := 0
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if input.IsZeroInput() || input.Owner != addr || authSigs[i] != (chain.Signature{}) {
+			continue
+		}
+		sig, err := signWith(signer, typed, sigHash)
+		if err != nil {
+			return 0, err
+		}
+		authSigs[i] = sig
+		signed++
+	}
+	if !tx.IsMulti() && tx.Input1.IsZeroInput() {
+		authSigs[1] = authSigs[0]
+	}
+	return signed, nil
+}
+
+// signWith signs typed if it is set, and legacyHash otherwise.
+func signWith(signer eth.Signer, typed *eth.TypedData, legacyHash util.Hash) (chain.Signature, error) {
+	if typed != nil {
+		return signer.SignTypedData(typed)
+	}
+	return signer.SignHash(legacyHash)
+}
+
+// submitTx sends a signed transaction to the node, and records where it was
+// included.
+func submitTx(client pb.RootClient, confirmed *chain.ConfirmedTransaction) (*pb.SendResponse, error) {
+	sendCmdLog.Info("sending transaction")
+
+	ctx, _ := context.WithTimeout(context.Background(), time.Second*5)
+	sendRes, err := client.Send(ctx, &pb.SendRequest{
+		Confirmed: rpc.SerializeConfirmedTx(confirmed),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	confirmed.Transaction.BlkNum = sendRes.Inclusion.BlockNumber
+	confirmed.Transaction.TxIdx = sendRes.Inclusion.TransactionIndex
+	return sendRes, nil
+}
+
+// confirmTx sends one confirmation signature per input to the node.
+func confirmTx(client pb.RootClient, confirmed *chain.ConfirmedTransaction, authSigs []chain.Signature) error {
+	sendCmdLog.Info("confirming transaction")
+
+	req := &pb.ConfirmRequest{
+		BlockNumber:      confirmed.Transaction.BlkNum,
+		TransactionIndex: confirmed.Transaction.TxIdx,
+	}
+	if confirmed.Transaction.IsMulti() {
+		for i := range authSigs {
+			req.AuthSigs = append(req.AuthSigs, authSigs[i][:])
+		}
+	} else {
+		req.AuthSig0 = authSigs[0][:]
+		req.AuthSig1 = authSigs[1][:]
+	}
+
+	ctx, _ := context.WithTimeout(context.Background(), time.Second*5)
+	_, err := client.Confirm(ctx, req)
+	return err
+}
+
+// sendAndConfirm sends a signed transaction and confirms it once it has been
+// included in a block. If the confirmation fails, the send response is
+// returned along with the error, since the transaction is then included but
+// still pending confirmation.
+func sendAndConfirm(client pb.RootClient, signer eth.Signer, domain *eth.TypedDataDomain, confirmed *chain.ConfirmedTransaction) (*pb.SendResponse, []string, error) {
+	sendRes, err := submitTx(client, confirmed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authSigs := make([]chain.Signature, confirmed.Transaction.NumInputs())
+	if _, err := signAuthSigs(signer, domain, confirmed, sendRes.Inclusion.MerkleRoot, authSigs); err != nil {
+		return sendRes, nil, err
+	}
+	if err := confirmTx(client, confirmed, authSigs); err != nil {
+		return sendRes, nil, err
+	}
+
+	return sendRes, encodeSigs(authSigs), nil
+}
+
+func encodeSigs(sigs []chain.Signature) []string {
+	out := make([]string, len(sigs))
+	for i := range sigs {
+		out[i] = hexutil.Encode(sigs[i][:])
+	}
+	return out
+}
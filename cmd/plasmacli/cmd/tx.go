@@ -0,0 +1,533 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/eth"
+	"github.com/kyokan/plasma/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	TxFormatJSON = "json"
+	TxFormatRLP  = "rlp"
+)
+
+// TxFileVersion is the version of the transaction file format.
+const TxFileVersion = 1
+
+// txFile is the interchange format passed between the tx subcommands:
+//
+//   {
+//     "version": 1,
+//     "transaction": "0x...",
+//     "inputs": [{"owner": "0x...", "amount": "100"}],
+//     "inclusion": {"blockNumber": 12, "transactionIndex": 0, "merkleRoot": "0x..."},
+//     "authSignatures": ["0x...", "0x..."],
+//     "domain": {"name": "Plasma", "version": "1", "chainId": 1, "verifyingContract": "0x..."}
+//   }
+//
+// transaction is the hex RLP storage encoding of a chain.ConfirmedTransaction,
+// which includes every input's owner and whatever input and confirm
+// signatures have been added so far. inputs repeats the owner and amount of
+// each spent output so that an offline signer can review the transaction.
+// inclusion is set by tx submit, and authSignatures holds one confirmation
+// signature per input once the transaction has been included. domain is set
+// when the transaction is built with --typed-signatures, and makes tx sign
+// sign EIP-712 typed data in it. With --format rlp, tx build and tx sign
+// exchange just the transaction's hex RLP of a legacy signed transaction
+// instead, and tx sign and tx submit take the input amounts from
+// --input-amount.
+type txFile struct {
+	Version        int                  `json:"version"`
+	Transaction    string               `json:"transaction"`
+	Inputs         []txFileInput        `json:"inputs,omitempty"`
+	Inclusion      *txFileInclusion     `json:"inclusion,omitempty"`
+	AuthSignatures []string             `json:"authSignatures,omitempty"`
+	Domain         *eth.TypedDataDomain `json:"domain,omitempty"`
+}
+
+type txFileInput struct {
+	Owner  string `json:"owner"`
+	Amount string `json:"amount,omitempty"`
+}
+
+type txFileInclusion struct {
+	BlockNumber      uint64 `json:"blockNumber"`
+	TransactionIndex uint32 `json:"transactionIndex"`
+	MerkleRoot       string `json:"merkleRoot"`
+}
+
+func newTxFile(confirmed *chain.ConfirmedTransaction, selected []utxo) (*txFile, error) {
+	f := &txFile{
+		Version: TxFileVersion,
+	}
+	if err := f.setTransaction(confirmed); err != nil {
+		return nil, err
+	}
+	for i, u := range selected {
+		f.Inputs = append(f.Inputs, txFileInput{
+			Owner:  confirmed.Transaction.InputAt(uint8(i)).Owner.Hex(),
+			Amount: u.Amount.Text(10),
+		})
+	}
+	return f, nil
+}
+
+func (f *txFile) setTransaction(confirmed *chain.ConfirmedTransaction) error {
+	enc, err := rlp.EncodeToBytes(confirmed)
+	if err != nil {
+		return err
+	}
+	f.Transaction = hexutil.Encode(enc)
+	return nil
+}
+
+// confirmedTransaction decodes the transaction, and restores its position in
+// the chain if it has been included.
+func (f *txFile) confirmedTransaction() (*chain.ConfirmedTransaction, error) {
+	enc, err := hexutil.Decode(f.Transaction)
+	if err != nil {
+		return nil, errors.New("invalid transaction encoding")
+	}
+	var confirmed chain.ConfirmedTransaction
+	if err := rlp.DecodeBytes(enc, &confirmed); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid transaction: %s", err))
+	}
+	if f.Inclusion != nil {
+		confirmed.Transaction.BlkNum = f.Inclusion.BlockNumber
+		confirmed.Transaction.TxIdx = f.Inclusion.TransactionIndex
+	}
+	return &confirmed, nil
+}
+
+// checkBalance makes sure the transaction is well formed, that the file
+// lists the amount of every input, and that they pay exactly for its
+// outputs and fee.
+func (f *txFile) checkBalance(confirmed *chain.ConfirmedTransaction) error {
+	tx := &confirmed.Transaction
+	if err := tx.Validate(); err != nil {
+		return err
+	}
+	inputs, outputs := txParts(tx)
+	if len(inputs) == 0 {
+		return errors.New("transaction has no inputs")
+	}
+	if len(f.Inputs) != len(inputs) {
+		return errors.New(fmt.Sprintf("transaction has %d inputs but the file lists the amounts of %d", len(inputs), len(f.Inputs)))
+	}
+
+	total := new(big.Int).Set(tx.Fee)
+	for _, output := range outputs {
+		total = total.Add(total, output.Denom)
+	}
+	for i, input := range f.Inputs {
+		amount, ok := new(big.Int).SetString(input.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			return errors.New(fmt.Sprintf("input %d has no valid amount", i))
+		}
+		total = total.Sub(total, amount)
+	}
+	if total.Sign() != 0 {
+		return errors.New("inputs do not pay exactly for the outputs and fee")
+	}
+	return nil
+}
+
+// setInputAmounts lists the --input-amount amounts for a transaction read as
+// bare RLP, which carries no amounts of its own.
+func (f *txFile) setInputAmounts(cmd *cobra.Command, confirmed *chain.ConfirmedTransaction) error {
+	amounts, _ := cmd.Flags().GetStringSlice(FlagInputAmount)
+	if len(amounts) == 0 {
+		return nil
+	}
+	if len(f.Inputs) > 0 {
+		return errors.New(fmt.Sprintf("the file already lists its input amounts, --%s is only for rlp transactions", FlagInputAmount))
+	}
+	inputs, _ := txParts(&confirmed.Transaction)
+	if len(amounts) != len(inputs) {
+		return errors.New(fmt.Sprintf("transaction has %d inputs but --%s was given %d times", len(inputs), FlagInputAmount, len(amounts)))
+	}
+	for i, input := range inputs {
+		f.Inputs = append(f.Inputs, txFileInput{
+			Owner:  input.Owner.Hex(),
+			Amount: amounts[i],
+		})
+	}
+	return nil
+}
+
+// authSigs returns one confirmation signature slot per input, filled in
+// with the signatures collected so far.
+func (f *txFile) authSigs(confirmed *chain.ConfirmedTransaction) ([]chain.Signature, error) {
+	sigs := make([]chain.Signature, confirmed.Transaction.NumInputs())
+	if len(f.AuthSignatures) == 0 {
+		return sigs, nil
+	}
+	if len(f.AuthSignatures) != len(sigs) {
+		return nil, errors.New(fmt.Sprintf("expected %d confirmation signatures, found %d", len(sigs), len(f.AuthSignatures)))
+	}
+	for i, s := range f.AuthSignatures {
+		b, err := hexutil.Decode(s)
+		if err != nil || len(b) != len(sigs[i]) {
+			return nil, errors.New(fmt.Sprintf("invalid confirmation signature %d", i))
+		}
+		copy(sigs[i][:], b)
+	}
+	return sigs, nil
+}
+
+// readTxFile reads a transaction file, or a bare hex RLP transaction, from
+// path. A path of - reads standard input.
+func readTxFile(path string) (*txFile, string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(trimmed, "{") {
+		return &txFile{
+			Version:     TxFileVersion,
+			Transaction: trimmed,
+		}, TxFormatRLP, nil
+	}
+
+	var f txFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, "", errors.New(fmt.Sprintf("invalid transaction file: %s", err))
+	}
+	if f.Version != TxFileVersion {
+		return nil, "", errors.New(fmt.Sprintf("unsupported transaction file version %d", f.Version))
+	}
+	return &f, TxFormatJSON, nil
+}
+
+// writeTxFile writes f to --out, or to standard output if it is not set.
+func writeTxFile(cmd *cobra.Command, f *txFile, format string) error {
+	var data []byte
+	if format == TxFormatRLP {
+		if f.Inclusion != nil || len(f.AuthSignatures) > 0 {
+			return errors.New("included transactions can only be written as json")
+		}
+		if f.Domain != nil {
+			return errors.New("typed transactions can only be written as json")
+		}
+		data = []byte(f.Transaction)
+	} else {
+		var err error
+		data, err = json.MarshalIndent(f, "", "    ")
+		if err != nil {
+			return err
+		}
+	}
+
+	out := cmd.Flag(FlagOut).Value.String()
+	if out == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	return ioutil.WriteFile(out, append(data, '\n'), 0600)
+}
+
+var txCmdLog = log.ForSubsystem("TxCmd")
+
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Builds, signs, submits and confirms transactions in separate steps",
+	Long: `Builds, signs, submits and confirms transactions in separate steps, so that
+the signing key can stay on an offline machine:
+
+  plasmacli tx build --from <addr> <to> <value> --out tx.json   (online)
+  plasmacli tx sign tx.json --out tx.json                        (offline)
+  plasmacli tx submit tx.json --out tx.json                      (online)
+  plasmacli tx sign tx.json --out tx.json                        (offline)
+  plasmacli tx confirm tx.json                                   (online)
+
+Signing a submitted transaction adds the confirmation signatures.
+
+Transactions spending several parties' outputs start with tx create, which
+sets the outputs, and tx fund, which each party runs to add their inputs.
+Every party then signs, and copies signed in parallel can be merged with
+tx combine. tx inspect shows which signatures are still missing.`,
+}
+
+var txBuildCmd = &cobra.Command{
+	Use:   "build to value",
+	Short: "Builds an unsigned transaction from the sender's spendable outputs",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, err := spenderAddress(cmd)
+		if err != nil {
+			return err
+		}
+		to := common.HexToAddress(args[0])
+		value, ok := new(big.Int).SetString(args[1], 10)
+		if !ok {
+			return errors.New("invalid send value")
+		}
+		format := cmd.Flag(FlagFormat).Value.String()
+		if format != TxFormatJSON && format != TxFormatRLP {
+			return errors.New(fmt.Sprintf("format must be %s or %s", TxFormatJSON, TxFormatRLP))
+		}
+
+		client, conn, err := CreateRootClient(cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		domain, err := signingDomain(cmd, client)
+		if err != nil {
+			return err
+		}
+		confirmed, selected, err := buildSpend(cmd, client, from, to, value)
+		if err != nil {
+			return err
+		}
+		f, err := newTxFile(confirmed, selected)
+		if err != nil {
+			return err
+		}
+		f.Domain = domain
+		return writeTxFile(cmd, f, format)
+	},
+}
+
+var txSignCmd = &cobra.Command{
+	Use:   "sign file",
+	Short: "Adds the signer's signatures to a transaction without contacting the node",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, format, err := readTxFile(args[0])
+		if err != nil {
+			return err
+		}
+		confirmed, err := f.confirmedTransaction()
+		if err != nil {
+			return err
+		}
+		signer, err := ParseSigner(cmd)
+		if err != nil {
+			return err
+		}
+		if err := f.setInputAmounts(cmd, confirmed); err != nil {
+			return err
+		}
+		if !ownsInput(confirmed, signer.Address()) {
+			return errors.New(fmt.Sprintf("%s does not own any inputs", signer.Address().Hex()))
+		}
+		if typed, _ := cmd.Flags().GetBool(FlagTypedSignatures); typed && f.Domain == nil {
+			return errors.New("transaction has no signing domain, build it with --typed-signatures")
+		}
+		logTransaction(confirmed, f)
+
+		if f.Inclusion != nil {
+			authSigs, err := f.authSigs(confirmed)
+			if err != nil {
+				return err
+			}
+			root, err := hexutil.Decode(f.Inclusion.MerkleRoot)
+			if err != nil {
+				return errors.New("invalid merkle root")
+			}
+			if _, err := signAuthSigs(signer, f.Domain, confirmed, root, authSigs); err != nil {
+				return err
+			}
+			f.AuthSignatures = encodeSigs(authSigs)
+			return writeTxFile(cmd, f, format)
+		}
+
+		if err := f.checkBalance(confirmed); err != nil {
+			return err
+		}
+		if _, err := signInputs(signer, f.Domain, confirmed); err != nil {
+			return err
+		}
+		if _, err := signConfirmations(signer, f.Domain, confirmed); err != nil {
+			return err
+		}
+		if missing := missingSigs(f.Domain, confirmed); len(missing) > 0 {
+			txCmdLog.WithField("inputs", missing).Info("transaction still needs signatures")
+		}
+		if err := f.setTransaction(confirmed); err != nil {
+			return err
+		}
+		return writeTxFile(cmd, f, format)
+	},
+}
+
+var txSubmitCmd = &cobra.Command{
+	Use:   "submit file",
+	Short: "Sends a fully signed transaction to the node",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, _, err := readTxFile(args[0])
+		if err != nil {
+			return err
+		}
+		if f.Inclusion != nil {
+			return errors.New("transaction has already been submitted")
+		}
+		confirmed, err := f.confirmedTransaction()
+		if err != nil {
+			return err
+		}
+		if err := f.setInputAmounts(cmd, confirmed); err != nil {
+			return err
+		}
+		if err := f.checkBalance(confirmed); err != nil {
+			return err
+		}
+		if missing := missingSigs(f.Domain, confirmed); len(missing) > 0 {
+			return errors.New(fmt.Sprintf("missing signatures for inputs %v", missing))
+		}
+
+		client, conn, err := CreateRootClient(cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		sendRes, err := submitTx(client, confirmed)
+		if err != nil {
+			return err
+		}
+		f.Inclusion = &txFileInclusion{
+			BlockNumber:      sendRes.Inclusion.BlockNumber,
+			TransactionIndex: sendRes.Inclusion.TransactionIndex,
+			MerkleRoot:       hexutil.Encode(sendRes.Inclusion.MerkleRoot),
+		}
+		return writeTxFile(cmd, f, TxFormatJSON)
+	},
+}
+
+type txConfirmCmdOutput struct {
+	BlockNumber      uint64   `json:"blockNumber"`
+	TransactionIndex uint32   `json:"transactionIndex"`
+	AuthSignatures   []string `json:"authSignatures"`
+}
+
+var txConfirmCmd = &cobra.Command{
+	Use:   "confirm file",
+	Short: "Sends a submitted transaction's confirmation signatures to the node",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, _, err := readTxFile(args[0])
+		if err != nil {
+			return err
+		}
+		if f.Inclusion == nil {
+			return errors.New("transaction has not been submitted")
+		}
+		confirmed, err := f.confirmedTransaction()
+		if err != nil {
+			return err
+		}
+		authSigs, err := f.authSigs(confirmed)
+		if err != nil {
+			return err
+		}
+		for i := uint8(0); i < confirmed.Transaction.NumInputs(); i++ {
+			if !confirmed.Transaction.InputAt(i).IsZeroInput() && authSigs[i] == (chain.Signature{}) {
+				return errors.New(fmt.Sprintf("missing confirmation signature for input %d", i))
+			}
+		}
+
+		client, conn, err := CreateRootClient(cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if err := confirmTx(client, confirmed, authSigs); err != nil {
+			return err
+		}
+		return PrintJSON(&txConfirmCmdOutput{
+			BlockNumber:      confirmed.Transaction.BlkNum,
+			TransactionIndex: confirmed.Transaction.TxIdx,
+			AuthSignatures:   f.AuthSignatures,
+		})
+	},
+}
+
+// spenderAddress returns the --from address, or the signer's address if it
+// is not set.
+func spenderAddress(cmd *cobra.Command) (common.Address, error) {
+	if fromStr := cmd.Flag(FlagFrom).Value.String(); fromStr != "" {
+		if !common.IsHexAddress(fromStr) {
+			return common.Address{}, errors.New("invalid sender address")
+		}
+		return common.HexToAddress(fromStr), nil
+	}
+	signer, err := ParseSigner(cmd)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return signer.Address(), nil
+}
+
+// logTransaction shows what is about to be signed.
+func logTransaction(confirmed *chain.ConfirmedTransaction, f *txFile) {
+	tx := &confirmed.Transaction
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if input.IsZeroInput() {
+			continue
+		}
+		fields := logrus.Fields{
+			"input": fmt.Sprintf("%d:%d:%d", input.BlkNum, input.TxIdx, input.OutIdx),
+			"owner": input.Owner.Hex(),
+		}
+		if int(i) < len(f.Inputs) {
+			fields["amount"] = f.Inputs[i].Amount
+		}
+		txCmdLog.WithFields(fields).Info("spending")
+	}
+	for i := uint8(0); i < tx.NumOutputs(); i++ {
+		output := tx.OutputAt(i)
+		if output.IsZeroOutput() {
+			continue
+		}
+		txCmdLog.WithFields(logrus.Fields{
+			"owner":  output.Owner.Hex(),
+			"amount": output.Denom.Text(10),
+		}).Info("paying")
+	}
+	txCmdLog.WithField("fee", tx.Fee.Text(10)).Info("fee")
+}
+
+func init() {
+	addSpendFlags(txBuildCmd)
+	txBuildCmd.Flags().String(FlagFrom, "", "Address to spend from. Defaults to the signer's address.")
+	txBuildCmd.Flags().String(FlagFormat, TxFormatJSON, fmt.Sprintf("Output format, %s or %s.", TxFormatJSON, TxFormatRLP))
+	for _, c := range []*cobra.Command{txSignCmd, txSubmitCmd} {
+		c.Flags().StringSlice(FlagInputAmount, nil, "Amount of the output spent by the next input, for transactions given as bare RLP. Can be repeated.")
+	}
+	for _, c := range []*cobra.Command{txBuildCmd, txSignCmd, txSubmitCmd} {
+		c.Flags().String(FlagOut, "", "File to write the transaction to. Defaults to standard output.")
+	}
+
+	txCmd.AddCommand(txBuildCmd)
+	txCmd.AddCommand(txSignCmd)
+	txCmd.AddCommand(txSubmitCmd)
+	txCmd.AddCommand(txConfirmCmd)
+	rootCmd.AddCommand(txCmd)
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kyokan/plasma/chain"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+// testTxFile builds a transaction file in which from sends value to bob out
+// of outputs worth the given amounts.
+func testTxFile(t *testing.T, from common.Address, value int64, amounts ...int64) (*txFile, *chain.ConfirmedTransaction) {
+	utxos := testUTXOs(amounts...)
+	confirmed, err := buildTx(from, bob, big.NewInt(value), big.NewInt(1), utxos, false)
+	require.NoError(t, err)
+	f, err := newTxFile(confirmed, utxos)
+	require.NoError(t, err)
+	return f, confirmed
+}
+
+func outCommand(path string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String(FlagOut, path, "")
+	return cmd
+}
+
+func TestBuildTx(t *testing.T) {
+	confirmed, err := buildTx(alice, bob, big.NewInt(35), big.NewInt(1), testUTXOs(10, 30), false)
+	require.NoError(t, err)
+	require.False(t, confirmed.Transaction.IsMulti())
+
+	// three inputs only fit in a multi transaction
+	utxos := testUTXOs(10, 20, 30)
+	_, err = buildTx(alice, bob, big.NewInt(55), big.NewInt(1), utxos, false)
+	require.Error(t, err)
+	confirmed, err = buildTx(alice, bob, big.NewInt(55), big.NewInt(1), utxos, true)
+	require.NoError(t, err)
+	require.True(t, confirmed.Transaction.IsMulti())
+	require.Equal(t, uint8(3), confirmed.Transaction.NumInputs())
+}
+
+func TestTxFileRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plasma-tx")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	signer := newTestSigner(t)
+	from := signer.Address()
+	for _, format := range []string{TxFormatJSON, TxFormatRLP} {
+		t.Run(format, func(t *testing.T) {
+			f, confirmed := testTxFile(t, from, 35, 10, 30)
+			require.NoError(t, signFully(signer, nil, confirmed))
+			require.NoError(t, f.setTransaction(confirmed))
+
+			path := filepath.Join(dir, format)
+			require.NoError(t, writeTxFile(outCommand(path), f, format))
+			read, readFormat, err := readTxFile(path)
+			require.NoError(t, err)
+			require.Equal(t, format, readFormat)
+
+			decoded, err := read.confirmedTransaction()
+			require.NoError(t, err)
+			expected, err := rlp.EncodeToBytes(confirmed)
+			require.NoError(t, err)
+			actual, err := rlp.EncodeToBytes(decoded)
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+			require.Empty(t, missingSigs(read.Domain, decoded))
+			if format == TxFormatJSON {
+				require.Equal(t, f.Inputs, read.Inputs)
+			}
+		})
+	}
+
+	f, _ := testTxFile(t, from, 35, 10, 30)
+	f.Inclusion = &txFileInclusion{BlockNumber: 5, TransactionIndex: 2}
+	require.Error(t, writeTxFile(outCommand(filepath.Join(dir, "included")), f, TxFormatRLP))
+	decoded, err := f.confirmedTransaction()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), decoded.Transaction.BlkNum)
+	require.Equal(t, uint32(2), decoded.Transaction.TxIdx)
+
+	path := filepath.Join(dir, "future")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"version": 99, "transaction": "0x"}`), 0600))
+	_, _, err = readTxFile(path)
+	require.Error(t, err)
+}
+
+func TestTxFileCheckBalance(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []string
+		valid   bool
+	}{
+		{"balanced", []string{"10", "30"}, true},
+		{"unknown amounts", []string{"10", ""}, false},
+		{"unparseable amount", []string{"10", "3O"}, false},
+		{"negative amount", []string{"50", "-10"}, false},
+		{"inputs not listed", nil, false},
+		{"too few inputs listed", []string{"40"}, false},
+		{"overpaid", []string{"10", "31"}, false},
+		{"underpaid", []string{"10", "29"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 35 to bob, 4 in change and a fee of 1
+			f, confirmed := testTxFile(t, alice, 35, 10, 30)
+			f.Inputs = nil
+			for _, amount := range tt.amounts {
+				f.Inputs = append(f.Inputs, txFileInput{Owner: alice.Hex(), Amount: amount})
+			}
+			err := f.checkBalance(confirmed)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestTxFileSetInputAmounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		listed  bool
+		amounts []string
+		valid   bool
+	}{
+		{"rlp with amounts", false, []string{"10", "30"}, true},
+		{"rlp without amounts", false, nil, false},
+		{"rlp with too few amounts", false, []string{"40"}, false},
+		{"json with amounts", true, []string{"10", "30"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, confirmed := testTxFile(t, alice, 35, 10, 30)
+			if !tt.listed {
+				f.Inputs = nil
+			}
+			cmd := &cobra.Command{}
+			cmd.Flags().StringSlice(FlagInputAmount, tt.amounts, "")
+			err := f.setInputAmounts(cmd, confirmed)
+			if err == nil {
+				err = f.checkBalance(confirmed)
+			}
+			if tt.valid {
+				require.NoError(t, err)
+				require.Equal(t, alice.Hex(), f.Inputs[1].Owner)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestTxFileAuthSigs(t *testing.T) {
+	_, confirmed := testTxFile(t, alice, 35, 10, 30)
+	sig := chain.RandomConfirmationSig()
+
+	tests := []struct {
+		name     string
+		sigs     []string
+		expected []chain.Signature
+	}{
+		{"none yet", nil, []chain.Signature{{}, {}}},
+		{"one per input", []string{hexutil.Encode(sig[:]), hexutil.Encode(make([]byte, 65))}, []chain.Signature{sig, {}}},
+		{"wrong count", []string{hexutil.Encode(sig[:])}, nil},
+		{"wrong length", []string{"0x01", "0x02"}, nil},
+		{"not hex", []string{"sig", "sig"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &txFile{AuthSignatures: tt.sigs}
+			sigs, err := f.authSigs(confirmed)
+			if tt.expected == nil {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, sigs)
+		})
+	}
+}
@@ -8,11 +8,16 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/kyokan/plasma/rpc/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"github.com/mitchellh/go-homedir"
 	"strings"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/kyokan/plasma/eth"
 )
 
 func AddrOrPrivateKeyAddr(cmd *cobra.Command, args []string, addrArg int) (common.Address, error) {
@@ -20,18 +25,58 @@ func AddrOrPrivateKeyAddr(cmd *cobra.Command, args []string, addrArg int) (commo
 	if len(args) > addrArg {
 		addr = common.HexToAddress(args[addrArg])
 	} else {
-		privKey, err := ParsePrivateKey(cmd)
+		signer, err := ParseSigner(cmd)
 		if err != nil {
 			return addr, err
 		}
 
-		addr = crypto.PubkeyToAddress(privKey.PublicKey)
+		addr = signer.Address()
 	}
 
 	return addr, nil
 }
 
+// ParseSigner returns the external signer at --signer if one is set, and
+// signs with the private key from ParsePrivateKey otherwise.
+func ParseSigner(cmd *cobra.Command) (eth.Signer, error) {
+	if socket := cmd.Flag(FlagSigner).Value.String(); socket != "" {
+		account := cmd.Flag(FlagAccount).Value.String()
+		if !common.IsHexAddress(account) {
+			return nil, errors.New("--account must be set when using an external signer")
+		}
+		signer, err := eth.NewExternalSigner(socket, common.HexToAddress(account))
+		if err != nil {
+			return nil, err
+		}
+		return signer, nil
+	}
+
+	privKey, err := ParsePrivateKey(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return eth.NewLocalSigner(privKey), nil
+}
+
+// ParsePrivateKey loads the key of the --account keystore if one is set.
+// Otherwise it reads the private key file, which holds either a hex private
+// key or an encrypted JSON keystore.
 func ParsePrivateKey(cmd *cobra.Command) (*ecdsa.PrivateKey, error) {
+	if account := cmd.Flag(FlagAccount).Value.String(); account != "" {
+		if !common.IsHexAddress(account) {
+			return nil, errors.New("invalid account address")
+		}
+		ks, err := OpenKeystore(cmd)
+		if err != nil {
+			return nil, err
+		}
+		path, err := eth.KeystoreFile(ks, common.HexToAddress(account))
+		if err != nil {
+			return nil, err
+		}
+		return decryptKeyFile(cmd, path)
+	}
+
 	path := cmd.Flag(FlagPrivateKeyPath).Value.String()
 	if path == "" {
 		return nil, errors.New("no private key path set")
@@ -46,17 +91,51 @@ func ParsePrivateKey(cmd *cobra.Command) (*ecdsa.PrivateKey, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read private key")
 	}
+	if eth.IsKeystoreJSON(keyBytes) {
+		return decryptKeyFile(cmd, expanded)
+	}
 
 	return crypto.HexToECDSA(strings.TrimSpace(string(keyBytes)))
 }
 
+func decryptKeyFile(cmd *cobra.Command, path string) (*ecdsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read keystore")
+	}
+	pass, err := PassphraseSource(cmd).Passphrase("Passphrase: ", false)
+	if err != nil {
+		return nil, err
+	}
+	return eth.DecryptKeystore(data, pass)
+}
+
+func PassphraseSource(cmd *cobra.Command) *eth.PassphraseSource {
+	return &eth.PassphraseSource{
+		PasswordFile: cmd.Flag(FlagPasswordFile).Value.String(),
+		EnvVar:       PasswordEnvVar,
+	}
+}
+
+func OpenKeystore(cmd *cobra.Command) (*keystore.KeyStore, error) {
+	dir, err := homedir.Expand(cmd.Flag(FlagKeystore).Value.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't expand homedir")
+	}
+	return eth.OpenKeystore(dir), nil
+}
+
 func CreateRootClient(cmd *cobra.Command) (pb.RootClient, *grpc.ClientConn, error) {
 	url := cmd.Flag(FlagNodeURL).Value.String()
 	if url == "" {
 		return nil, nil, errors.New("no node url set")
 	}
 
-	conn, err := grpc.Dial(url, grpc.WithInsecure())
+	transport, err := transportOption(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := grpc.Dial(url, transport)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to dial node")
 	}
@@ -65,6 +144,57 @@ func CreateRootClient(cmd *cobra.Command) (pb.RootClient, *grpc.ClientConn, erro
 	return client, conn, nil
 }
 
+// transportOption secures the connection to the node with TLS if --tls-ca or
+// --tls-cert is set. Without --tls-ca, the node's certificate is checked
+// against the system roots. --tls-cert and --tls-key authenticate the client
+// for operator RPCs.
+func transportOption(cmd *cobra.Command) (grpc.DialOption, error) {
+	caPath := cmd.Flag(FlagTLSCA).Value.String()
+	certPath := cmd.Flag(FlagTLSCert).Value.String()
+	keyPath := cmd.Flag(FlagTLSKey).Value.String()
+	if caPath == "" && certPath == "" {
+		return grpc.WithInsecure(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if caPath != "" {
+		path, err := homedir.Expand(caPath)
+		if err != nil {
+			return nil, err
+		}
+		pem, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read TLS CA")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New(fmt.Sprintf("no certificates found in %s", path))
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if certPath != "" {
+		if keyPath == "" {
+			return nil, errors.New("--tls-cert needs --tls-key")
+		}
+		certPath, err := homedir.Expand(certPath)
+		if err != nil {
+			return nil, err
+		}
+		keyPath, err := homedir.Expand(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load TLS client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+}
+
 func CreateEthClient(cmd *cobra.Command) {
 
 }
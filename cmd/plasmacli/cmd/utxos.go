@@ -5,7 +5,6 @@ import (
 	"context"
 	"time"
 	"github.com/kyokan/plasma/rpc/pb"
-	"github.com/kyokan/plasma/rpc"
 )
 
 type utxoCmdOutput struct {
@@ -41,18 +40,16 @@ var utxosCmd = &cobra.Command{
 			return err
 		}
 
-		out := make([]utxoCmdOutput, len(res.ConfirmedTransactions), len(res.ConfirmedTransactions))
-
-		for i, conf := range res.ConfirmedTransactions {
-			deser := rpc.DeserializeConfirmedTx(conf)
-			tx := deser.Transaction
+		utxos := toUTXOs(res, addr)
+		out := make([]utxoCmdOutput, len(utxos), len(utxos))
 
+		for i, u := range utxos {
 			out[i] = utxoCmdOutput{
-				BlockNumber:      tx.BlkNum,
-				TransactionIndex: tx.TxIdx,
-				OutputIndex:      tx.OutputIndexFor(&addr),
-				Amount:           tx.OutputFor(&addr).Denom.Text(10),
-				DepositNonce:     tx.OutputFor(&addr).DepositNonce.Text(10),
+				BlockNumber:      u.BlkNum,
+				TransactionIndex: u.TxIdx,
+				OutputIndex:      u.OutIdx,
+				Amount:           u.Amount.Text(10),
+				DepositNonce:     u.DepositNonce.Text(10),
 			}
 		}
 
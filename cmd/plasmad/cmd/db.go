@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/kyokan/plasma/db"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagBatchSize = "batch-size"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "manages the Plasma database",
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "upgrades the database to the current schema version",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetString(FlagDBBackend) == db.BackendSQLite {
+			return errors.New("migrations only apply to the leveldb backend")
+		}
+
+		batchSize, err := cmd.Flags().GetInt(FlagBatchSize)
+		if err != nil {
+			return err
+		}
+
+		location := path.Join(viper.GetString(FlagDB), "root")
+		err = db.MigrateStorage(location, batchSize, func(progress db.MigrationProgress) {
+			if progress.Done {
+				fmt.Printf("Migrated to version %d (%d keys processed).\n", progress.Version, progress.Processed)
+				return
+			}
+			fmt.Printf("Version %d: %s: %d keys processed\n", progress.Version, progress.Description, progress.Processed)
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Database is at schema version %d.\n", db.CurrentSchemaVersion)
+		return nil
+	},
+}
+
+var dbExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "writes a snapshot of the database to a file",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		closer, storage, err := openStorage()
+		if err != nil {
+			return err
+		}
+		defer closer.Close()
+		snapshots, ok := storage.(db.SnapshotStorage)
+		if !ok {
+			return errors.New("the database backend does not support snapshots")
+		}
+
+		err = snapshots.ExportSnapshot(f, func(blkNum uint64) {
+			if blkNum%1000 == 0 {
+				fmt.Printf("Exported block %d.\n", blkNum)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Wrote snapshot to %s.\n", args[0])
+		return nil
+	},
+}
+
+var dbImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "loads a snapshot into an empty database",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		closer, storage, err := openStorage()
+		if err != nil {
+			return err
+		}
+		defer closer.Close()
+		snapshots, ok := storage.(db.SnapshotStorage)
+		if !ok {
+			return errors.New("the database backend does not support snapshots")
+		}
+
+		var last uint64
+		err = snapshots.ImportSnapshot(f, func(blkNum uint64) {
+			last = blkNum
+			if blkNum%1000 == 0 {
+				fmt.Printf("Imported block %d.\n", blkNum)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d blocks.\n", last)
+		return nil
+	},
+}
+
+var dbVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "checks the integrity of every block in the database",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		closer, storage, err := openStorage()
+		if err != nil {
+			return err
+		}
+		defer closer.Close()
+		verifiable, ok := storage.(*db.Storage)
+		if !ok {
+			return errors.New("the database backend does not support verification")
+		}
+
+		report, err := verifiable.Verify(func(blkNum uint64) {
+			if blkNum%1000 == 0 {
+				fmt.Fprintf(os.Stderr, "Verified block %d.\n", blkNum)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		if err := printJSON(report); err != nil {
+			return err
+		}
+		if !report.OK() {
+			return errors.New(fmt.Sprintf("found %d issues", len(report.Issues)))
+		}
+		return nil
+	},
+}
+
+// openStorage opens the database, along with its archive if it has been
+// pruned.
+func openStorage() (io.Closer, db.PlasmaStorage, error) {
+	location := path.Join(viper.GetString(FlagDB), "root")
+	closer, storage, err := db.CreateStorageWithBackend(viper.GetString(FlagDBBackend), location)
+	if err != nil {
+		return nil, nil, err
+	}
+	prunable, ok := storage.(db.PrunableStorage)
+	if !ok {
+		return closer, storage, nil
+	}
+	archive, err := db.OpenStorageArchive(prunable, path.Join(location, db.ArchiveName), false)
+	if err != nil {
+		closer.Close()
+		return nil, nil, err
+	}
+	if archive == nil {
+		return closer, storage, nil
+	}
+	return closers{archive, closer}, storage, nil
+}
+
+// closers closes each of its elements in order.
+type closers []io.Closer
+
+func (c closers) Close() error {
+	var firstErr error
+	for _, closer := range c {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func printJSON(in interface{}) error {
+	j, err := json.MarshalIndent(in, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(j))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbCmd.AddCommand(dbExportCmd)
+	dbCmd.AddCommand(dbImportCmd)
+	dbCmd.AddCommand(dbVerifyCmd)
+	dbMigrateCmd.Flags().Int(FlagBatchSize, db.DefaultMigrationBatchSize, "number of keys to rewrite per batch")
+}
@@ -3,9 +3,41 @@ package cmd
 const (
 	FlagConfig       = "config"
 	FlagDB           = "db"
+	FlagDBBackend    = "db-backend"
 	FlagNodeURL      = "node-url"
 	FlagContractAddr = "contract-addr"
 	FlagPrivateKey   = "private-key"
+	FlagKeystore     = "keystore"
+	FlagPasswordFile = "password-file"
+	FlagSigner       = "signer"
+	FlagSignerAddr   = "signer-address"
 	FlagRPCPort      = "rpc-port"
 	FlagRESTPort     = "rest-port"
+
+	FlagMaxChainsawLag     = "max-chainsaw-lag"
+	FlagMaxSubmissionDelay = "max-submission-delay"
+
+	FlagMinBlockInterval   = "min-block-interval"
+	FlagMaxBlockInterval   = "max-block-interval"
+	FlagMinBlockTxs        = "min-block-txs"
+	FlagMaxBlockTxs        = "max-block-txs"
+	FlagEmptyBlockInterval = "empty-block-interval"
+
+	FlagPrune          = "prune"
+	FlagPruneRetention = "prune-retention"
+
+	FlagLegacySignatures  = "legacy-signatures"
+	FlagMultiTransactions = "multi-transactions"
+
+	FlagTLSCert     = "tls-cert"
+	FlagTLSKey      = "tls-key"
+	FlagTLSClientCA = "tls-client-ca"
 )
+
+// ConfigRPCLimits is the config file section that overrides the default
+// per-method RPC limits. It has no command line flag.
+const ConfigRPCLimits = "rpc-limits"
+
+// PasswordEnvVar holds the operator keystore's passphrase when no password
+// file is given.
+const PasswordEnvVar = "PLASMAD_PASSWORD"
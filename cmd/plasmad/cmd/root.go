@@ -13,7 +13,6 @@ var boundFlags = []string{
 	FlagDB,
 	FlagNodeURL,
 	FlagContractAddr,
-	FlagPrivateKey,
 }
 
 var configFile string
@@ -27,13 +26,24 @@ func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&configFile, FlagConfig, "", "filepath to Plasma's configuration file")
 	rootCmd.PersistentFlags().String(FlagDB, db.DefaultLocation(), "filepath to Plasma's database")
+	rootCmd.PersistentFlags().String(FlagDBBackend, db.BackendLevelDB, fmt.Sprintf("database backend to use (%s or %s)", db.BackendLevelDB, db.BackendSQLite))
 	rootCmd.PersistentFlags().String(FlagNodeURL, "", "full URL to a running Ethereum node")
 	rootCmd.PersistentFlags().String(FlagContractAddr, "", "address of the Plasma contract")
-	rootCmd.PersistentFlags().String(FlagPrivateKey, "", "node operator's private key")
+	rootCmd.PersistentFlags().String(FlagPrivateKey, "", "node operator's hex private key (visible to other users, prefer --keystore)")
+	rootCmd.PersistentFlags().String(FlagKeystore, "", "path to the node operator's encrypted JSON keystore")
+	rootCmd.PersistentFlags().String(FlagPasswordFile, "", fmt.Sprintf("file containing the keystore passphrase, falls back to $%s and then to a prompt", PasswordEnvVar))
 	for _, flag := range boundFlags {
 		rootCmd.MarkFlagRequired(flag)
 		viper.BindPFlag(flag, rootCmd.PersistentFlags().Lookup(flag))
 	}
+	rootCmd.PersistentFlags().String(FlagSigner, "", "path to the Unix socket of a Clef-style external signer holding the operator key")
+	rootCmd.PersistentFlags().String(FlagSignerAddr, "", "operator address managed by the external signer")
+	viper.BindPFlag(FlagDBBackend, rootCmd.PersistentFlags().Lookup(FlagDBBackend))
+	viper.BindPFlag(FlagPrivateKey, rootCmd.PersistentFlags().Lookup(FlagPrivateKey))
+	viper.BindPFlag(FlagKeystore, rootCmd.PersistentFlags().Lookup(FlagKeystore))
+	viper.BindPFlag(FlagPasswordFile, rootCmd.PersistentFlags().Lookup(FlagPasswordFile))
+	viper.BindPFlag(FlagSigner, rootCmd.PersistentFlags().Lookup(FlagSigner))
+	viper.BindPFlag(FlagSignerAddr, rootCmd.PersistentFlags().Lookup(FlagSignerAddr))
 }
 
 func initConfig() {
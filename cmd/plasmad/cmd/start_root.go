@@ -4,25 +4,62 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/kyokan/plasma/root"
+	"time"
+	"github.com/kyokan/plasma/node"
 )
 
 var startRootCmd = &cobra.Command{
 	Use:   "start-root",
 	Short: "starts running a Plasma root node",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		privateKey, err := ParsePrivateKey()
+		signer, err := ParseSigner()
 		if err != nil {
 			return err
 		}
 
-		return root.Start(NewGlobalConfig(), privateKey)
+		cfg := NewGlobalConfig()
+		cfg.RPCLimits, err = ParseRPCLimits()
+		if err != nil {
+			return err
+		}
+
+		return root.Start(cfg, signer)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(startRootCmd)
 	startRootCmd.Flags().Uint(FlagRPCPort, 6545, "port for the RPC server to listen on")
-	startRootCmd.Flags().Uint(FlagRESTPort, 6546, "port for the RPC server to listen on")
+	startRootCmd.Flags().Uint(FlagRESTPort, 6546, "port for the health check server to listen on")
+	startRootCmd.Flags().Uint64(FlagMaxChainsawLag, 20, "number of Ethereum blocks chainsaw may fall behind before the node is not ready")
+	startRootCmd.Flags().Duration(FlagMaxSubmissionDelay, 5*time.Minute, "age of an unsubmitted block after which the node is not ready")
+	policy := node.DefaultBlockPolicy()
+	startRootCmd.Flags().Duration(FlagMinBlockInterval, policy.MinBlockInterval, "minimum time between two blocks")
+	startRootCmd.Flags().Duration(FlagMaxBlockInterval, policy.MaxBlockInterval, "maximum time pending transactions wait for --min-block-txs before being packaged (0 to disable)")
+	startRootCmd.Flags().Int(FlagMinBlockTxs, policy.MinTxCount, "number of pending transactions that triggers a new block")
+	startRootCmd.Flags().Int(FlagMaxBlockTxs, policy.MaxTxCount, "maximum number of transactions per block")
+	startRootCmd.Flags().Duration(FlagEmptyBlockInterval, policy.EmptyBlockInterval, "package an empty block if no block was created for this long (0 to disable)")
+	startRootCmd.Flags().Bool(FlagPrune, false, "move spent transactions of old blocks from the database to an archive file")
+	startRootCmd.Flags().Duration(FlagPruneRetention, node.DefaultPruneRetention, "age after which blocks are pruned when running with --prune, at least the contract's exit period")
+	startRootCmd.Flags().Bool(FlagLegacySignatures, true, "accept signatures over the legacy RLP hashes as well as EIP-712 typed data signatures")
+	startRootCmd.Flags().Bool(FlagMultiTransactions, false, "accept multi-input transactions, which the root chain contract cannot decode to challenge exits")
+	startRootCmd.Flags().String(FlagTLSCert, "", "PEM certificate to serve RPCs over TLS with")
+	startRootCmd.Flags().String(FlagTLSKey, "", "PEM private key of --tls-cert")
+	startRootCmd.Flags().String(FlagTLSClientCA, "", "PEM CA whose client certificates may call operator RPCs")
 	viper.BindPFlag(FlagRPCPort, startRootCmd.Flags().Lookup(FlagRPCPort))
 	viper.BindPFlag(FlagRESTPort, startRootCmd.Flags().Lookup(FlagRESTPort))
+	viper.BindPFlag(FlagMaxChainsawLag, startRootCmd.Flags().Lookup(FlagMaxChainsawLag))
+	viper.BindPFlag(FlagMaxSubmissionDelay, startRootCmd.Flags().Lookup(FlagMaxSubmissionDelay))
+	viper.BindPFlag(FlagMinBlockInterval, startRootCmd.Flags().Lookup(FlagMinBlockInterval))
+	viper.BindPFlag(FlagMaxBlockInterval, startRootCmd.Flags().Lookup(FlagMaxBlockInterval))
+	viper.BindPFlag(FlagMinBlockTxs, startRootCmd.Flags().Lookup(FlagMinBlockTxs))
+	viper.BindPFlag(FlagMaxBlockTxs, startRootCmd.Flags().Lookup(FlagMaxBlockTxs))
+	viper.BindPFlag(FlagEmptyBlockInterval, startRootCmd.Flags().Lookup(FlagEmptyBlockInterval))
+	viper.BindPFlag(FlagPrune, startRootCmd.Flags().Lookup(FlagPrune))
+	viper.BindPFlag(FlagPruneRetention, startRootCmd.Flags().Lookup(FlagPruneRetention))
+	viper.BindPFlag(FlagLegacySignatures, startRootCmd.Flags().Lookup(FlagLegacySignatures))
+	viper.BindPFlag(FlagMultiTransactions, startRootCmd.Flags().Lookup(FlagMultiTransactions))
+	viper.BindPFlag(FlagTLSCert, startRootCmd.Flags().Lookup(FlagTLSCert))
+	viper.BindPFlag(FlagTLSKey, startRootCmd.Flags().Lookup(FlagTLSKey))
+	viper.BindPFlag(FlagTLSClientCA, startRootCmd.Flags().Lookup(FlagTLSClientCA))
 }
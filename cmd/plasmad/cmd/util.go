@@ -6,19 +6,96 @@ import (
 	"crypto/ecdsa"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
+	"github.com/kyokan/plasma/eth"
+	"io/ioutil"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func NewGlobalConfig() *config.GlobalConfig {
 	return &config.GlobalConfig{
-		DBPath:       viper.GetString(FlagDB),
-		NodeURL:      viper.GetString(FlagNodeURL),
-		RPCPort:      viper.GetInt(FlagRPCPort),
-		ContractAddr: viper.GetString(FlagContractAddr),
+		DBPath:             viper.GetString(FlagDB),
+		DBBackend:          viper.GetString(FlagDBBackend),
+		NodeURL:            viper.GetString(FlagNodeURL),
+		RPCPort:            viper.GetInt(FlagRPCPort),
+		RESTPort:           viper.GetInt(FlagRESTPort),
+		ContractAddr:       viper.GetString(FlagContractAddr),
+		MaxChainsawLag:     uint64(viper.GetInt64(FlagMaxChainsawLag)),
+		MaxSubmissionDelay: viper.GetDuration(FlagMaxSubmissionDelay),
+		MinBlockInterval:   viper.GetDuration(FlagMinBlockInterval),
+		MaxBlockInterval:   viper.GetDuration(FlagMaxBlockInterval),
+		MinBlockTxs:        viper.GetInt(FlagMinBlockTxs),
+		MaxBlockTxs:        viper.GetInt(FlagMaxBlockTxs),
+		EmptyBlockInterval: viper.GetDuration(FlagEmptyBlockInterval),
+		Prune:              viper.GetBool(FlagPrune),
+		PruneRetention:     viper.GetDuration(FlagPruneRetention),
+		LegacySignatures:   viper.GetBool(FlagLegacySignatures),
+		MultiTransactions:  viper.GetBool(FlagMultiTransactions),
+		TLSCert:            viper.GetString(FlagTLSCert),
+		TLSKey:             viper.GetString(FlagTLSKey),
+		TLSClientCA:        viper.GetString(FlagTLSClientCA),
 	}
 }
 
+// ParseRPCLimits applies the rpc-limits section of the config file to the
+// default limits.
+func ParseRPCLimits() (*config.RPCLimits, error) {
+	limits := config.DefaultRPCLimits()
+	if !viper.IsSet(ConfigRPCLimits) {
+		return limits, nil
+	}
+	var overrides map[string]config.MethodLimits
+	if err := viper.UnmarshalKey(ConfigRPCLimits, &overrides); err != nil {
+		return nil, errors.Wrap(err, "invalid rpc-limits")
+	}
+	limits.Override(overrides)
+	return limits, nil
+}
+
+// ParseSigner uses the external signer at --signer if one is set, and the
+// operator's private key otherwise.
+func ParseSigner() (eth.Signer, error) {
+	if socket := viper.GetString(FlagSigner); socket != "" {
+		addr := viper.GetString(FlagSignerAddr)
+		if !common.IsHexAddress(addr) {
+			return nil, errors.New("--signer-address must be set to the operator's address")
+		}
+		signer, err := eth.NewExternalSigner(socket, common.HexToAddress(addr))
+		if err != nil {
+			return nil, err
+		}
+		return signer, nil
+	}
+
+	privateKey, err := ParsePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return eth.NewLocalSigner(privateKey), nil
+}
+
+// ParsePrivateKey loads the operator key from --keystore, or from the hex
+// --private-key if no keystore is set.
 func ParsePrivateKey() (*ecdsa.PrivateKey, error) {
+	if path := viper.GetString(FlagKeystore); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read keystore")
+		}
+		source := &eth.PassphraseSource{
+			PasswordFile: viper.GetString(FlagPasswordFile),
+			EnvVar:       PasswordEnvVar,
+		}
+		pass, err := source.Passphrase("Keystore passphrase: ", false)
+		if err != nil {
+			return nil, err
+		}
+		return eth.DecryptKeystore(data, pass)
+	}
+
 	privateKeyStr := viper.GetString(FlagPrivateKey)
+	if privateKeyStr == "" {
+		return nil, errors.New("either --keystore or --private-key must be set")
+	}
 	privateKey, err := crypto.HexToECDSA(privateKeyStr)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse private key")
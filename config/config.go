@@ -1,8 +1,136 @@
 package config
 
+import (
+	"strings"
+	"time"
+)
+
+// Version is overridden at build time via -ldflags.
+var Version = "dev"
+
 type GlobalConfig struct {
-	DBPath       string
-	NodeURL      string
-	RPCPort      int
-	ContractAddr string
+	DBPath             string
+	DBBackend          string
+	NodeURL            string
+	RPCPort            int
+	RESTPort           int
+	ContractAddr       string
+	MaxChainsawLag     uint64
+	MaxSubmissionDelay time.Duration
+	MinBlockInterval   time.Duration
+	MaxBlockInterval   time.Duration
+	MinBlockTxs        int
+	MaxBlockTxs        int
+	EmptyBlockInterval time.Duration
+	Prune              bool
+	PruneRetention     time.Duration
+	LegacySignatures   bool
+	MultiTransactions  bool
+	TLSCert            string
+	TLSKey             string
+	TLSClientCA        string
+	RPCLimits          *RPCLimits
+}
+
+// MethodLimits bounds the load one RPC method can put on the node. Rates are
+// in requests per second, and sizes in bytes. Zero and negative values
+// disable a limit.
+type MethodLimits struct {
+	IPRate         float64 `mapstructure:"ip-rate"`
+	IPBurst        int     `mapstructure:"ip-burst"`
+	AddressRate    float64 `mapstructure:"address-rate"`
+	AddressBurst   int     `mapstructure:"address-burst"`
+	MaxRequestSize int     `mapstructure:"max-request-size"`
+	MaxConcurrent  int     `mapstructure:"max-concurrent"`
+}
+
+// merge returns m with every non-zero field of override applied.
+func (m MethodLimits) merge(override MethodLimits) MethodLimits {
+	if override.IPRate != 0 {
+		m.IPRate = override.IPRate
+	}
+	if override.IPBurst != 0 {
+		m.IPBurst = override.IPBurst
+	}
+	if override.AddressRate != 0 {
+		m.AddressRate = override.AddressRate
+	}
+	if override.AddressBurst != 0 {
+		m.AddressBurst = override.AddressBurst
+	}
+	if override.MaxRequestSize != 0 {
+		m.MaxRequestSize = override.MaxRequestSize
+	}
+	if override.MaxConcurrent != 0 {
+		m.MaxConcurrent = override.MaxConcurrent
+	}
+	return m
+}
+
+// RPCLimits holds the limits of every RPC method. Methods are keyed by their
+// lower case name without the service, as in "getoutputs", since config keys
+// are case insensitive. They fall back to Default for any limit they don't
+// set.
+type RPCLimits struct {
+	Default MethodLimits
+	Methods map[string]MethodLimits
+}
+
+// RPCLimitsDefault names the entry of an rpc-limits config section that
+// overrides Default rather than a method.
+const RPCLimitsDefault = "default"
+
+func DefaultRPCLimits() *RPCLimits {
+	return &RPCLimits{
+		Default: MethodLimits{
+			IPRate:         20,
+			IPBurst:        40,
+			MaxRequestSize: 64 << 10,
+		},
+		Methods: map[string]MethodLimits{
+			"send": {
+				MaxConcurrent: 16,
+			},
+			"getoutputs": {
+				IPRate:        5,
+				IPBurst:       10,
+				AddressRate:   2,
+				AddressBurst:  10,
+				MaxConcurrent: 4,
+			},
+			"getbalance": {
+				IPRate:        5,
+				IPBurst:       10,
+				MaxConcurrent: 8,
+			},
+			"getblock": {
+				MaxConcurrent: 8,
+			},
+			"getconfirmationschallenge": {
+				IPRate:  2,
+				IPBurst: 10,
+			},
+		},
+	}
+}
+
+// Override applies the non-zero limits in overrides, which are keyed by
+// method name or RPCLimitsDefault.
+func (l *RPCLimits) Override(overrides map[string]MethodLimits) {
+	if l.Methods == nil {
+		l.Methods = make(map[string]MethodLimits)
+	}
+	for name, override := range overrides {
+		name = strings.ToLower(name)
+		if name == RPCLimitsDefault {
+			l.Default = l.Default.merge(override)
+			continue
+		}
+		l.Methods[name] = l.Methods[name].merge(override)
+	}
+}
+
+// For returns the limits of method.
+func (l *RPCLimits) For(method string) MethodLimits {
+	return l.Default.merge(l.Methods[strings.ToLower(method)])
 }
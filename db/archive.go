@@ -0,0 +1,134 @@
+package db
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+)
+
+// An archive starts with archiveMagic and the format version, followed by
+// block records framed like snapshot records. A block may appear more than
+// once if the node stopped between archiving and pruning it; the database
+// records the offset of the copy that was pruned.
+const archiveMagic = "PLASMAARC"
+
+const ArchiveFormatVersion = 1
+
+// ArchiveName is the name of the archive file in the database directory.
+const ArchiveName = "archive.dat"
+
+// Archive is an append-only file holding the blocks removed by pruning.
+// Records are read back by offset, which is safe while blocks are appended.
+type Archive struct {
+	f *os.File
+}
+
+func OpenArchive(location string) (*Archive, error) {
+	f, err := os.OpenFile(location, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() == 0 {
+		buf := make([]byte, len(archiveMagic)+4)
+		copy(buf, archiveMagic)
+		binary.BigEndian.PutUint32(buf[len(archiveMagic):], ArchiveFormatVersion)
+		if _, err := f.Write(buf); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &Archive{f: f}, nil
+	}
+
+	buf := make([]byte, len(archiveMagic)+4)
+	if _, err := io.ReadFull(io.NewSectionReader(f, 0, int64(len(buf))), buf); err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "failed to read archive header")
+	}
+	if string(buf[:len(archiveMagic)]) != archiveMagic {
+		f.Close()
+		return nil, errors.New("not a plasma archive")
+	}
+	if version := binary.BigEndian.Uint32(buf[len(archiveMagic):]); version != ArchiveFormatVersion {
+		f.Close()
+		return nil, errors.New(fmt.Sprintf("unsupported archive format version %d", version))
+	}
+	return &Archive{f: f}, nil
+}
+
+// append writes rec and syncs it to disk, so that it is never lost once the
+// block has been pruned from the database. It returns the record's offset.
+// Only one goroutine may append at a time.
+func (a *Archive) append(rec *snapshotBlock) (int64, error) {
+	payload, err := rlp.EncodeToBytes(rec)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := a.f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, 5+len(payload))
+	buf[0] = snapshotRecordBlock
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(payload)))
+	copy(buf[5:], payload)
+	if _, err := a.f.Write(buf); err != nil {
+		return 0, err
+	}
+	return offset, a.f.Sync()
+}
+
+// blockAt reads the block record written at offset.
+func (a *Archive) blockAt(offset int64) (*snapshotBlock, error) {
+	var buf [5]byte
+	if _, err := a.f.ReadAt(buf[:], offset); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to read archive record at %d", offset))
+	}
+	if buf[0] != snapshotRecordBlock {
+		return nil, errors.New(fmt.Sprintf("no archived block at offset %d", offset))
+	}
+	length := binary.BigEndian.Uint32(buf[1:])
+	if length > maxSnapshotRecordSize {
+		return nil, errors.New(fmt.Sprintf("archive record of %d bytes is too large", length))
+	}
+	payload := make([]byte, length)
+	if _, err := a.f.ReadAt(payload, offset+5); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to read archive record at %d", offset))
+	}
+	var rec snapshotBlock
+	if err := rlp.DecodeBytes(payload, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// OpenStorageArchive opens the archive at location and lets prunable read
+// pruned blocks from it. Unless create is set, it returns a nil archive if
+// nothing has been pruned yet.
+func OpenStorageArchive(prunable PrunableStorage, location string, create bool) (*Archive, error) {
+	if !create {
+		lastPruned, err := prunable.LastPrunedBlock()
+		if err != nil || lastPruned == 0 {
+			return nil, err
+		}
+	}
+	archive, err := OpenArchive(location)
+	if err != nil {
+		return nil, err
+	}
+	prunable.UseArchive(archive)
+	return archive, nil
+}
+
+func (a *Archive) Close() error {
+	return a.f.Close()
+}
@@ -1,10 +1,20 @@
 package db
 
 import (
+	"database/sql"
+	"fmt"
+	"io"
 	"log"
+	"os"
 	"path"
 
-		"github.com/syndtr/goleveldb/leveldb"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	BackendLevelDB = "leveldb"
+	BackendSQLite  = "sqlite"
 )
 
 func CreateStorage(location string) (*leveldb.DB, PlasmaStorage, error) {
@@ -14,5 +24,60 @@ func CreateStorage(location string) (*leveldb.DB, PlasmaStorage, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	return level, NewStorage(level), nil
-}
\ No newline at end of file
+	if err := checkSchemaVersion(level); err != nil {
+		level.Close()
+		return nil, nil, err
+	}
+	storage := &Storage{
+		db: level,
+	}
+	if err := storage.RepairPartialBlocks(); err != nil {
+		level.Close()
+		return nil, nil, err
+	}
+	return level, storage, nil
+}
+
+// MigrateStorage upgrades the LevelDB database in location to
+// CurrentSchemaVersion.
+func MigrateStorage(location string, batchSize int, progress func(MigrationProgress)) error {
+	loc := path.Join(location, "db")
+	level, err := leveldb.OpenFile(loc, nil)
+	if err != nil {
+		return err
+	}
+	defer level.Close()
+	return Migrate(level, batchSize, progress)
+}
+
+func CreateSQLStorage(location string) (*sql.DB, PlasmaStorage, error) {
+	loc := path.Join(location, "plasma.sqlite")
+	log.Printf("Creating SQLite database in %s.", loc)
+	if err := os.MkdirAll(location, 0700); err != nil {
+		return nil, nil, err
+	}
+	sqlDB, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=1&_busy_timeout=5000", loc))
+	if err != nil {
+		return nil, nil, err
+	}
+	// SQLite only supports a single writer.
+	sqlDB.SetMaxOpenConns(1)
+	storage, err := NewSQLStorage(sqlDB)
+	if err != nil {
+		sqlDB.Close()
+		return nil, nil, err
+	}
+	return sqlDB, storage, nil
+}
+
+// CreateStorageWithBackend opens the storage backend with the given name.
+func CreateStorageWithBackend(backend string, location string) (io.Closer, PlasmaStorage, error) {
+	switch backend {
+	case BackendLevelDB, "":
+		return CreateStorage(location)
+	case BackendSQLite:
+		return CreateSQLStorage(location)
+	default:
+		return nil, nil, fmt.Errorf("unknown database backend %s", backend)
+	}
+}
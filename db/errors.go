@@ -0,0 +1,101 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// NotFoundError is returned when a block, its metadata or a transaction's
+// confirmation signatures don't exist, whichever backend is used.
+type NotFoundError struct {
+	What string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found", e.What)
+}
+
+// IsNotFound reports whether err, or the error it wraps, is a NotFoundError
+// or an InvalidHeightError, since no block exists at an invalid height.
+func IsNotFound(err error) bool {
+	switch errors.Cause(err).(type) {
+	case *NotFoundError, *InvalidHeightError:
+		return true
+	}
+	return false
+}
+
+// notFound turns the backends' own not found errors into a NotFoundError
+// for what, and returns any other error unchanged.
+func notFound(err error, what string) error {
+	if err == leveldb.ErrNotFound || err == sql.ErrNoRows {
+		return &NotFoundError{What: what}
+	}
+	return err
+}
+
+// ConflictingSpendError is returned when a block would spend an output that
+// is already spent, either by an earlier block or earlier in the same block.
+type ConflictingSpendError struct {
+	TxIdx    uint32
+	InputIdx uint8
+}
+
+func (e *ConflictingSpendError) Error() string {
+	return fmt.Sprintf("transaction %d input %d spends an output that is already spent", e.TxIdx, e.InputIdx)
+}
+
+func conflictingSpend(txIdx uint32, inputIdx uint8) error {
+	return &ConflictingSpendError{TxIdx: txIdx, InputIdx: inputIdx}
+}
+
+// IsConflictingSpend reports whether err, or the error it wraps, is a
+// ConflictingSpendError.
+func IsConflictingSpend(err error) bool {
+	_, ok := errors.Cause(err).(*ConflictingSpendError)
+	return ok
+}
+
+// PrunedError is returned when a block or transaction is incomplete or
+// missing although it should exist, which happens once a node has pruned it.
+type PrunedError struct {
+	What string
+}
+
+func (e *PrunedError) Error() string {
+	return fmt.Sprintf("%s, it may have been pruned", e.What)
+}
+
+// IsPruned reports whether err, or the error it wraps, is a PrunedError.
+func IsPruned(err error) bool {
+	_, ok := errors.Cause(err).(*PrunedError)
+	return ok
+}
+
+// InvalidHeightError is returned when a block is requested at a height no
+// block can have. Blocks are numbered from 1.
+type InvalidHeightError struct {
+	Height uint64
+}
+
+func (e *InvalidHeightError) Error() string {
+	return fmt.Sprintf("invalid block height %d, blocks are numbered from 1", e.Height)
+}
+
+// IsInvalidHeight reports whether err, or the error it wraps, is an
+// InvalidHeightError.
+func IsInvalidHeight(err error) bool {
+	_, ok := errors.Cause(err).(*InvalidHeightError)
+	return ok
+}
+
+// checkHeight returns an InvalidHeightError if no block can have height num.
+func checkHeight(num uint64) error {
+	if num == 0 {
+		return &InvalidHeightError{Height: num}
+	}
+	return nil
+}
@@ -53,10 +53,6 @@ func blockFeesExitKey(number uint64) []byte {
 	return prefixKey(blockFeesExit, strconv.FormatUint(number, 10))
 }
 
-func extractAmount(tx *chain.Transaction, addr *common.Address) *big.Int {
-	return tx.OutputFor(addr).Denom
-}
-
 func earnPrefixKey(addr *common.Address) []byte {
 	return prefixKey(earnKeyPrefix, util.AddressToHex(addr))
 }
@@ -0,0 +1,299 @@
+package db
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/util"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	levelutil "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const lastPrunedBlockKey = "LAST_PRUNED_BLOCK"
+const retainedKeyPrefix = "retained"
+const archivedKeyPrefix = "archived"
+
+// PrunableStorage is implemented by storage backends that can move old,
+// fully spent transactions to an archive.
+type PrunableStorage interface {
+	UseArchive(archive *Archive)
+	Prune(before uint64) (int, error)
+	LastPrunedBlock() (uint64, error)
+}
+
+func retainedKey(blkNum uint64, txIdx uint32) []byte {
+	return prefixKey(retainedKeyPrefix, util.Uint642Str(blkNum), util.Uint322Str(txIdx))
+}
+
+// archivedKey maps a pruned block to the offset of its archive record.
+func archivedKey(blkNum uint64) []byte {
+	return prefixKey(archivedKeyPrefix, util.Uint642Str(blkNum))
+}
+
+// UseArchive sets the archive Prune moves blocks to and pruned blocks are
+// read back from. It must be called before the storage is used.
+func (ps *Storage) UseArchive(archive *Archive) {
+	ps.archive = archive
+}
+
+func (ps *Storage) LastPrunedBlock() (uint64, error) {
+	return ps.getMostRecentEventIdx(lastPrunedBlockKey)
+}
+
+// Prune appends every block below before that hasn't been pruned yet to the
+// archive, then removes its transactions whose outputs have all been spent or
+// exited from the database: their bodies, redundant copies, auth sigs and
+// earn keys. Transactions with unspent outputs are retained and pruned by a
+// later call once they are spent. Block headers, metadata and spend keys are
+// always kept, and pruned transactions are read back from the archive, so
+// exits of spent outputs can still be challenged. It returns the number of
+// transactions pruned.
+func (ps *Storage) Prune(before uint64) (int, error) {
+	if ps.archive == nil {
+		return 0, errors.New("no archive to prune blocks into")
+	}
+	lastPruned, err := ps.LastPrunedBlock()
+	if err != nil {
+		return 0, err
+	}
+
+	// transactions retained by earlier calls may have been spent since
+	removed, err := ps.pruneRetained()
+	if err != nil {
+		return removed, err
+	}
+
+	for blkNum := lastPruned + 1; blkNum < before; blkNum++ {
+		rec, err := snapshotBlockAt(ps.db, blkNum)
+		if err != nil {
+			return removed, errors.Wrap(err, fmt.Sprintf("failed to read block %d", blkNum))
+		}
+		offset, err := ps.archive.append(rec)
+		if err != nil {
+			return removed, errors.Wrap(err, fmt.Sprintf("failed to archive block %d", blkNum))
+		}
+
+		archivedSigs := make(map[uint32]bool)
+		for _, authSig := range rec.AuthSigs {
+			archivedSigs[authSig.TxIdx] = true
+		}
+
+		batch := new(leveldb.Batch)
+		count := 0
+		for txIdx, txEnc := range rec.Transactions {
+			var confirmed chain.ConfirmedTransaction
+			if err := rlp.DecodeBytes(txEnc, &confirmed); err != nil {
+				return removed, err
+			}
+			confirmed.Transaction.BlkNum = blkNum
+			confirmed.Transaction.TxIdx = uint32(txIdx)
+
+			spent, err := ps.isFullySpent(&confirmed)
+			if err != nil {
+				return removed, err
+			}
+			if !spent {
+				batch.Put(retainedKey(blkNum, uint32(txIdx)), nil)
+				continue
+			}
+			deletePrunedTransaction(&confirmed, archivedSigs[uint32(txIdx)], batch)
+			count++
+		}
+		batch.Put(archivedKey(blkNum), uint64ToBytes(uint64(offset)))
+		batch.Put(prefixKey(lastPrunedBlockKey), uint64ToBytes(blkNum))
+		if err := ps.db.Write(batch, nil); err != nil {
+			return removed, err
+		}
+		removed += count
+	}
+
+	return removed, nil
+}
+
+func (ps *Storage) pruneRetained() (int, error) {
+	iter := ps.db.NewIterator(levelutil.BytesPrefix([]byte(retainedKeyPrefix+keyPartsSeparator)), nil)
+	defer iter.Release()
+
+	batch := new(leveldb.Batch)
+	removed := 0
+	for iter.Next() {
+		parts := strings.Split(string(iter.Key()), keyPartsSeparator)
+		if len(parts) != 3 {
+			return 0, errors.New("invalid retained key")
+		}
+		blkNum, ok := util.Str2Uint64(parts[1])
+		if !ok {
+			return 0, errors.New("invalid retained key")
+		}
+		txIdx, ok := util.Str2Uint32(parts[2])
+		if !ok {
+			return 0, errors.New("invalid retained key")
+		}
+
+		confirmed, _, err := ps.findTransactionByBlockNumTxIdx(blkNum, txIdx)
+		if err != nil {
+			return 0, err
+		}
+		if confirmed == nil {
+			batch.Delete(iter.Key())
+			continue
+		}
+		spent, err := ps.isFullySpent(confirmed)
+		if err != nil {
+			return 0, err
+		}
+		if !spent {
+			continue
+		}
+		// the block was archived before the transaction was spent, so auth
+		// sigs added since are only in the database
+		deletePrunedTransaction(confirmed, false, batch)
+		batch.Delete(iter.Key())
+		removed++
+	}
+	if err := iter.Error(); err != nil {
+		return 0, err
+	}
+
+	return removed, ps.db.Write(batch, nil)
+}
+
+// isFullySpent reports whether every output of confirmed has been spent or
+// exited.
+func (ps *Storage) isFullySpent(confirmed *chain.ConfirmedTransaction) (bool, error) {
+	tx := &confirmed.Transaction
+	for i := uint8(0); i < tx.NumOutputs(); i++ {
+		output := tx.OutputAt(i)
+		if output.IsZeroOutput() || output.IsExit() {
+			continue
+		}
+
+		spent, err := ps.isOutputSpent(tx, i)
+		if err != nil || !spent {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (ps *Storage) isOutputSpent(tx *chain.Transaction, outIdx uint8) (bool, error) {
+	for _, key := range outputSpendKeys(tx, outIdx) {
+		found, err := ps.db.Has(key, nil)
+		if err != nil || found {
+			return found, err
+		}
+	}
+	return false, nil
+}
+
+// outputSpendKeys returns the spend and exit spend keys that are recorded
+// when output outIdx of tx is spent.
+func outputSpendKeys(tx *chain.Transaction, outIdx uint8) [][]byte {
+	output := tx.OutputAt(outIdx)
+	depositNonce := big.NewInt(0)
+	if output.IsDeposit() {
+		depositNonce = output.DepositNonce
+	}
+	input := chain.NewInput(tx.BlkNum, tx.TxIdx, outIdx, depositNonce, common.Address{})
+	return [][]byte{
+		spend(&output.Owner, input),
+		spendExit(&output.Owner, input),
+	}
+}
+
+// deletePrunedTransaction adds the deletion of confirmed, its hash indexed
+// copies, the earn keys of its outputs and, if dropSigs is set, its auth sigs
+// to batch. The spend keys pointing to it are needed to challenge exits, so
+// they are left alone.
+func deletePrunedTransaction(confirmed *chain.ConfirmedTransaction, dropSigs bool, batch *leveldb.Batch) {
+	tx := &confirmed.Transaction
+
+	hexHash := hexutil.Encode(confirmed.RLPHash(util.Sha256))
+	batch.Delete(txPrefixKey("hash", hexHash))
+	batch.Delete(blkNumHashkey(tx.BlkNum, hexHash))
+	batch.Delete(blkNumTxIdxKey(tx.BlkNum, tx.TxIdx))
+	if dropSigs {
+		batch.Delete(blkNumTxIdxAuthSigKey(tx.BlkNum, tx.TxIdx))
+	}
+
+	for i := uint8(0); i < tx.NumOutputs(); i++ {
+		output := tx.OutputAt(i)
+		if output.IsZeroOutput() {
+			continue
+		}
+		if i == 0 && output.IsDeposit() {
+			batch.Delete(depositKey(confirmed))
+		}
+		batch.Delete(earn(&output.Owner, *confirmed, i))
+	}
+}
+
+// archivedBlock returns the archived copy of block blkNum, with the auth sigs
+// that were only kept in snap, or nil if the block hasn't been pruned.
+func (ps *Storage) archivedBlock(snap levelReader, blkNum uint64) (*snapshotBlock, error) {
+	b, err := snap.Get(archivedKey(blkNum), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ps.archive == nil {
+		return nil, &PrunedError{What: fmt.Sprintf("block %d is only in the archive", blkNum)}
+	}
+	rec, err := ps.archive.blockAt(int64(bytesToUint64(b)))
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to read archived block %d", blkNum))
+	}
+
+	archivedSigs := make(map[uint32][]byte)
+	for _, authSig := range rec.AuthSigs {
+		archivedSigs[authSig.TxIdx] = authSig.Sigs
+	}
+	rec.AuthSigs = nil
+	for i := range rec.Transactions {
+		txIdx := uint32(i)
+		sigs, err := snap.Get(blkNumTxIdxAuthSigKey(blkNum, txIdx), nil)
+		if err == leveldb.ErrNotFound {
+			sigs = archivedSigs[txIdx]
+		} else if err != nil {
+			return nil, err
+		}
+		if sigs != nil {
+			rec.AuthSigs = append(rec.AuthSigs, snapshotAuthSig{
+				TxIdx: txIdx,
+				Sigs:  sigs,
+			})
+		}
+	}
+	return rec, nil
+}
+
+// blockRecordAt reads block blkNum from snap, or from the archive if it has
+// been pruned.
+func (ps *Storage) blockRecordAt(snap levelReader, blkNum uint64) (*snapshotBlock, error) {
+	rec, err := ps.archivedBlock(snap, blkNum)
+	if err != nil || rec != nil {
+		return rec, err
+	}
+	return snapshotBlockAt(snap, blkNum)
+}
+
+// archivedTransactions decodes the transactions of an archived block.
+func archivedTransactions(rec *snapshotBlock, blkNum uint64) ([]chain.ConfirmedTransaction, error) {
+	txs := make([]chain.ConfirmedTransaction, len(rec.Transactions))
+	for i, txEnc := range rec.Transactions {
+		if err := rlp.DecodeBytes(txEnc, &txs[i]); err != nil {
+			return nil, err
+		}
+		txs[i].Transaction.BlkNum = blkNum
+		txs[i].Transaction.TxIdx = uint32(i)
+	}
+	return txs, nil
+}
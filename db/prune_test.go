@@ -0,0 +1,121 @@
+package db
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/kyokan/plasma/chain"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestArchive(t *testing.T, ps *Storage) func() {
+	dir, err := ioutil.TempDir("", "plasma-prune")
+	require.NoError(t, err)
+	archive, err := OpenArchive(path.Join(dir, ArchiveName))
+	require.NoError(t, err)
+	ps.UseArchive(archive)
+	return func() {
+		archive.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPruneKeepsChallengeData(t *testing.T) {
+	level, ps := newLevelStorage(t)
+	defer level.Close()
+	seedStorage(t, ps)
+	sigs := []chain.Signature{chain.RandomConfirmationSig(), chain.RandomConfirmationSig()}
+	_, err := ps.ConfirmTransaction(3, 0, sigs)
+	require.NoError(t, err)
+
+	_, err = ps.Prune(4)
+	require.Error(t, err)
+	defer openTestArchive(t, ps)()
+
+	// only alice's deposit is fully spent
+	removed, err := ps.Prune(4)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	lastPruned, err := ps.LastPrunedBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), lastPruned)
+	has, err := level.Has(blkNumTxIdxKey(1, 0), nil)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// both outputs of block 3 are spent after it was archived
+	_, err = ps.PackageBlock([]chain.ConfirmedTransaction{
+		testSpend(3, 0, 0, testOutput(carol, 60)),
+		testSpend(3, 0, 1, testOutput(carol, 40)),
+	})
+	require.NoError(t, err)
+	removed, err = ps.Prune(5)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	for _, key := range [][]byte{blkNumTxIdxKey(3, 0), blkNumTxIdxKey(4, 0)} {
+		has, err := level.Has(key, nil)
+		require.NoError(t, err)
+		require.Equal(t, bytes.Equal(key, blkNumTxIdxKey(4, 0)), has, "%s", key)
+	}
+
+	deposit, err := ps.FindTransactionByBlockNumTxIdx(1, 0)
+	require.NoError(t, err)
+	require.Equal(t, alice, deposit.Transaction.Output0.Owner)
+	has, err = level.Has(earn(&alice, *deposit, 0), nil)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// exits of the spent outputs can still be challenged from the archive
+	spending, err := ps.FindDoubleSpendingTransaction(1, 0, 0)
+	require.NoError(t, err)
+	require.NotNil(t, spending)
+	require.Equal(t, uint64(3), spending.Transaction.BlkNum)
+	spending, err = ps.FindDoubleSpendingTransaction(3, 0, 1)
+	require.NoError(t, err)
+	require.NotNil(t, spending)
+	require.Equal(t, uint32(1), spending.Transaction.TxIdx)
+	stored, err := ps.AuthSigsFor(3, 0)
+	require.NoError(t, err)
+	require.Equal(t, sigs, stored)
+	txs, err := ps.FindTransactionsByBlockNum(3)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, bob, txs[0].Transaction.Output0.Owner)
+	txs, err = ps.FindTransactionsByBlockNum(4)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+
+	spending, err = ps.FindDoubleSpendingTransaction(2, 0, 0)
+	require.NoError(t, err)
+	require.Nil(t, spending)
+	_, err = ps.FindDoubleSpendingTransaction(3, 1, 0)
+	require.True(t, IsNotFound(err), "got %v", err)
+
+	requireBalance(t, ps, alice, 0)
+	requireBalance(t, ps, bob, 50)
+	requireBalance(t, ps, carol, 100)
+
+	report, err := ps.Verify(nil)
+	require.NoError(t, err)
+	require.True(t, report.OK(), "issues: %v", report.Issues)
+
+	var buf bytes.Buffer
+	require.NoError(t, ps.ExportSnapshot(&buf, nil))
+	imported, importedStorage := newLevelStorage(t)
+	defer imported.Close()
+	require.NoError(t, importedStorage.ImportSnapshot(bytes.NewReader(buf.Bytes()), nil))
+	requireBalance(t, importedStorage, carol, 100)
+	stored, err = importedStorage.AuthSigsFor(3, 0)
+	require.NoError(t, err)
+	require.Equal(t, sigs, stored)
+
+	// without its archive the pruned blocks can't be read
+	unarchived := &Storage{db: level}
+	_, err = unarchived.FindTransactionsByBlockNum(3)
+	require.True(t, IsPruned(err), "got %v", err)
+	_, err = unarchived.FindDoubleSpendingTransaction(1, 0, 0)
+	require.True(t, IsPruned(err), "got %v", err)
+}
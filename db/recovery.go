@@ -0,0 +1,160 @@
+package db
+
+import (
+	"bytes"
+	"log"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/util"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	levelutil "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// RepairPartialBlocks removes what is left of a block whose packaging was
+// interrupted. Blocks are written atomically, but databases written by older
+// versions could end up with a latest block that has no metadata, or with
+// transactions stored for a block that was never recorded.
+func (ps *Storage) RepairPartialBlocks() error {
+	latest, err := ps.LatestBlock()
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	var latestNum uint64
+	if latest != nil {
+		latestNum = latest.Header.Number
+		hasMeta, err := ps.db.Has(blockMetaPrefixKey(latestNum), nil)
+		if err != nil {
+			return err
+		}
+		// metadata is the last thing written for a block
+		if !hasMeta {
+			log.Printf("Block %d was only partially written, removing it.", latestNum)
+			batch.Delete(blockPrefixKey(hexutil.Encode(latest.BlockHash)))
+			batch.Delete(blockNumKey(latestNum))
+			batch.Delete(blockFeesKey(latestNum))
+			if latestNum == 1 {
+				batch.Delete(blockPrefixKey(latestKey))
+			} else {
+				prevKey, err := ps.db.Get(blockNumKey(latestNum-1), nil)
+				if err != nil {
+					return errors.Wrap(err, "failed to find the block preceding the partial block")
+				}
+				batch.Put(blockPrefixKey(latestKey), prevKey)
+			}
+			latestNum--
+		}
+	}
+
+	count, err := ps.deleteBlockTransactions(latestNum+1, batch)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("Removing %d transactions of partially written block %d.", count, latestNum+1)
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+	return ps.db.Write(batch, &opt.WriteOptions{Sync: true})
+}
+
+// deleteBlockTransactions adds the deletion of every transaction stored for
+// blkNum, along with its indexes, to batch.
+func (ps *Storage) deleteBlockTransactions(blkNum uint64, batch *leveldb.Batch) (int, error) {
+	prefix := txPrefixKey("blkNum", strconv.FormatUint(blkNum, 10), "txIdx")
+	prefix = append(prefix, ':', ':')
+
+	iter := ps.db.NewIterator(levelutil.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	count := 0
+	for iter.Next() {
+		txIdx, ok := util.Str2Uint32(string(iter.Key()[len(prefix):]))
+		if !ok {
+			return 0, errors.New("Failed to parse transaction index from key")
+		}
+		var confirmed chain.ConfirmedTransaction
+		if err := rlp.DecodeBytes(iter.Value(), &confirmed); err != nil {
+			return 0, err
+		}
+		confirmed.Transaction.BlkNum = blkNum
+		confirmed.Transaction.TxIdx = txIdx
+		tx := &confirmed.Transaction
+
+		hexHash := hexutil.Encode(confirmed.RLPHash(util.Sha256))
+		batch.Delete(txPrefixKey("hash", hexHash))
+		batch.Delete(blkNumHashkey(blkNum, hexHash))
+		batch.Delete(blkNumTxIdxKey(blkNum, txIdx))
+
+		for i := uint8(0); i < tx.NumInputs(); i++ {
+			input := tx.InputAt(i)
+			if input.IsZeroInput() {
+				continue
+			}
+			prevTx, _, err := ps.findPreviousTx(&confirmed, i)
+			if err != nil && !IsNotFound(err) {
+				return 0, err
+			}
+			if prevTx == nil || input.OutIdx >= prevTx.Transaction.NumOutputs() {
+				// the owner of the spent output is unknown, so find the
+				// spend keys by the spend they record instead
+				ident := chain.SpendIdentifier{
+					BlockNumber:      blkNum,
+					TransactionIndex: txIdx,
+					InputIndex:       i,
+				}
+				if err := ps.deleteSpendKeysOf(&ident, batch); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			owner := prevTx.Transaction.OutputAt(input.OutIdx).Owner
+			batch.Delete(spend(&owner, input))
+			batch.Delete(spendExit(&owner, input))
+		}
+
+		for i := uint8(0); i < tx.NumOutputs(); i++ {
+			output := tx.OutputAt(i)
+			if output.IsZeroOutput() {
+				continue
+			}
+			if i == 0 && output.IsDeposit() {
+				batch.Delete(depositKey(&confirmed))
+			}
+			batch.Delete(earn(&output.Owner, confirmed, i))
+		}
+		count++
+	}
+
+	return count, iter.Error()
+}
+
+// deleteSpendKeysOf adds the deletion of every spend and exit spend key that
+// records ident to batch.
+func (ps *Storage) deleteSpendKeysOf(ident *chain.SpendIdentifier, batch *leveldb.Batch) error {
+	identBytes, err := ident.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	for _, prefix := range []string{spendKeyPrefix, spendExitKeyPrefix} {
+		iter := ps.db.NewIterator(levelutil.BytesPrefix([]byte(prefix+keyPartsSeparator)), nil)
+		for iter.Next() {
+			if bytes.Equal(iter.Value(), identBytes) {
+				batch.Delete(append([]byte{}, iter.Key()...))
+			}
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
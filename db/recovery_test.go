@@ -0,0 +1,107 @@
+package db
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/kyokan/plasma/chain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairBlockWithoutMetadata(t *testing.T) {
+	level, ps := newLevelStorage(t)
+	defer level.Close()
+	seedStorage(t, ps)
+
+	// the write of block 3 stopped before its metadata
+	require.NoError(t, level.Delete(blockMetaPrefixKey(3), nil))
+	require.NoError(t, ps.RepairPartialBlocks())
+
+	latest, err := ps.LatestBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), latest.Header.Number)
+	_, err = ps.BlockAtHeight(3)
+	require.True(t, IsNotFound(err), "got %v", err)
+	txs, err := ps.FindTransactionsByBlockNum(3)
+	require.NoError(t, err)
+	require.Empty(t, txs)
+
+	// alice's deposit is unspent again
+	requireBalance(t, ps, alice, 100)
+	requireBalance(t, ps, bob, 50)
+	again := testSpend(1, 0, 0, testOutput(carol, 100))
+	spent, err := ps.IsDoubleSpent(&again)
+	require.NoError(t, err)
+	require.False(t, spent)
+
+	// the block can be packaged again
+	result, err := ps.PackageBlock([]chain.ConfirmedTransaction{again})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), result.BlockNumber.Uint64())
+	requireBalance(t, ps, carol, 100)
+}
+
+func TestRepairSpendOfMissingTransaction(t *testing.T) {
+	level, ps := newLevelStorage(t)
+	defer level.Close()
+	seedStorage(t, ps)
+
+	spendKey := rawSpend(&alice, 1, 0, 0, big.NewInt(0))
+	has, err := level.Has(spendKey, nil)
+	require.NoError(t, err)
+	require.True(t, has)
+
+	// block 3 is partial and the deposit it spends is gone as well
+	require.NoError(t, level.Delete(blockMetaPrefixKey(3), nil))
+	require.NoError(t, level.Delete(blkNumTxIdxKey(1, 0), nil))
+	require.NoError(t, ps.RepairPartialBlocks())
+
+	latest, err := ps.LatestBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), latest.Header.Number)
+	has, err = level.Has(spendKey, nil)
+	require.NoError(t, err)
+	require.False(t, has)
+	requireBalance(t, ps, bob, 50)
+}
+
+func TestRepairOrphanTransactions(t *testing.T) {
+	level, ps := newLevelStorage(t)
+	defer level.Close()
+	seedStorage(t, ps)
+
+	// only the transactions of block 3 were written
+	block2, err := level.Get(blockNumKey(2), nil)
+	require.NoError(t, err)
+	block3, err := ps.BlockAtHeight(3)
+	require.NoError(t, err)
+	require.NoError(t, level.Delete(blockPrefixKey(hexutil.Encode(block3.BlockHash)), nil))
+	require.NoError(t, level.Delete(blockNumKey(3), nil))
+	require.NoError(t, level.Put(blockPrefixKey(latestKey), block2, nil))
+
+	require.NoError(t, ps.RepairPartialBlocks())
+
+	latest, err := ps.LatestBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), latest.Header.Number)
+	txs, err := ps.FindTransactionsByBlockNum(3)
+	require.NoError(t, err)
+	require.Empty(t, txs)
+	requireBalance(t, ps, alice, 100)
+	requireBalance(t, ps, bob, 50)
+}
+
+func TestRepairIntactStore(t *testing.T) {
+	level, ps := newLevelStorage(t)
+	defer level.Close()
+	seedStorage(t, ps)
+
+	require.NoError(t, ps.RepairPartialBlocks())
+
+	latest, err := ps.LatestBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), latest.Header.Number)
+	requireBalance(t, ps, alice, 40)
+	requireBalance(t, ps, bob, 110)
+}
@@ -0,0 +1,238 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kyokan/plasma/chain"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	levelutil "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const schemaVersionKey = "SCHEMA_VERSION"
+const migrationCursorKeyPrefix = "MIGRATION_CURSOR"
+
+// CurrentSchemaVersion is the version of the LevelDB key layout written by
+// this build. Databases created before versioning was introduced are
+// version 0.
+const CurrentSchemaVersion = 1
+
+const DefaultMigrationBatchSize = 1000
+
+// Migration upgrades a database from Version-1 to Version. It visits every
+// key under Prefixes in order and records its changes in batch.
+type Migration struct {
+	Version     uint64
+	Description string
+	Prefixes    []string
+	Rewrite     func(level *leveldb.DB, key []byte, value []byte, batch *leveldb.Batch) error
+}
+
+type MigrationProgress struct {
+	Version     uint64
+	Description string
+	Processed   int
+	Done        bool
+}
+
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "record the correct input index for spends of a transaction's first input",
+		Prefixes:    []string{spendKeyPrefix + keyPartsSeparator, spendExitKeyPrefix + keyPartsSeparator},
+		Rewrite:     fixSpendInputIndex,
+	},
+}
+
+// SchemaVersion returns the schema version of the database.
+func SchemaVersion(level *leveldb.DB) (uint64, error) {
+	exists, err := level.Has([]byte(schemaVersionKey), nil)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		b, err := level.Get([]byte(schemaVersionKey), nil)
+		if err != nil {
+			return 0, err
+		}
+		return bytesToUint64(b), nil
+	}
+
+	iter := level.NewIterator(nil, nil)
+	defer iter.Release()
+	if iter.Next() {
+		return 0, nil
+	}
+
+	// a brand new database is written using the current layout
+	if err := level.Put([]byte(schemaVersionKey), uint64ToBytes(CurrentSchemaVersion), nil); err != nil {
+		return 0, err
+	}
+	return CurrentSchemaVersion, nil
+}
+
+func checkSchemaVersion(level *leveldb.DB) error {
+	version, err := SchemaVersion(level)
+	if err != nil {
+		return errors.Wrap(err, "failed to read schema version")
+	}
+	if version < CurrentSchemaVersion {
+		return errors.New(fmt.Sprintf("database schema version %d is older than %d, run `plasmad db migrate` to upgrade it", version, CurrentSchemaVersion))
+	}
+	if version > CurrentSchemaVersion {
+		return errors.New(fmt.Sprintf("database schema version %d was written by a newer version of plasmad", version))
+	}
+	return nil
+}
+
+// Migrate runs every pending migration. Each batch of rewritten keys is
+// written along with a cursor, so an interrupted migration resumes where it
+// left off.
+func Migrate(level *leveldb.DB, batchSize int, progress func(MigrationProgress)) error {
+	if batchSize < 1 {
+		return errors.New("batch size must be at least 1")
+	}
+
+	version, err := SchemaVersion(level)
+	if err != nil {
+		return err
+	}
+	if version > CurrentSchemaVersion {
+		return errors.New(fmt.Sprintf("database schema version %d was written by a newer version of plasmad", version))
+	}
+
+	for _, migration := range migrations {
+		if migration.Version <= version {
+			continue
+		}
+		if err := runMigration(level, migration, batchSize, progress); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("migration to version %d failed", migration.Version))
+		}
+	}
+	return nil
+}
+
+func runMigration(level *leveldb.DB, migration Migration, batchSize int, progress func(MigrationProgress)) error {
+	cursorKey := prefixKey(migrationCursorKeyPrefix, strconv.FormatUint(migration.Version, 10))
+	cursor, err := level.Get(cursorKey, nil)
+	if err != nil && err != leveldb.ErrNotFound {
+		return err
+	}
+
+	status := MigrationProgress{
+		Version:     migration.Version,
+		Description: migration.Description,
+	}
+	batch := new(leveldb.Batch)
+	pending := 0
+	flush := func(lastKey []byte) error {
+		batch.Put(cursorKey, lastKey)
+		if err := level.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+			return err
+		}
+		batch.Reset()
+		status.Processed += pending
+		pending = 0
+		progress(status)
+		return nil
+	}
+
+	for _, prefix := range migration.Prefixes {
+		rng := levelutil.BytesPrefix([]byte(prefix))
+		if cursor != nil && bytes.Compare(cursor, rng.Start) >= 0 {
+			if bytes.Compare(cursor, rng.Limit) >= 0 {
+				continue
+			}
+			rng.Start = append(append([]byte{}, cursor...), 0)
+		}
+
+		iter := level.NewIterator(rng, nil)
+		var lastKey []byte
+		for iter.Next() {
+			lastKey = append(lastKey[:0], iter.Key()...)
+			if err := migration.Rewrite(level, iter.Key(), iter.Value(), batch); err != nil {
+				iter.Release()
+				return errors.Wrap(err, fmt.Sprintf("failed to migrate key %s", iter.Key()))
+			}
+			pending++
+			if pending == batchSize {
+				if err := flush(lastKey); err != nil {
+					iter.Release()
+					return err
+				}
+			}
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return err
+		}
+		if pending > 0 {
+			if err := flush(lastKey); err != nil {
+				return err
+			}
+		}
+	}
+
+	batch.Delete(cursorKey)
+	batch.Put([]byte(schemaVersionKey), uint64ToBytes(migration.Version))
+	if err := level.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+		return err
+	}
+	status.Done = true
+	progress(status)
+	return nil
+}
+
+// fixSpendInputIndex rewrites spend identifiers that were recorded with input
+// index 1 even though they spend the transaction's first input.
+func fixSpendInputIndex(level *leveldb.DB, key []byte, value []byte, batch *leveldb.Batch) error {
+	parts := strings.Split(string(key), keyPartsSeparator)
+	if len(parts) != 6 {
+		return errors.New("invalid spend key")
+	}
+	blkNum, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return err
+	}
+	txIdx, err := strconv.ParseUint(parts[3], 10, 32)
+	if err != nil {
+		return err
+	}
+	outIdx, err := strconv.ParseUint(parts[4], 10, 8)
+	if err != nil {
+		return err
+	}
+
+	var ident chain.SpendIdentifier
+	if err := ident.UnmarshalBinary(value); err != nil {
+		return err
+	}
+	txEnc, err := level.Get(blkNumTxIdxKey(ident.BlockNumber, ident.TransactionIndex), nil)
+	if err != nil {
+		return err
+	}
+	var spending chain.ConfirmedTransaction
+	if err := rlp.DecodeBytes(txEnc, &spending); err != nil {
+		return err
+	}
+
+	input0 := spending.Transaction.Input0
+	if input0.BlkNum != blkNum || uint64(input0.TxIdx) != txIdx || uint64(input0.OutIdx) != outIdx {
+		return nil
+	}
+	if ident.InputIndex == 0 {
+		return nil
+	}
+	ident.InputIndex = 0
+	identBytes, err := ident.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	batch.Put(key, identBytes)
+	return nil
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/kyokan/plasma/chain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaVersionOfNewDatabase(t *testing.T) {
+	level, _ := newLevelStorage(t)
+	defer level.Close()
+
+	version, err := SchemaVersion(level)
+	require.NoError(t, err)
+	require.Equal(t, uint64(CurrentSchemaVersion), version)
+	require.NoError(t, checkSchemaVersion(level))
+}
+
+func TestMigrateOldStore(t *testing.T) {
+	level, ps := newLevelStorage(t)
+	defer level.Close()
+	_, err := SchemaVersion(level)
+	require.NoError(t, err)
+	seedStorage(t, ps)
+
+	// version 0 stores recorded spends of the first input with input index 1
+	spending, err := ps.FindTransactionByBlockNumTxIdx(3, 0)
+	require.NoError(t, err)
+	key := spend(&alice, spending.Transaction.Input0)
+	good, err := level.Get(key, nil)
+	require.NoError(t, err)
+	bad, err := (&chain.SpendIdentifier{BlockNumber: 3, TransactionIndex: 0, InputIndex: 1}).MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, level.Put(key, bad, nil))
+	require.NoError(t, level.Delete([]byte(schemaVersionKey), nil))
+
+	version, err := SchemaVersion(level)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), version)
+	require.Error(t, checkSchemaVersion(level))
+
+	var updates []MigrationProgress
+	require.NoError(t, Migrate(level, 1, func(p MigrationProgress) {
+		updates = append(updates, p)
+	}))
+	require.NotEmpty(t, updates)
+	last := updates[len(updates)-1]
+	require.True(t, last.Done)
+	require.Equal(t, uint64(1), last.Version)
+	require.Equal(t, 1, last.Processed)
+
+	fixed, err := level.Get(key, nil)
+	require.NoError(t, err)
+	require.Equal(t, good, fixed)
+	require.NoError(t, checkSchemaVersion(level))
+	has, err := level.Has(prefixKey(migrationCursorKeyPrefix, "1"), nil)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// migrating an up to date store does nothing
+	require.NoError(t, Migrate(level, 1, func(p MigrationProgress) {
+		t.Fatalf("unexpected migration to version %d", p.Version)
+	}))
+}
+
+func TestMigrateResumesFromCursor(t *testing.T) {
+	level, ps := newLevelStorage(t)
+	defer level.Close()
+	seedStorage(t, ps)
+
+	spending, err := ps.FindTransactionByBlockNumTxIdx(3, 0)
+	require.NoError(t, err)
+	key := spend(&alice, spending.Transaction.Input0)
+	bad, err := (&chain.SpendIdentifier{BlockNumber: 3, TransactionIndex: 0, InputIndex: 1}).MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, level.Put(key, bad, nil))
+	require.NoError(t, level.Delete([]byte(schemaVersionKey), nil))
+
+	// a cursor past the key means an earlier run already migrated it
+	require.NoError(t, level.Put(prefixKey(migrationCursorKeyPrefix, "1"), key, nil))
+	require.NoError(t, Migrate(level, 1, func(MigrationProgress) {}))
+
+	unchanged, err := level.Get(key, nil)
+	require.NoError(t, err)
+	require.Equal(t, bad, unchanged)
+	require.NoError(t, checkSchemaVersion(level))
+}
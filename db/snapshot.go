@@ -0,0 +1,454 @@
+package db
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/util"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	levelutil "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// A snapshot starts with snapshotMagic and the format version, followed by
+// records made of a one byte kind, a four byte big endian length and an RLP
+// payload. The final record holds the SHA-256 of everything preceding its
+// payload.
+const snapshotMagic = "PLASMASNAP"
+
+const SnapshotFormatVersion = 1
+
+// maxSnapshotRecordSize bounds the length a record header may claim. A full
+// block of multi-input transactions and their auth sigs stays well below it.
+const maxSnapshotRecordSize = 256 << 20
+
+const (
+	snapshotRecordBlock uint8 = iota + 1
+	snapshotRecordCursors
+	snapshotRecordEnd
+)
+
+// SnapshotStorage is implemented by storage backends that can export and
+// import snapshots.
+type SnapshotStorage interface {
+	ExportSnapshot(w io.Writer, progress func(blkNum uint64)) error
+	ImportSnapshot(r io.ReadSeeker, progress func(blkNum uint64)) error
+}
+
+// levelReader is satisfied by both *leveldb.DB and *leveldb.Snapshot.
+type levelReader interface {
+	Get(key []byte, ro *opt.ReadOptions) ([]byte, error)
+	NewIterator(slice *levelutil.Range, ro *opt.ReadOptions) iterator.Iterator
+}
+
+type snapshotBlock struct {
+	Block        []byte
+	Meta         []byte
+	Fees         []byte
+	Transactions [][]byte
+	AuthSigs     []snapshotAuthSig
+}
+
+type snapshotAuthSig struct {
+	TxIdx uint32
+	Sigs  []byte
+}
+
+type snapshotCursors struct {
+	DepositPoll        uint64
+	TxExitPoll         uint64
+	DepositExitIdx     uint64
+	LastSubmittedBlock uint64
+}
+
+type snapshotWriter struct {
+	w    io.Writer
+	hash hash.Hash
+}
+
+func newSnapshotWriter(w io.Writer) *snapshotWriter {
+	h := sha256.New()
+	return &snapshotWriter{
+		w:    io.MultiWriter(w, h),
+		hash: h,
+	}
+}
+
+func (sw *snapshotWriter) writeHeader() error {
+	buf := make([]byte, len(snapshotMagic)+4)
+	copy(buf, snapshotMagic)
+	binary.BigEndian.PutUint32(buf[len(snapshotMagic):], SnapshotFormatVersion)
+	_, err := sw.w.Write(buf)
+	return err
+}
+
+func (sw *snapshotWriter) writeRecordHeader(kind uint8, length int) error {
+	var buf [5]byte
+	buf[0] = kind
+	binary.BigEndian.PutUint32(buf[1:], uint32(length))
+	_, err := sw.w.Write(buf[:])
+	return err
+}
+
+func (sw *snapshotWriter) writeRecord(kind uint8, val interface{}) error {
+	payload, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return err
+	}
+	if err := sw.writeRecordHeader(kind, len(payload)); err != nil {
+		return err
+	}
+	_, err = sw.w.Write(payload)
+	return err
+}
+
+func (sw *snapshotWriter) close() error {
+	if err := sw.writeRecordHeader(snapshotRecordEnd, sha256.Size); err != nil {
+		return err
+	}
+	_, err := sw.w.Write(sw.hash.Sum(nil))
+	return err
+}
+
+type snapshotReader struct {
+	r    io.Reader
+	hash hash.Hash
+}
+
+func newSnapshotReader(r io.Reader) *snapshotReader {
+	h := sha256.New()
+	return &snapshotReader{
+		r:    io.TeeReader(r, h),
+		hash: h,
+	}
+}
+
+func (sr *snapshotReader) readHeader() error {
+	buf := make([]byte, len(snapshotMagic)+4)
+	if _, err := io.ReadFull(sr.r, buf); err != nil {
+		return errors.Wrap(err, "failed to read snapshot header")
+	}
+	if string(buf[:len(snapshotMagic)]) != snapshotMagic {
+		return errors.New("not a plasma snapshot")
+	}
+	version := binary.BigEndian.Uint32(buf[len(snapshotMagic):])
+	if version != SnapshotFormatVersion {
+		return errors.New(fmt.Sprintf("unsupported snapshot format version %d", version))
+	}
+	return nil
+}
+
+// next reads the next record. The checksum is verified when the final record
+// is reached.
+func (sr *snapshotReader) next() (uint8, []byte, error) {
+	var buf [5]byte
+	if _, err := io.ReadFull(sr.r, buf[:]); err != nil {
+		return 0, nil, errors.Wrap(err, "snapshot is truncated")
+	}
+	kind := buf[0]
+	length := binary.BigEndian.Uint32(buf[1:])
+
+	var expected []byte
+	if kind == snapshotRecordEnd {
+		expected = sr.hash.Sum(nil)
+	}
+
+	if length > maxSnapshotRecordSize {
+		return 0, nil, errors.New(fmt.Sprintf("snapshot record of %d bytes is too large", length))
+	}
+
+	// grow the buffer as the payload arrives rather than trusting the length
+	// up front, so a truncated snapshot cannot make us allocate it all
+	var payload bytes.Buffer
+	if _, err := io.CopyN(&payload, sr.r, int64(length)); err != nil {
+		return 0, nil, errors.Wrap(err, "snapshot is truncated")
+	}
+	if kind == snapshotRecordEnd && !bytes.Equal(expected, payload.Bytes()) {
+		return 0, nil, errors.New("snapshot checksum mismatch")
+	}
+	return kind, payload.Bytes(), nil
+}
+
+// VerifySnapshot reads a whole snapshot and checks its format and checksum.
+func VerifySnapshot(r io.Reader) error {
+	sr := newSnapshotReader(r)
+	if err := sr.readHeader(); err != nil {
+		return err
+	}
+	for {
+		kind, _, err := sr.next()
+		if err != nil {
+			return err
+		}
+		if kind == snapshotRecordEnd {
+			return nil
+		}
+	}
+}
+
+// ExportSnapshot writes every block along with its transactions, auth sigs,
+// metadata and fees, followed by the poll cursors. Everything is read from a
+// single LevelDB snapshot, so blocks may keep being packaged meanwhile.
+// Pruned blocks are read from the archive, so exporting a pruned database
+// needs the archive it was pruned into.
+func (ps *Storage) ExportSnapshot(w io.Writer, progress func(blkNum uint64)) error {
+	snap, err := ps.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+
+	sw := newSnapshotWriter(w)
+	if err := sw.writeHeader(); err != nil {
+		return err
+	}
+
+	latestNum, err := snapshotLatestBlockNum(snap)
+	if err != nil {
+		return err
+	}
+	for blkNum := uint64(1); blkNum <= latestNum; blkNum++ {
+		rec, err := ps.blockRecordAt(snap, blkNum)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to export block %d", blkNum))
+		}
+		if err := sw.writeRecord(snapshotRecordBlock, rec); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(blkNum)
+		}
+	}
+
+	var cursors snapshotCursors
+	for key, dest := range map[string]*uint64{
+		latestDepositIdxKey:   &cursors.DepositPoll,
+		lastTxExitPollKey:     &cursors.TxExitPoll,
+		latestDepExitIdxKey:   &cursors.DepositExitIdx,
+		lastSubmittedBlockKey: &cursors.LastSubmittedBlock,
+	} {
+		b, err := snap.Get(prefixKey(key), nil)
+		if err == leveldb.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		*dest = bytesToUint64(b)
+	}
+	if err := sw.writeRecord(snapshotRecordCursors, &cursors); err != nil {
+		return err
+	}
+
+	return sw.close()
+}
+
+func snapshotLatestBlockNum(snap levelReader) (uint64, error) {
+	topKey, err := snap.Get(blockPrefixKey(latestKey), nil)
+	if err == leveldb.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	data, err := snap.Get(topKey, nil)
+	if err != nil {
+		return 0, err
+	}
+	var blk chain.Block
+	if err := rlp.DecodeBytes(data, &blk); err != nil {
+		return 0, err
+	}
+	return blk.Header.Number, nil
+}
+
+func snapshotBlockAt(snap levelReader, blkNum uint64) (*snapshotBlock, error) {
+	var rec snapshotBlock
+	blkKey, err := snap.Get(blockNumKey(blkNum), nil)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Block, err = snap.Get(blkKey, nil); err != nil {
+		return nil, err
+	}
+	if rec.Meta, err = snap.Get(blockMetaPrefixKey(blkNum), nil); err != nil {
+		return nil, err
+	}
+	if rec.Fees, err = snap.Get(blockFeesKey(blkNum), nil); err != nil {
+		return nil, err
+	}
+
+	prefix := txPrefixKey("blkNum", strconv.FormatUint(blkNum, 10), "txIdx")
+	prefix = append(prefix, ':', ':')
+	iter := snap.NewIterator(levelutil.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	txs := make(map[uint32][]byte)
+	var indexes []uint32
+	for iter.Next() {
+		txIdx, ok := util.Str2Uint32(string(iter.Key()[len(prefix):]))
+		if !ok {
+			return nil, errors.New("Failed to parse transaction index from key")
+		}
+		txs[txIdx] = append([]byte{}, iter.Value()...)
+		indexes = append(indexes, txIdx)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i] < indexes[j]
+	})
+
+	for i, txIdx := range indexes {
+		if uint32(i) != txIdx {
+			return nil, errors.New(fmt.Sprintf("transaction %d is missing", i))
+		}
+		rec.Transactions = append(rec.Transactions, txs[txIdx])
+
+		sigs, err := snap.Get(blkNumTxIdxAuthSigKey(blkNum, txIdx), nil)
+		if err == leveldb.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rec.AuthSigs = append(rec.AuthSigs, snapshotAuthSig{
+			TxIdx: txIdx,
+			Sigs:  sigs,
+		})
+	}
+
+	return &rec, nil
+}
+
+// ImportSnapshot loads a snapshot into an empty database. The checksum is
+// verified before anything is written, and every block is rebuilt from its
+// transactions so that its merkle root, previous hash and fees can be checked
+// against the exported header.
+func (ps *Storage) ImportSnapshot(r io.ReadSeeker, progress func(blkNum uint64)) error {
+	latest, err := ps.LatestBlock()
+	if err != nil {
+		return err
+	}
+	if latest != nil {
+		return errors.New("snapshots can only be imported into an empty database")
+	}
+
+	if err := VerifySnapshot(r); err != nil {
+		return err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	sr := newSnapshotReader(r)
+	if err := sr.readHeader(); err != nil {
+		return err
+	}
+
+	var prevBlock *chain.Block
+	for {
+		kind, payload, err := sr.next()
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case snapshotRecordBlock:
+			var rec snapshotBlock
+			if err := rlp.DecodeBytes(payload, &rec); err != nil {
+				return err
+			}
+			block, err := ps.importBlock(prevBlock, &rec)
+			if err != nil {
+				return err
+			}
+			prevBlock = block
+			if progress != nil {
+				progress(block.Header.Number)
+			}
+		case snapshotRecordCursors:
+			var cursors snapshotCursors
+			if err := rlp.DecodeBytes(payload, &cursors); err != nil {
+				return err
+			}
+			batch := new(leveldb.Batch)
+			batch.Put(prefixKey(latestDepositIdxKey), uint64ToBytes(cursors.DepositPoll))
+			batch.Put(prefixKey(lastTxExitPollKey), uint64ToBytes(cursors.TxExitPoll))
+			batch.Put(prefixKey(latestDepExitIdxKey), uint64ToBytes(cursors.DepositExitIdx))
+			batch.Put(prefixKey(lastSubmittedBlockKey), uint64ToBytes(cursors.LastSubmittedBlock))
+			if err := ps.db.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+				return err
+			}
+		case snapshotRecordEnd:
+			return nil
+		default:
+			return errors.New(fmt.Sprintf("unknown snapshot record kind %d", kind))
+		}
+	}
+}
+
+func (ps *Storage) importBlock(prevBlock *chain.Block, rec *snapshotBlock) (*chain.Block, error) {
+	var expected chain.Block
+	if err := rlp.DecodeBytes(rec.Block, &expected); err != nil {
+		return nil, err
+	}
+	blkNum := expected.Header.Number
+	var meta chain.BlockMetadata
+	if err := meta.FromRLP(rec.Meta); err != nil {
+		return nil, err
+	}
+
+	txs := make([]chain.ConfirmedTransaction, len(rec.Transactions))
+	for i, txEnc := range rec.Transactions {
+		if err := rlp.DecodeBytes(txEnc, &txs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	batch := new(leveldb.Batch)
+	block, result, err := ps.addBlock(prevBlock, txs, meta.CreatedAt, batch)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to import block %d", blkNum))
+	}
+
+	if block.Header.Number != blkNum {
+		return nil, errors.New(fmt.Sprintf("expected block %d, got block %d", block.Header.Number, blkNum))
+	}
+	if !bytes.Equal(block.Header.MerkleRoot, expected.Header.MerkleRoot) {
+		return nil, errors.New(fmt.Sprintf("merkle root mismatch in block %d", blkNum))
+	}
+	if !bytes.Equal(block.Header.PrevHash, expected.Header.PrevHash) {
+		return nil, errors.New(fmt.Sprintf("previous hash mismatch in block %d", blkNum))
+	}
+	if !bytes.Equal(expected.Header.Hash(), expected.BlockHash) || !bytes.Equal(block.BlockHash, expected.BlockHash) {
+		return nil, errors.New(fmt.Sprintf("block hash mismatch in block %d", blkNum))
+	}
+	if new(big.Int).SetBytes(rec.Fees).Cmp(result.BlockFees) != 0 {
+		return nil, errors.New(fmt.Sprintf("fee mismatch in block %d", blkNum))
+	}
+
+	for _, authSig := range rec.AuthSigs {
+		if int(authSig.TxIdx) >= len(txs) {
+			return nil, errors.New(fmt.Sprintf("auth sigs for unknown transaction %d in block %d", authSig.TxIdx, blkNum))
+		}
+		batch.Put(blkNumTxIdxAuthSigKey(blkNum, authSig.TxIdx), authSig.Sigs)
+	}
+
+	if err := ps.db.Write(batch, nil); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kyokan/plasma/chain"
+	"github.com/stretchr/testify/require"
+)
+
+func exportTestSnapshot(t *testing.T) ([]byte, []chain.Signature) {
+	level, ps := newLevelStorage(t)
+	defer level.Close()
+	seedStorage(t, ps)
+
+	sigs := []chain.Signature{chain.RandomConfirmationSig(), chain.RandomConfirmationSig()}
+	_, err := ps.ConfirmTransaction(3, 0, sigs)
+	require.NoError(t, err)
+	require.NoError(t, ps.SaveDepositPoll(12))
+	require.NoError(t, ps.SaveTxExitPoll(13))
+	require.NoError(t, ps.SaveDepositExitEventIdx(14))
+	require.NoError(t, ps.SaveLastSubmittedBlock(3))
+
+	var buf bytes.Buffer
+	var exported []uint64
+	require.NoError(t, ps.ExportSnapshot(&buf, func(blkNum uint64) {
+		exported = append(exported, blkNum)
+	}))
+	require.Equal(t, []uint64{1, 2, 3}, exported)
+	return buf.Bytes(), sigs
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	snapshot, sigs := exportTestSnapshot(t)
+	require.NoError(t, VerifySnapshot(bytes.NewReader(snapshot)))
+
+	level, ps := newLevelStorage(t)
+	defer level.Close()
+	var imported []uint64
+	require.NoError(t, ps.ImportSnapshot(bytes.NewReader(snapshot), func(blkNum uint64) {
+		imported = append(imported, blkNum)
+	}))
+	require.Equal(t, []uint64{1, 2, 3}, imported)
+
+	latest, err := ps.LatestBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), latest.Header.Number)
+	requireBalance(t, ps, alice, 40)
+	requireBalance(t, ps, bob, 110)
+
+	stored, err := ps.AuthSigsFor(3, 0)
+	require.NoError(t, err)
+	require.Equal(t, sigs, stored)
+
+	for _, cursor := range []struct {
+		load     func() (uint64, error)
+		expected uint64
+	}{
+		{ps.LastDepositPoll, 12},
+		{ps.LastTxExitPoll, 13},
+		{ps.LastDepositExitEventIdx, 14},
+		{ps.LastSubmittedBlock, 3},
+	} {
+		value, err := cursor.load()
+		require.NoError(t, err)
+		require.Equal(t, cursor.expected, value)
+	}
+
+	report, err := ps.Verify(nil)
+	require.NoError(t, err)
+	require.True(t, report.OK(), "issues: %v", report.Issues)
+
+	// a second import would overwrite the chain
+	require.Error(t, ps.ImportSnapshot(bytes.NewReader(snapshot), nil))
+}
+
+func TestSnapshotRejectsCorruption(t *testing.T) {
+	snapshot, _ := exportTestSnapshot(t)
+
+	corrupted := append([]byte{}, snapshot...)
+	corrupted[len(snapshotMagic)+20] ^= 0xff
+	require.EqualError(t, VerifySnapshot(bytes.NewReader(corrupted)), "snapshot checksum mismatch")
+
+	truncated := snapshot[:len(snapshot)-10]
+	require.Error(t, VerifySnapshot(bytes.NewReader(truncated)))
+
+	// the first record claims to be 4GB long
+	oversized := append([]byte{}, snapshot[:len(snapshotMagic)+4]...)
+	oversized = append(oversized, snapshotRecordBlock, 0xff, 0xff, 0xff, 0xff)
+	require.EqualError(t, VerifySnapshot(bytes.NewReader(oversized)), "snapshot record of 4294967295 bytes is too large")
+
+	level, ps := newLevelStorage(t)
+	defer level.Close()
+	require.Error(t, ps.ImportSnapshot(bytes.NewReader(corrupted), nil))
+	latest, err := ps.LatestBlock()
+	require.NoError(t, err)
+	require.Nil(t, latest)
+}
@@ -0,0 +1,638 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/util"
+	"github.com/pkg/errors"
+)
+
+const sqlSchema = `
+CREATE TABLE IF NOT EXISTS blocks (
+	number      INTEGER PRIMARY KEY,
+	hash        BLOB    NOT NULL UNIQUE,
+	merkle_root BLOB    NOT NULL,
+	prev_hash   BLOB,
+	created_at  INTEGER NOT NULL,
+	tx_count    INTEGER NOT NULL,
+	fees        TEXT    NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+	block_number INTEGER NOT NULL REFERENCES blocks (number),
+	tx_idx       INTEGER NOT NULL,
+	hash         BLOB    NOT NULL,
+	fee          TEXT    NOT NULL,
+	rlp          BLOB    NOT NULL,
+	PRIMARY KEY (block_number, tx_idx)
+);
+CREATE INDEX IF NOT EXISTS transactions_hash ON transactions (hash);
+
+CREATE TABLE IF NOT EXISTS outputs (
+	block_number  INTEGER NOT NULL,
+	tx_idx        INTEGER NOT NULL,
+	out_idx       INTEGER NOT NULL,
+	owner         TEXT    NOT NULL,
+	amount        TEXT    NOT NULL,
+	deposit_nonce TEXT    NOT NULL,
+	PRIMARY KEY (block_number, tx_idx, out_idx),
+	FOREIGN KEY (block_number, tx_idx) REFERENCES transactions (block_number, tx_idx)
+);
+
+CREATE TABLE IF NOT EXISTS extra_auth_sigs (
+	block_number INTEGER NOT NULL,
+	tx_idx       INTEGER NOT NULL,
+	input_idx    INTEGER NOT NULL,
+	sig          BLOB    NOT NULL,
+	PRIMARY KEY (block_number, tx_idx, input_idx),
+	FOREIGN KEY (block_number, tx_idx) REFERENCES transactions (block_number, tx_idx)
+);
+CREATE INDEX IF NOT EXISTS outputs_owner ON outputs (owner);
+CREATE INDEX IF NOT EXISTS outputs_deposit_nonce ON outputs (deposit_nonce);
+
+CREATE TABLE IF NOT EXISTS spends (
+	block_number          INTEGER NOT NULL,
+	tx_idx                INTEGER NOT NULL,
+	out_idx               INTEGER NOT NULL,
+	owner                 TEXT    NOT NULL,
+	spending_block_number INTEGER NOT NULL,
+	spending_tx_idx       INTEGER NOT NULL,
+	spending_input_idx    INTEGER NOT NULL,
+	is_exit               INTEGER NOT NULL,
+	PRIMARY KEY (block_number, tx_idx, out_idx, is_exit)
+);
+CREATE INDEX IF NOT EXISTS spends_owner ON spends (owner);
+
+CREATE TABLE IF NOT EXISTS auth_sigs (
+	block_number INTEGER NOT NULL,
+	tx_idx       INTEGER NOT NULL,
+	sig0         BLOB    NOT NULL,
+	sig1         BLOB    NOT NULL,
+	PRIMARY KEY (block_number, tx_idx),
+	FOREIGN KEY (block_number, tx_idx) REFERENCES transactions (block_number, tx_idx)
+);
+
+CREATE TABLE IF NOT EXISTS cursors (
+	name  TEXT    PRIMARY KEY,
+	value INTEGER NOT NULL
+);
+`
+
+// sqlQuerier is satisfied by both *sql.DB and *sql.Tx, so lookups can run
+// inside or outside of a block's transaction.
+type sqlQuerier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// SQLStorage is a PlasmaStorage backed by a relational database. Unlike
+// Storage, its tables can be queried directly by external tools.
+type SQLStorage struct {
+	db *sql.DB
+}
+
+func NewSQLStorage(db *sql.DB) (PlasmaStorage, error) {
+	if _, err := db.Exec(sqlSchema); err != nil {
+		return nil, errors.Wrap(err, "failed to create schema")
+	}
+
+	return &SQLStorage{
+		db: db,
+	}, nil
+}
+
+func (ps *SQLStorage) ProcessDeposit(confirmed chain.ConfirmedTransaction) (*BlockResult, error) {
+	if !confirmed.Transaction.IsDeposit() {
+		return nil, errors.New("only deposit blocks are accepted")
+	}
+
+	return ps.PackageBlock([]chain.ConfirmedTransaction{confirmed})
+}
+
+func (ps *SQLStorage) PackageBlock(txs []chain.ConfirmedTransaction) (*BlockResult, error) {
+	prevBlock, err := ps.LatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	block := nextBlock(prevBlock, txs)
+	blkNum := block.Header.Number
+
+	dbTx, err := ps.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+
+	currentFees := big.NewInt(0)
+	for _, tx := range txs {
+		currentFees = currentFees.Add(currentFees, tx.Transaction.Fee)
+	}
+
+	_, err = dbTx.Exec(
+		"INSERT INTO blocks (number, hash, merkle_root, prev_hash, created_at, tx_count, fees) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		blkNum,
+		[]byte(block.BlockHash),
+		[]byte(block.Header.MerkleRoot),
+		[]byte(block.Header.PrevHash),
+		time.Now().Unix(),
+		len(txs),
+		currentFees.Text(10),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, tx := range txs {
+		if err := ps.saveTransaction(dbTx, blkNum, uint32(i), tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &BlockResult{
+		MerkleRoot:         block.Header.MerkleRoot,
+		NumberTransactions: uint32(len(txs)),
+		BlockFees:          currentFees,
+		BlockNumber:        util.Uint642Big(blkNum),
+	}, nil
+}
+
+func (ps *SQLStorage) saveTransaction(q sqlQuerier, blkNum uint64, txIdx uint32, confirmed chain.ConfirmedTransaction) error {
+	confirmed.Transaction.BlkNum = blkNum
+	confirmed.Transaction.TxIdx = txIdx
+	tx := &confirmed.Transaction
+
+	txEnc, err := rlp.EncodeToBytes(&confirmed)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(
+		"INSERT INTO transactions (block_number, tx_idx, hash, fee, rlp) VALUES (?, ?, ?, ?, ?)",
+		blkNum,
+		txIdx,
+		[]byte(confirmed.RLPHash(util.Sha256)),
+		tx.Fee.Text(10),
+		txEnc,
+	)
+	if err != nil {
+		return err
+	}
+
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if input.IsZeroInput() {
+			continue
+		}
+
+		prevTx, err := findSQLTransaction(q, input.BlkNum, input.TxIdx)
+		if err != nil {
+			return err
+		}
+		if prevTx == nil {
+			return fmt.Errorf("input %d not found", i)
+		}
+		owner := prevTx.Transaction.OutputAt(input.OutIdx).Owner
+		isExit := i == 0 && tx.Output0.IsExit()
+		var count int
+		err = q.QueryRow(
+			"SELECT COUNT(*) FROM spends WHERE block_number = ? AND tx_idx = ? AND out_idx = ? AND is_exit = ?",
+			input.BlkNum,
+			input.TxIdx,
+			input.OutIdx,
+			isExit,
+		).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return conflictingSpend(txIdx, i)
+		}
+		_, err = q.Exec(
+			"INSERT INTO spends (block_number, tx_idx, out_idx, owner, spending_block_number, spending_tx_idx, spending_input_idx, is_exit) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			input.BlkNum,
+			input.TxIdx,
+			input.OutIdx,
+			util.AddressToHex(&owner),
+			blkNum,
+			txIdx,
+			i,
+			isExit,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := uint8(0); i < tx.NumOutputs(); i++ {
+		output := tx.OutputAt(i)
+		if output.IsZeroOutput() {
+			continue
+		}
+
+		depositNonce := big.NewInt(0)
+		if output.DepositNonce != nil {
+			depositNonce = output.DepositNonce
+		}
+		_, err = q.Exec(
+			"INSERT INTO outputs (block_number, tx_idx, out_idx, owner, amount, deposit_nonce) VALUES (?, ?, ?, ?, ?, ?)",
+			blkNum,
+			txIdx,
+			i,
+			util.AddressToHex(&output.Owner),
+			output.Denom.Text(10),
+			depositNonce.Text(10),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ps *SQLStorage) FindTransactionsByBlockNum(blkNum uint64) ([]chain.ConfirmedTransaction, error) {
+	if err := checkHeight(blkNum); err != nil {
+		return nil, err
+	}
+	rows, err := ps.db.Query("SELECT block_number, tx_idx, rlp FROM transactions WHERE block_number = ? ORDER BY tx_idx", blkNum)
+	if err != nil {
+		return nil, err
+	}
+	return scanSQLTransactions(rows)
+}
+
+func (ps *SQLStorage) FindTransactionByBlockNumTxIdx(blkNum uint64, txIdx uint32) (*chain.ConfirmedTransaction, error) {
+	if _, err := ps.BlockAtHeight(blkNum); err != nil {
+		return nil, err
+	}
+
+	return findSQLTransaction(ps.db, blkNum, txIdx)
+}
+
+func (ps *SQLStorage) Balance(addr *common.Address) (*big.Int, error) {
+	outputs, err := ps.SpendableOutputs(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	total := big.NewInt(0)
+	for _, owned := range outputs {
+		total = total.Add(total, owned.Output().Denom)
+	}
+	return total, nil
+}
+
+func (ps *SQLStorage) SpendableOutputs(addr *common.Address) ([]OwnedOutput, error) {
+	rows, err := ps.db.Query(`
+		SELECT t.block_number, t.tx_idx, t.rlp, o.out_idx FROM outputs o
+		JOIN transactions t ON t.block_number = o.block_number AND t.tx_idx = o.tx_idx
+		WHERE o.owner = ? AND NOT EXISTS (
+			SELECT 1 FROM spends s
+			WHERE s.block_number = o.block_number AND s.tx_idx = o.tx_idx AND s.out_idx = o.out_idx AND s.is_exit = 0
+		)
+		ORDER BY o.block_number, o.tx_idx, o.out_idx`,
+		util.AddressToHex(addr),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanSQLOutputs(rows)
+}
+
+func (ps *SQLStorage) Outputs(addr *common.Address) ([]OwnedOutput, error) {
+	rows, err := ps.db.Query(`
+		SELECT t.block_number, t.tx_idx, t.rlp, o.out_idx FROM outputs o
+		JOIN transactions t ON t.block_number = o.block_number AND t.tx_idx = o.tx_idx
+		WHERE o.owner = ?
+		ORDER BY o.block_number, o.tx_idx, o.out_idx`,
+		util.AddressToHex(addr),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanSQLOutputs(rows)
+}
+
+func (ps *SQLStorage) BlockAtHeight(num uint64) (*chain.Block, error) {
+	if err := checkHeight(num); err != nil {
+		return nil, err
+	}
+	row := ps.db.QueryRow("SELECT number, hash, merkle_root, prev_hash FROM blocks WHERE number = ?", num)
+	blk, err := scanSQLBlock(row)
+	if err != nil {
+		return nil, notFound(err, fmt.Sprintf("block %d", num))
+	}
+	return blk, nil
+}
+
+func (ps *SQLStorage) BlockMetaAtHeight(num uint64) (*chain.BlockMetadata, error) {
+	if err := checkHeight(num); err != nil {
+		return nil, err
+	}
+	var meta chain.BlockMetadata
+	var fees string
+	err := ps.db.QueryRow("SELECT created_at, tx_count, fees FROM blocks WHERE number = ?", num).
+		Scan(&meta.CreatedAt, &meta.TransactionCount, &fees)
+	if err != nil {
+		return nil, notFound(err, fmt.Sprintf("block %d", num))
+	}
+
+	var ok bool
+	meta.Fees, ok = new(big.Int).SetString(fees, 10)
+	if !ok {
+		return nil, errors.New("failed to parse block fees")
+	}
+	return &meta, nil
+}
+
+func (ps *SQLStorage) LatestBlock() (*chain.Block, error) {
+	row := ps.db.QueryRow("SELECT number, hash, merkle_root, prev_hash FROM blocks ORDER BY number DESC LIMIT 1")
+	blk, err := scanSQLBlock(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return blk, err
+}
+
+// ConfirmTransaction stores the first two signatures in auth_sigs and those
+// of any further inputs of a multi transaction in extra_auth_sigs.
+func (ps *SQLStorage) ConfirmTransaction(blockNumber uint64, transactionIndex uint32, sigs []chain.Signature) (*chain.ConfirmedTransaction, error) {
+	tx, err := ps.FindTransactionByBlockNumTxIdx(blockNumber, transactionIndex)
+	if err != nil {
+		return nil, err
+	}
+	if len(sigs) < 2 {
+		padded := make([]chain.Signature, 2)
+		copy(padded, sigs)
+		sigs = padded
+	}
+
+	dbTx, err := ps.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+
+	_, err = dbTx.Exec(
+		"INSERT OR REPLACE INTO auth_sigs (block_number, tx_idx, sig0, sig1) VALUES (?, ?, ?, ?)",
+		blockNumber,
+		transactionIndex,
+		sigs[0][:],
+		sigs[1][:],
+	)
+	if err != nil {
+		return nil, err
+	}
+	_, err = dbTx.Exec("DELETE FROM extra_auth_sigs WHERE block_number = ? AND tx_idx = ?", blockNumber, transactionIndex)
+	if err != nil {
+		return nil, err
+	}
+	for i := 2; i < len(sigs); i++ {
+		_, err = dbTx.Exec(
+			"INSERT INTO extra_auth_sigs (block_number, tx_idx, input_idx, sig) VALUES (?, ?, ?, ?)",
+			blockNumber,
+			transactionIndex,
+			i,
+			sigs[i][:],
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := dbTx.Commit(); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (ps *SQLStorage) AuthSigsFor(blockNumber uint64, transactionIndex uint32) ([]chain.Signature, error) {
+	var sig0, sig1 []byte
+	err := ps.db.QueryRow("SELECT sig0, sig1 FROM auth_sigs WHERE block_number = ? AND tx_idx = ?", blockNumber, transactionIndex).
+		Scan(&sig0, &sig1)
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{What: "confirmation signatures"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sigs := make([]chain.Signature, 2)
+	copy(sigs[0][:], sig0)
+	copy(sigs[1][:], sig1)
+
+	rows, err := ps.db.Query("SELECT sig FROM extra_auth_sigs WHERE block_number = ? AND tx_idx = ? ORDER BY input_idx", blockNumber, transactionIndex)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var sig chain.Signature
+		copy(sig[:], raw)
+		sigs = append(sigs, sig)
+	}
+	return sigs, rows.Err()
+}
+
+func (ps *SQLStorage) HasAuthSigs(blockNumber uint64, transactionIndex uint32) (bool, error) {
+	var count int
+	err := ps.db.QueryRow("SELECT COUNT(*) FROM auth_sigs WHERE block_number = ? AND tx_idx = ?", blockNumber, transactionIndex).
+		Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (ps *SQLStorage) LastDepositPoll() (uint64, error) {
+	return ps.cursor(latestDepositIdxKey)
+}
+
+func (ps *SQLStorage) SaveDepositPoll(idx uint64) error {
+	return ps.saveCursor(latestDepositIdxKey, idx)
+}
+
+func (ps *SQLStorage) LastTxExitPoll() (uint64, error) {
+	return ps.cursor(lastTxExitPollKey)
+}
+
+func (ps *SQLStorage) SaveTxExitPoll(idx uint64) error {
+	return ps.saveCursor(lastTxExitPollKey, idx)
+}
+
+func (ps *SQLStorage) LastDepositExitEventIdx() (uint64, error) {
+	return ps.cursor(latestDepExitIdxKey)
+}
+
+func (ps *SQLStorage) SaveDepositExitEventIdx(idx uint64) error {
+	return ps.saveCursor(latestDepExitIdxKey, idx)
+}
+
+func (ps *SQLStorage) SaveLastSubmittedBlock(num uint64) error {
+	return ps.saveCursor(lastSubmittedBlockKey, num)
+}
+
+func (ps *SQLStorage) LastSubmittedBlock() (uint64, error) {
+	return ps.cursor(lastSubmittedBlockKey)
+}
+
+func (ps *SQLStorage) MarkExitsAsSpent([]chain.Input) error {
+	return nil
+}
+
+func (ps *SQLStorage) IsDoubleSpent(confirmed *chain.ConfirmedTransaction) (bool, error) {
+	tx := &confirmed.Transaction
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if i > 0 && input.IsZeroInput() {
+			continue
+		}
+
+		var count int
+		err := ps.db.QueryRow(
+			"SELECT COUNT(*) FROM spends WHERE block_number = ? AND tx_idx = ? AND out_idx = ?",
+			input.BlkNum,
+			input.TxIdx,
+			input.OutIdx,
+		).Scan(&count)
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (ps *SQLStorage) FindDoubleSpendingTransaction(blkNum uint64, txIdx uint32, outIndex uint8) (*chain.ConfirmedTransaction, error) {
+	var spendingBlkNum uint64
+	var spendingTxIdx uint32
+	err := ps.db.QueryRow(
+		"SELECT spending_block_number, spending_tx_idx FROM spends WHERE block_number = ? AND tx_idx = ? AND out_idx = ? ORDER BY is_exit LIMIT 1",
+		blkNum,
+		txIdx,
+		outIndex,
+	).Scan(&spendingBlkNum, &spendingTxIdx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return findSQLTransaction(ps.db, spendingBlkNum, spendingTxIdx)
+}
+
+func (ps *SQLStorage) cursor(name string) (uint64, error) {
+	var value uint64
+	err := ps.db.QueryRow("SELECT value FROM cursors WHERE name = ?", name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return value, err
+}
+
+func (ps *SQLStorage) saveCursor(name string, value uint64) error {
+	_, err := ps.db.Exec("INSERT OR REPLACE INTO cursors (name, value) VALUES (?, ?)", name, value)
+	return err
+}
+
+func findSQLTransaction(q sqlQuerier, blkNum uint64, txIdx uint32) (*chain.ConfirmedTransaction, error) {
+	var data []byte
+	err := q.QueryRow("SELECT rlp FROM transactions WHERE block_number = ? AND tx_idx = ?", blkNum, txIdx).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tx chain.ConfirmedTransaction
+	if err := rlp.DecodeBytes(data, &tx); err != nil {
+		return nil, err
+	}
+	tx.Transaction.BlkNum = blkNum
+	tx.Transaction.TxIdx = txIdx
+	return &tx, nil
+}
+
+func scanSQLTransactions(rows *sql.Rows) ([]chain.ConfirmedTransaction, error) {
+	defer rows.Close()
+
+	var txs []chain.ConfirmedTransaction
+	for rows.Next() {
+		var blkNum uint64
+		var txIdx uint32
+		var data []byte
+		if err := rows.Scan(&blkNum, &txIdx, &data); err != nil {
+			return nil, err
+		}
+
+		var tx chain.ConfirmedTransaction
+		if err := rlp.DecodeBytes(data, &tx); err != nil {
+			return nil, err
+		}
+		// RLP encoding for tranctions doesn't contain TxIdx or BlkNum
+		tx.Transaction.BlkNum = blkNum
+		tx.Transaction.TxIdx = txIdx
+		txs = append(txs, tx)
+	}
+
+	return txs, rows.Err()
+}
+
+func scanSQLOutputs(rows *sql.Rows) ([]OwnedOutput, error) {
+	defer rows.Close()
+
+	var outputs []OwnedOutput
+	for rows.Next() {
+		var blkNum uint64
+		var txIdx uint32
+		var data []byte
+		var outIdx uint8
+		if err := rows.Scan(&blkNum, &txIdx, &data, &outIdx); err != nil {
+			return nil, err
+		}
+
+		var tx chain.ConfirmedTransaction
+		if err := rlp.DecodeBytes(data, &tx); err != nil {
+			return nil, err
+		}
+		tx.Transaction.BlkNum = blkNum
+		tx.Transaction.TxIdx = txIdx
+		outputs = append(outputs, OwnedOutput{Transaction: tx, OutIdx: outIdx})
+	}
+
+	return outputs, rows.Err()
+}
+
+func scanSQLBlock(row *sql.Row) (*chain.Block, error) {
+	var header chain.BlockHeader
+	var hash, merkleRoot, prevHash []byte
+	if err := row.Scan(&header.Number, &hash, &merkleRoot, &prevHash); err != nil {
+		return nil, err
+	}
+
+	header.MerkleRoot = merkleRoot
+	header.PrevHash = prevHash
+	return &chain.Block{
+		Header:    &header,
+		BlockHash: hash,
+	}, nil
+}
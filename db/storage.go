@@ -14,6 +14,7 @@ import (
 	"github.com/kyokan/plasma/util"
 	"github.com/pkg/errors"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
 	levelutil "github.com/syndtr/goleveldb/leveldb/util"
 	"time"
 )
@@ -27,21 +28,34 @@ type BlockResult struct {
 	BlockNumber        *big.Int
 }
 
+// OwnedOutput is output OutIdx of Transaction. A transaction can pay the
+// same address more than once, so outputs are listed individually rather
+// than by transaction.
+type OwnedOutput struct {
+	Transaction chain.ConfirmedTransaction
+	OutIdx      uint8
+}
+
+func (o *OwnedOutput) Output() *chain.Output {
+	return o.Transaction.Transaction.OutputAt(o.OutIdx)
+}
+
 type PlasmaStorage interface {
 	ProcessDeposit(tx chain.ConfirmedTransaction) (deposit *BlockResult, err error)
 	FindTransactionsByBlockNum(blkNum uint64) ([]chain.ConfirmedTransaction, error)
 	FindTransactionByBlockNumTxIdx(blkNum uint64, txIdx uint32) (*chain.ConfirmedTransaction, error)
 
 	Balance(addr *common.Address) (*big.Int, error)
-	SpendableTxs(addr *common.Address) ([]chain.ConfirmedTransaction, error)
-	UTXOs(addr *common.Address) ([]chain.ConfirmedTransaction, error)
+	SpendableOutputs(addr *common.Address) ([]OwnedOutput, error)
+	Outputs(addr *common.Address) ([]OwnedOutput, error)
 
 	BlockAtHeight(num uint64) (*chain.Block, error)
 	BlockMetaAtHeight(num uint64) (*chain.BlockMetadata, error)
 	LatestBlock() (*chain.Block, error)
 	PackageBlock(txs []chain.ConfirmedTransaction) (result *BlockResult, err error)
-	ConfirmTransaction(blockNumber uint64, transactionIndex uint32, sigs [2]chain.Signature) (*chain.ConfirmedTransaction, error)
-	AuthSigsFor(blockNumber uint64, transactionIndex uint32) ([2]chain.Signature, error)
+	ConfirmTransaction(blockNumber uint64, transactionIndex uint32, sigs []chain.Signature) (*chain.ConfirmedTransaction, error)
+	AuthSigsFor(blockNumber uint64, transactionIndex uint32) ([]chain.Signature, error)
+	HasAuthSigs(blockNumber uint64, transactionIndex uint32) (bool, error)
 
 	LastDepositPoll() (uint64, error)
 	SaveDepositPoll(idx uint64) error
@@ -63,7 +77,8 @@ type PlasmaStorage interface {
 }
 
 type Storage struct {
-	db *leveldb.DB
+	db      *leveldb.DB
+	archive *Archive
 }
 
 func NewStorage(db *leveldb.DB) PlasmaStorage {
@@ -74,31 +89,31 @@ func NewStorage(db *leveldb.DB) PlasmaStorage {
 	return &result
 }
 
-func (ps *Storage) Put(key, value []byte) {
-	ps.db.Put(key, value, nil)
-}
-
-func (ps *Storage) Delete(key []byte) {
-	ps.db.Delete(key, nil)
-}
-
 func (ps *Storage) findPreviousTx(tx *chain.ConfirmedTransaction, inputIdx uint8) (*chain.ConfirmedTransaction, util.Hash, error) {
-	var input *chain.Input
-
-	if inputIdx != 0 && inputIdx != 1 {
-		panic("inputIdx must be 0 or 1")
-	}
+	input := tx.Transaction.InputAt(inputIdx)
+	return ps.findTransactionByBlockNumTxIdx(input.BlkNum, input.TxIdx)
+}
 
-	if inputIdx == 0 {
-		input = tx.Transaction.Input0
-	} else {
-		input = tx.Transaction.Input1
+// findPendingPreviousTx is like findPreviousTx, but also finds outputs created
+// earlier in the block being packaged, which are not on disk yet.
+func (ps *Storage) findPendingPreviousTx(tx *chain.ConfirmedTransaction, inputIdx uint8, pending []chain.ConfirmedTransaction) (*chain.ConfirmedTransaction, error) {
+	input := tx.Transaction.InputAt(inputIdx)
+	if input.BlkNum == tx.Transaction.BlkNum {
+		if int(input.TxIdx) >= len(pending) {
+			return nil, errors.New("input spends a transaction that is not in the block yet")
+		}
+		return &pending[input.TxIdx], nil
 	}
 
-	return ps.findTransactionByBlockNumTxIdx(input.BlkNum, input.TxIdx)
+	prevTx, _, err := ps.findPreviousTx(tx, inputIdx)
+	return prevTx, err
 }
 
-func (ps *Storage) saveTransaction(blkNum uint64, txIdx uint32, confirmed chain.ConfirmedTransaction, batch *leveldb.Batch) (*chain.ConfirmedTransaction, error) {
+// saveTransaction adds confirmed and its indexes to batch. pending holds the
+// transactions already added to batch for the same block, and
+// spent holds the spend keys already added to batch, so that two spends of
+// the same output in one block are rejected like they are by SQLStorage.
+func (ps *Storage) saveTransaction(blkNum uint64, txIdx uint32, confirmed chain.ConfirmedTransaction, pending []chain.ConfirmedTransaction, spent map[string]bool, batch *leveldb.Batch) (*chain.ConfirmedTransaction, error) {
 	confirmed.Transaction.TxIdx = txIdx
 	confirmed.Transaction.BlkNum = blkNum
 
@@ -118,59 +133,55 @@ func (ps *Storage) saveTransaction(blkNum uint64, txIdx uint32, confirmed chain.
 	var empty []byte
 
 	// Recording spends
-	if !confirmed.Transaction.Input0.IsZeroInput() {
+	tx := &confirmed.Transaction
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if input.IsZeroInput() {
+			continue
+		}
 		outpointIdent := &chain.SpendIdentifier{
-			BlockNumber: blkNum,
-			TransactionIndex:txIdx,
-			InputIndex: 1,
+			BlockNumber:      blkNum,
+			TransactionIndex: txIdx,
+			InputIndex:       i,
 		}
 		identBytes, _ := outpointIdent.MarshalBinary()
 
-		prevTx0, _, err := ps.findPreviousTx(&confirmed, 0)
+		prevTx, err := ps.findPendingPreviousTx(&confirmed, i, pending)
 		if err != nil {
 			return nil, err
 		}
 
-		input := confirmed.Transaction.Input0
-		prevOutput := prevTx0.Transaction.OutputAt(input.OutIdx)
-		outputOwner := prevOutput.Owner
-		if confirmed.Transaction.Output0.IsExit() {
-			batch.Put(spendExit(&outputOwner, confirmed.Transaction.Input0), identBytes)
+		outputOwner := prevTx.Transaction.OutputAt(input.OutIdx).Owner
+		var spendKey []byte
+		if i == 0 && tx.Output0.IsExit() {
+			spendKey = spendExit(&outputOwner, input)
 		} else {
-			batch.Put(spend(&outputOwner, confirmed.Transaction.Input0), identBytes)
-		}
-	}
-	if !confirmed.Transaction.Input1.IsZeroInput() {
-		outpointIdent := &chain.SpendIdentifier{
-			BlockNumber: blkNum,
-			TransactionIndex:txIdx,
-			InputIndex: 1,
+			spendKey = spend(&outputOwner, input)
 		}
-		identBytes, _ := outpointIdent.MarshalBinary()
-
-		prevTx1, _, err := ps.findPreviousTx(&confirmed, 1)
+		found, err := ps.db.Has(spendKey, nil)
 		if err != nil {
 			return nil, err
 		}
-		input := confirmed.Transaction.InputAt(1)
-		outputOwner := prevTx1.Transaction.OutputAt(input.OutIdx).Owner
-		batch.Put(spend(&outputOwner, confirmed.Transaction.Input1), identBytes)
+		if found || spent[string(spendKey)] {
+			return nil, conflictingSpend(txIdx, i)
+		}
+		spent[string(spendKey)] = true
+		batch.Put(spendKey, identBytes)
 	}
 
 	// Recording earns
-	if !confirmed.Transaction.Output0.IsZeroOutput() {
-		if confirmed.Transaction.Output0.IsDeposit() { // Only first output can be a deposit
+	for i := uint8(0); i < tx.NumOutputs(); i++ {
+		output := tx.OutputAt(i)
+		if output.IsZeroOutput() {
+			continue
+		}
+		if i == 0 && output.IsDeposit() { // Only first output can be a deposit
 			batch.Put(depositKey(&confirmed), txEnc)
 		}
-		output := confirmed.Transaction.OutputAt(0)
-		batch.Put(earn(&output.Owner, confirmed, 0), empty)
-	}
-	if !confirmed.Transaction.Output1.IsZeroOutput() {
-		output := confirmed.Transaction.OutputAt(1)
-		batch.Put(earn(&output.Owner, confirmed, 1), empty)
+		batch.Put(earn(&output.Owner, confirmed, i), empty)
 	}
 
-	return &confirmed, batch.Replay(ps)
+	return &confirmed, nil
 }
 
 func (ps *Storage) MarkExitsAsSpent(inputs []chain.Input) error {
@@ -207,23 +218,23 @@ func (ps *Storage) MarkExitsAsSpent(inputs []chain.Input) error {
 }
 
 func (ps *Storage) IsDoubleSpent(confirmed *chain.ConfirmedTransaction) (bool, error) {
-	tx := confirmed.Transaction
-	prevTx0, _, err := ps.findPreviousTx(confirmed, 0)
-	if err != nil {
-		return false, err
-	}
+	tx := &confirmed.Transaction
 	spendKeys := make([][]byte, 0)
-	addr0 := prevTx0.Transaction.OutputAt(tx.Input0.OutIdx).Owner
-	spendKeys = append(spendKeys, spend(&addr0, tx.Input0))
-	spendKeys = append(spendKeys, spendExit(&addr0, tx.Input0))
-	if !tx.Input1.IsZeroInput() {
-		prevTx1, _, err := ps.findPreviousTx(confirmed, 1)
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if i > 0 && input.IsZeroInput() {
+			continue
+		}
+		prevTx, _, err := ps.findPreviousTx(confirmed, i)
 		if err != nil {
 			return false, err
 		}
-		addr1 := prevTx1.Transaction.OutputAt(tx.Input1.OutIdx).Owner
-		spendKeys = append(spendKeys, spend(&addr1, tx.Input1))
-		spendKeys = append(spendKeys, spendExit(&addr1, tx.Input1))
+		if prevTx == nil {
+			return false, fmt.Errorf("input %d not found", i)
+		}
+		addr := prevTx.Transaction.OutputAt(input.OutIdx).Owner
+		spendKeys = append(spendKeys, spend(&addr, input))
+		spendKeys = append(spendKeys, spendExit(&addr, input))
 	}
 
 	for _, spendKey := range spendKeys {
@@ -241,6 +252,9 @@ func (ps *Storage) FindDoubleSpendingTransaction(blkNum uint64, txIdx uint32, ou
 	if err != nil {
 		return nil, err
 	}
+	if confirmed == nil {
+		return nil, &NotFoundError{What: fmt.Sprintf("transaction %d:%d", blkNum, txIdx)}
+	}
 
 	tx := confirmed.Transaction
 	spendKeys := make([][]byte, 0)
@@ -284,44 +298,41 @@ func (ps *Storage) doPackageBlock(txs []chain.ConfirmedTransaction) (*BlockResul
 		return nil, err
 	}
 
-	var blkNum uint64
-	var prevHash util.Hash
-	if prevBlock == nil {
-		blkNum = 1
-	} else {
-		blkNum = prevBlock.Header.Number + 1
-		prevHash = prevBlock.BlockHash
-	}
-
 	// The batch will act as in-memory buffer
 	batch := new(leveldb.Batch)
-	numberOfTransactions := len(txs)
-
-	hashables := make([]util.RLPHashable, numberOfTransactions)
-	for i, tx := range txs {
-		hashables[i] = &tx
+	_, result, err := ps.addBlock(prevBlock, txs, uint64(time.Now().Unix()), batch)
+	if err != nil {
+		return nil, err
 	}
-	merkleRoot := merkle.Root(hashables)
 
-	header := chain.BlockHeader{
-		MerkleRoot: merkleRoot,
-		PrevHash:   prevHash,
-		Number:     blkNum,
-	}
-	block := chain.Block{
-		Header:    &header,
-		BlockHash: header.Hash(),
+	// Everything about the block, including the latest block pointer, lands
+	// on disk in this single write or not at all.
+	err = ps.db.Write(batch, &opt.WriteOptions{Sync: true})
+	if err != nil {
+		return nil, err
 	}
 
+	return result, nil
+}
+
+// addBlock adds the block following prevBlock, its transactions, indexes,
+// fees and metadata to batch.
+func (ps *Storage) addBlock(prevBlock *chain.Block, txs []chain.ConfirmedTransaction, createdAt uint64, batch *leveldb.Batch) (*chain.Block, *BlockResult, error) {
+	numberOfTransactions := len(txs)
+
+	block := nextBlock(prevBlock, txs)
+	blkNum := block.Header.Number
+	merkleRoot := block.Header.MerkleRoot
+
 	enc, err := rlp.EncodeToBytes(merkleRoot)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	batch.Put(merklePrefixKey(hexutil.Encode(merkleRoot)), enc)
 
 	enc, err = rlp.EncodeToBytes(block)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	key := blockPrefixKey(hexutil.Encode(block.BlockHash))
 	batch.Put(key, enc)
@@ -329,33 +340,31 @@ func (ps *Storage) doPackageBlock(txs []chain.ConfirmedTransaction) (*BlockResul
 	batch.Put(blockNumKey(block.Header.Number), key)
 
 	currentFees := big.NewInt(0)
+	saved := make([]chain.ConfirmedTransaction, 0, numberOfTransactions)
+	spent := make(map[string]bool)
 	for i, tx := range txs {
-		_, err := ps.saveTransaction(blkNum, uint32(i), tx, batch)
+		confirmed, err := ps.saveTransaction(blkNum, uint32(i), tx, saved, spent, batch)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		saved = append(saved, *confirmed)
 
 		currentFees = currentFees.Add(currentFees, tx.Transaction.Fee)
 	}
 	batch.Put(blockFeesKey(blkNum), currentFees.Bytes())
 
 	meta := &chain.BlockMetadata{
-		CreatedAt:        uint64(time.Now().Unix()),
+		CreatedAt:        createdAt,
 		TransactionCount: uint32(numberOfTransactions),
 		Fees:             currentFees,
 	}
 	metaEnc, err := meta.RLP()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	batch.Put(blockMetaPrefixKey(block.Header.Number), metaEnc)
 
-	err = ps.db.Write(batch, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return &BlockResult{
+	return block, &BlockResult{
 		MerkleRoot:         merkleRoot,
 		NumberTransactions: uint32(numberOfTransactions),
 		BlockFees:          currentFees,
@@ -363,6 +372,35 @@ func (ps *Storage) doPackageBlock(txs []chain.ConfirmedTransaction) (*BlockResul
 	}, nil
 }
 
+// nextBlock builds the block that follows prevBlock, which is nil for the
+// first block, out of txs.
+func nextBlock(prevBlock *chain.Block, txs []chain.ConfirmedTransaction) *chain.Block {
+	var blkNum uint64
+	var prevHash util.Hash
+	if prevBlock == nil {
+		blkNum = 1
+	} else {
+		blkNum = prevBlock.Header.Number + 1
+		prevHash = prevBlock.BlockHash
+	}
+
+	hashables := make([]util.RLPHashable, len(txs))
+	for i := range txs {
+		hashables[i] = &txs[i]
+	}
+	merkleRoot := merkle.Root(hashables)
+
+	header := chain.BlockHeader{
+		MerkleRoot: merkleRoot,
+		PrevHash:   prevHash,
+		Number:     blkNum,
+	}
+	return &chain.Block{
+		Header:    &header,
+		BlockHash: header.Hash(),
+	}
+}
+
 func (ps *Storage) ProcessDeposit(confirmed chain.ConfirmedTransaction) (deposit *BlockResult, err error) {
 	if !confirmed.Transaction.IsDeposit() {
 		return nil, errors.New("only deposit blocks are accepted")
@@ -372,6 +410,17 @@ func (ps *Storage) ProcessDeposit(confirmed chain.ConfirmedTransaction) (deposit
 }
 
 func (ps *Storage) FindTransactionsByBlockNum(blkNum uint64) ([]chain.ConfirmedTransaction, error) {
+	if err := checkHeight(blkNum); err != nil {
+		return nil, err
+	}
+
+	rec, err := ps.archivedBlock(ps.db, blkNum)
+	if err != nil {
+		return nil, err
+	}
+	if rec != nil {
+		return archivedTransactions(rec, blkNum)
+	}
 
 	// Construct partial prefix that matches all transactions for the block
 	prefix := txPrefixKey("blkNum", strconv.FormatUint(blkNum, 10), "txIdx")
@@ -408,6 +457,9 @@ func findBlockTransactions(iter iterator.Iterator, prefix []byte, blkNum uint64)
 
 	txs := make([]chain.ConfirmedTransaction, len(buffer))
 	for _, tx := range buffer {
+		if int(tx.Transaction.TxIdx) >= len(txs) {
+			return nil, &PrunedError{What: fmt.Sprintf("block %d is incomplete", blkNum)}
+		}
 		txs[tx.Transaction.TxIdx] = tx
 	}
 
@@ -460,7 +512,11 @@ func (ps *Storage) findTransactionByBlockNumTxIdx(blkNum uint64, txIdx uint32) (
 		return nil, nil, err
 	}
 	if !exists {
-		return nil, nil, nil
+		tx, err := ps.archivedTransaction(blkNum, txIdx)
+		if err != nil || tx == nil {
+			return nil, nil, err
+		}
+		return tx, block.BlockHash, nil
 	}
 	data, err = ps.db.Get(key, nil)
 	if err != nil {
@@ -478,6 +534,22 @@ func (ps *Storage) findTransactionByBlockNumTxIdx(blkNum uint64, txIdx uint32) (
 	return &tx, block.BlockHash, nil
 }
 
+// archivedTransaction reads a transaction of a pruned block from the archive.
+// It returns nil if the block hasn't been pruned or has no such transaction.
+func (ps *Storage) archivedTransaction(blkNum uint64, txIdx uint32) (*chain.ConfirmedTransaction, error) {
+	rec, err := ps.archivedBlock(ps.db, blkNum)
+	if err != nil || rec == nil || int(txIdx) >= len(rec.Transactions) {
+		return nil, err
+	}
+	var tx chain.ConfirmedTransaction
+	if err := rlp.DecodeBytes(rec.Transactions[txIdx], &tx); err != nil {
+		return nil, err
+	}
+	tx.Transaction.BlkNum = blkNum
+	tx.Transaction.TxIdx = txIdx
+	return &tx, nil
+}
+
 func (ps *Storage) FindTransactionByBlockNumTxIdx(blkNum uint64, txIdx uint32) (*chain.ConfirmedTransaction, error) {
 	tx, _, err := ps.findTransactionByBlockNumTxIdx(blkNum, txIdx)
 	return tx, err
@@ -485,7 +557,7 @@ func (ps *Storage) FindTransactionByBlockNumTxIdx(blkNum uint64, txIdx uint32) (
 
 // Address
 func (ps *Storage) Balance(addr *common.Address) (*big.Int, error) {
-	txs, err := ps.SpendableTxs(addr)
+	outputs, err := ps.SpendableOutputs(addr)
 
 	if err != nil {
 		return nil, err
@@ -493,76 +565,65 @@ func (ps *Storage) Balance(addr *common.Address) (*big.Int, error) {
 
 	total := big.NewInt(0)
 
-	for _, confirmed := range txs {
-		total = total.Add(total, extractAmount(&confirmed.Transaction, addr))
+	for _, owned := range outputs {
+		total = total.Add(total, owned.Output().Denom)
 	}
 
 	return total, nil
 }
 
-func (ps *Storage) SpendableTxs(addr *common.Address) ([]chain.ConfirmedTransaction, error) {
-	earnPrefix := earnPrefixKey(addr)
-	spendPrefix := spendPrefixKey(addr)
-
-	earnMap := make(map[string]uint8)
-	spendMap := make(map[string]uint8)
-
-	earnIter := ps.db.NewIterator(levelutil.BytesPrefix(earnPrefix), nil)
-	defer earnIter.Release()
-
-	for earnIter.Next() {
-		earnKey := earnIter.Key()
-		lookupKey := string(earnKey[len(earnKeyPrefix)+len(keyPartsSeparator):])
-		earnMap[lookupKey] = 1
+func (ps *Storage) SpendableOutputs(addr *common.Address) ([]OwnedOutput, error) {
+	earnKeys, err := ps.earnKeysFor(addr)
+	if err != nil {
+		return nil, err
 	}
 
-	spendIter := ps.db.NewIterator(levelutil.BytesPrefix(spendPrefix), nil)
+	spendIter := ps.db.NewIterator(levelutil.BytesPrefix(spendPrefixKey(addr)), nil)
 	defer spendIter.Release()
 
 	for spendIter.Next() {
-		spendKey := spendIter.Key()
-		lookupKey := string(spendKey[len(spendKeyPrefix)+len(keyPartsSeparator) : len(spendKey)-3])
-		spendMap[lookupKey] = 1
+		// spend keys end with the deposit nonce, which earn keys don't have
+		spendKey := string(spendIter.Key()[len(spendKeyPrefix)+len(keyPartsSeparator):])
+		lookupKey := spendKey[:strings.LastIndex(spendKey, keyPartsSeparator)]
+		delete(earnKeys, lookupKey)
 	}
-
-	for k, _ := range spendMap {
-		delete(earnMap, k)
+	if err := spendIter.Error(); err != nil {
+		return nil, err
 	}
 
-	var ret []chain.ConfirmedTransaction
-	for key := range earnMap {
-		_, blkNum, txIdx, _, err := parseSuffix([]byte(key))
-		if err != nil {
-			return nil, err
-		}
-		tx, _, err := ps.findTransactionByBlockNumTxIdx(blkNum.Uint64(), uint32(txIdx.Uint64()))
-
-		if err != nil {
-			return nil, err
-		}
+	return ps.ownedOutputs(earnKeys)
+}
 
-		ret = append(ret, *tx)
+// Outputs returns every output addr owns or has owned. On a pruned database
+// the outputs of pruned transactions are left out, since their earn keys are
+// gone.
+func (ps *Storage) Outputs(addr *common.Address) ([]OwnedOutput, error) {
+	earnKeys, err := ps.earnKeysFor(addr)
+	if err != nil {
+		return nil, err
 	}
-	sortTransactions(ret)
-	return ret, nil
+	return ps.ownedOutputs(earnKeys)
 }
 
-func (ps *Storage) UTXOs(addr *common.Address) ([]chain.ConfirmedTransaction, error) {
-	earnPrefix := earnPrefixKey(addr)
-	earnMap := make(map[string]uint8)
+// earnKeysFor returns the suffixes of addr's earn keys, one per output addr
+// has ever owned.
+func (ps *Storage) earnKeysFor(addr *common.Address) (map[string]bool, error) {
+	earnKeys := make(map[string]bool)
 
-	earnIter := ps.db.NewIterator(levelutil.BytesPrefix(earnPrefix), nil)
+	earnIter := ps.db.NewIterator(levelutil.BytesPrefix(earnPrefixKey(addr)), nil)
 	defer earnIter.Release()
 
 	for earnIter.Next() {
 		earnKey := earnIter.Key()
-		lookupKey := string(earnKey[len(earnKeyPrefix)+len(keyPartsSeparator):])
-		earnMap[lookupKey] = 1
+		earnKeys[string(earnKey[len(earnKeyPrefix)+len(keyPartsSeparator):])] = true
 	}
+	return earnKeys, earnIter.Error()
+}
 
-	var ret []chain.ConfirmedTransaction
-	for key := range earnMap {
-		_, blkNum, txIdx, _, err := parseSuffix([]byte(key))
+func (ps *Storage) ownedOutputs(earnKeys map[string]bool) ([]OwnedOutput, error) {
+	var ret []OwnedOutput
+	for key := range earnKeys {
+		_, blkNum, txIdx, outIdx, err := parseSuffix([]byte(key))
 		if err != nil {
 			return nil, err
 		}
@@ -572,13 +633,19 @@ func (ps *Storage) UTXOs(addr *common.Address) ([]chain.ConfirmedTransaction, er
 			return nil, err
 		}
 
-		ret = append(ret, *tx)
+		ret = append(ret, OwnedOutput{
+			Transaction: *tx,
+			OutIdx:      uint8(outIdx.Uint64()),
+		})
 	}
-	sortTransactions(ret)
+	sortOutputs(ret)
 	return ret, nil
 }
 
-func (ps *Storage) ConfirmTransaction(blockNumber uint64, transactionIndex uint32, sigs [2]chain.Signature) (*chain.ConfirmedTransaction, error) {
+// ConfirmTransaction stores one confirmation signature per input. Legacy
+// transactions always have two, so they are stored in the same format as
+// before multi transactions existed.
+func (ps *Storage) ConfirmTransaction(blockNumber uint64, transactionIndex uint32, sigs []chain.Signature) (*chain.ConfirmedTransaction, error) {
 	tx, _, err := ps.findTransactionByBlockNumTxIdx(blockNumber, transactionIndex)
 	if err != nil {
 		return nil, err
@@ -601,34 +668,66 @@ func (ps *Storage) ConfirmTransaction(blockNumber uint64, transactionIndex uint3
 	return tx, nil
 }
 
-func (ps *Storage) AuthSigsFor(blockNumber uint64, transactionIndex uint32) ([2]chain.Signature, error) {
-	var sigs [2]chain.Signature
+func (ps *Storage) AuthSigsFor(blockNumber uint64, transactionIndex uint32) ([]chain.Signature, error) {
+	var sigs []chain.Signature
 	key := blkNumTxIdxAuthSigKey(blockNumber, transactionIndex)
 	has, err := ps.db.Has(key, nil)
 	if err != nil {
 		return sigs, err
 	}
-	if !has {
-		return sigs, errors.New("no auth sigs found")
+	var rawSigs []byte
+	if has {
+		rawSigs, err = ps.db.Get(key, nil)
+	} else {
+		rawSigs, err = ps.archivedAuthSigs(blockNumber, transactionIndex)
 	}
-	rawSigs, err := ps.db.Get(key, nil)
 	if err != nil {
 		return sigs, err
 	}
+	if rawSigs == nil {
+		return sigs, &NotFoundError{What: "confirmation signatures"}
+	}
 
 	err = rlp.DecodeBytes(rawSigs, &sigs)
 	return sigs, err
 }
 
+func (ps *Storage) HasAuthSigs(blockNumber uint64, transactionIndex uint32) (bool, error) {
+	has, err := ps.db.Has(blkNumTxIdxAuthSigKey(blockNumber, transactionIndex), nil)
+	if err != nil || has {
+		return has, err
+	}
+	rawSigs, err := ps.archivedAuthSigs(blockNumber, transactionIndex)
+	return rawSigs != nil, err
+}
+
+// archivedAuthSigs returns the encoded auth sigs of a pruned transaction, or
+// nil if it has none.
+func (ps *Storage) archivedAuthSigs(blockNumber uint64, transactionIndex uint32) ([]byte, error) {
+	rec, err := ps.archivedBlock(ps.db, blockNumber)
+	if err != nil || rec == nil {
+		return nil, err
+	}
+	for _, authSig := range rec.AuthSigs {
+		if authSig.TxIdx == transactionIndex {
+			return authSig.Sigs, nil
+		}
+	}
+	return nil, nil
+}
+
 // Block
 func (ps *Storage) BlockAtHeight(num uint64) (*chain.Block, error) {
+	if err := checkHeight(num); err != nil {
+		return nil, err
+	}
 	key, err := ps.db.Get(blockNumKey(num), nil)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, fmt.Sprintf("block %d", num))
 	}
 	data, err := ps.db.Get(key, nil)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, fmt.Sprintf("block %d", num))
 	}
 
 	var blk chain.Block
@@ -642,9 +741,12 @@ func (ps *Storage) BlockAtHeight(num uint64) (*chain.Block, error) {
 }
 
 func (ps *Storage) BlockMetaAtHeight(num uint64) (*chain.BlockMetadata, error) {
+	if err := checkHeight(num); err != nil {
+		return nil, err
+	}
 	data, err := ps.db.Get(blockMetaPrefixKey(num), nil)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, fmt.Sprintf("block %d", num))
 	}
 
 	var meta chain.BlockMetadata
@@ -0,0 +1,302 @@
+package db
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/kyokan/plasma/chain"
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+)
+
+var (
+	alice = common.HexToAddress("0x627306090abab3a6e1400e9345bc60c78a8bef57")
+	bob   = common.HexToAddress("0xf17f52151ebef6c7334fad080c5704d77216b732")
+	carol = common.HexToAddress("0xc5fdf4076b8f3a5357c5e395ab970b5b54098fef")
+)
+
+// forEachBackend runs test against a new, empty store of every backend, so
+// that both are held to the same behavior.
+func forEachBackend(t *testing.T, test func(t *testing.T, storage PlasmaStorage)) {
+	backends := []string{BackendLevelDB, BackendSQLite}
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "plasma-db")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			closer, storage, err := CreateStorageWithBackend(backend, dir)
+			require.NoError(t, err)
+			defer closer.Close()
+
+			test(t, storage)
+		})
+	}
+}
+
+// newLevelStorage returns an empty LevelDB store kept in memory, for tests
+// that work with the raw keys.
+func newLevelStorage(t *testing.T) (*leveldb.DB, *Storage) {
+	level, err := leveldb.Open(storage.NewMemStorage(), nil)
+	require.NoError(t, err)
+	return level, &Storage{db: level}
+}
+
+func testDeposit(owner common.Address, amount int64, nonce int64) chain.ConfirmedTransaction {
+	tx := chain.ZeroTransaction()
+	tx.Output0 = chain.NewOutput(owner, big.NewInt(amount), big.NewInt(nonce))
+	return chain.ConfirmedTransaction{Transaction: *tx}
+}
+
+// testSpend spends output outIdx of the transaction at blkNum and txIdx into
+// the given outputs.
+func testSpend(blkNum uint64, txIdx uint32, outIdx uint8, outputs ...*chain.Output) chain.ConfirmedTransaction {
+	tx := chain.ZeroTransaction()
+	tx.Input0 = chain.NewInput(blkNum, txIdx, outIdx, chain.Zero(), common.Address{})
+	tx.Output0 = outputs[0]
+	if len(outputs) > 1 {
+		tx.Output1 = outputs[1]
+	}
+	return chain.ConfirmedTransaction{Transaction: *tx}
+}
+
+func testOutput(owner common.Address, amount int64) *chain.Output {
+	return chain.NewOutput(owner, big.NewInt(amount), big.NewInt(0))
+}
+
+func requireBalance(t *testing.T, storage PlasmaStorage, addr common.Address, expected int64) {
+	balance, err := storage.Balance(&addr)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(expected).String(), balance.String(), "balance of %s", addr.Hex())
+}
+
+// seedStorage creates deposits of 100 to alice in block 1 and of 50 to bob in
+// block 2, then has alice send 60 to bob in block 3.
+func seedStorage(t *testing.T, storage PlasmaStorage) *BlockResult {
+	_, err := storage.ProcessDeposit(testDeposit(alice, 100, 1))
+	require.NoError(t, err)
+	_, err = storage.ProcessDeposit(testDeposit(bob, 50, 2))
+	require.NoError(t, err)
+	result, err := storage.PackageBlock([]chain.ConfirmedTransaction{
+		testSpend(1, 0, 0, testOutput(bob, 60), testOutput(alice, 40)),
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestStorageBlocks(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, storage PlasmaStorage) {
+		latest, err := storage.LatestBlock()
+		require.NoError(t, err)
+		require.Nil(t, latest)
+
+		result := seedStorage(t, storage)
+		require.Equal(t, uint64(3), result.BlockNumber.Uint64())
+		require.Equal(t, uint32(1), result.NumberTransactions)
+
+		latest, err = storage.LatestBlock()
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), latest.Header.Number)
+		require.Equal(t, result.MerkleRoot, latest.Header.MerkleRoot)
+
+		prev, err := storage.BlockAtHeight(2)
+		require.NoError(t, err)
+		require.Equal(t, prev.BlockHash, latest.Header.PrevHash)
+
+		meta, err := storage.BlockMetaAtHeight(3)
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), meta.TransactionCount)
+		require.Equal(t, int64(0), meta.Fees.Int64())
+
+		txs, err := storage.FindTransactionsByBlockNum(3)
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		require.Equal(t, uint64(3), txs[0].Transaction.BlkNum)
+		require.Equal(t, bob, txs[0].Transaction.Output0.Owner)
+
+		tx, err := storage.FindTransactionByBlockNumTxIdx(1, 0)
+		require.NoError(t, err)
+		require.Equal(t, alice, tx.Transaction.Output0.Owner)
+		require.Equal(t, int64(100), tx.Transaction.Output0.Denom.Int64())
+
+		_, err = storage.BlockAtHeight(4)
+		require.True(t, IsNotFound(err), "got %v", err)
+		_, err = storage.BlockMetaAtHeight(4)
+		require.True(t, IsNotFound(err), "got %v", err)
+
+		// blocks are numbered from 1, and height 0 still counts as not found
+		_, err = storage.BlockAtHeight(0)
+		require.True(t, IsInvalidHeight(err), "got %v", err)
+		require.True(t, IsNotFound(err))
+		_, err = storage.BlockMetaAtHeight(0)
+		require.True(t, IsInvalidHeight(err), "got %v", err)
+		_, err = storage.FindTransactionsByBlockNum(0)
+		require.True(t, IsInvalidHeight(err), "got %v", err)
+	})
+}
+
+func TestStorageBalances(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, storage PlasmaStorage) {
+		seedStorage(t, storage)
+
+		requireBalance(t, storage, alice, 40)
+		requireBalance(t, storage, bob, 110)
+		requireBalance(t, storage, carol, 0)
+
+		spendable, err := storage.SpendableOutputs(&alice)
+		require.NoError(t, err)
+		require.Len(t, spendable, 1)
+		require.Equal(t, uint64(3), spendable[0].Transaction.Transaction.BlkNum)
+		require.Equal(t, uint8(1), spendable[0].OutIdx)
+
+		outputs, err := storage.Outputs(&alice)
+		require.NoError(t, err)
+		require.Len(t, outputs, 2)
+		require.Equal(t, uint64(1), outputs[0].Transaction.Transaction.BlkNum)
+		require.Equal(t, uint64(3), outputs[1].Transaction.Transaction.BlkNum)
+	})
+}
+
+func TestStorageOutputsToTheSameOwner(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, storage PlasmaStorage) {
+		seedStorage(t, storage)
+
+		// bob splits the 60 alice sent him into two outputs he owns, then
+		// spends the second one
+		_, err := storage.PackageBlock([]chain.ConfirmedTransaction{
+			testSpend(3, 0, 0, testOutput(bob, 25), testOutput(bob, 35)),
+		})
+		require.NoError(t, err)
+		requireBalance(t, storage, bob, 110)
+
+		outputs, err := storage.Outputs(&bob)
+		require.NoError(t, err)
+		require.Len(t, outputs, 4)
+		require.Equal(t, uint64(4), outputs[2].Transaction.Transaction.BlkNum)
+		require.Equal(t, uint8(0), outputs[2].OutIdx)
+		require.Equal(t, int64(25), outputs[2].Output().Denom.Int64())
+		require.Equal(t, uint64(4), outputs[3].Transaction.Transaction.BlkNum)
+		require.Equal(t, uint8(1), outputs[3].OutIdx)
+		require.Equal(t, int64(35), outputs[3].Output().Denom.Int64())
+
+		_, err = storage.PackageBlock([]chain.ConfirmedTransaction{
+			testSpend(4, 0, 1, testOutput(carol, 35)),
+		})
+		require.NoError(t, err)
+		requireBalance(t, storage, bob, 75)
+		requireBalance(t, storage, carol, 35)
+
+		spendable, err := storage.SpendableOutputs(&bob)
+		require.NoError(t, err)
+		require.Len(t, spendable, 2)
+		require.Equal(t, uint64(2), spendable[0].Transaction.Transaction.BlkNum)
+		require.Equal(t, uint64(4), spendable[1].Transaction.Transaction.BlkNum)
+		require.Equal(t, uint8(0), spendable[1].OutIdx)
+	})
+}
+
+func TestStorageDoubleSpends(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, storage PlasmaStorage) {
+		seedStorage(t, storage)
+
+		again := testSpend(1, 0, 0, testOutput(carol, 100))
+		spent, err := storage.IsDoubleSpent(&again)
+		require.NoError(t, err)
+		require.True(t, spent)
+
+		unspent := testSpend(2, 0, 0, testOutput(carol, 50))
+		spent, err = storage.IsDoubleSpent(&unspent)
+		require.NoError(t, err)
+		require.False(t, spent)
+
+		spending, err := storage.FindDoubleSpendingTransaction(1, 0, 0)
+		require.NoError(t, err)
+		require.NotNil(t, spending)
+		require.Equal(t, uint64(3), spending.Transaction.BlkNum)
+		require.Equal(t, uint32(0), spending.Transaction.TxIdx)
+
+		spending, err = storage.FindDoubleSpendingTransaction(2, 0, 0)
+		require.NoError(t, err)
+		require.Nil(t, spending)
+	})
+}
+
+func TestStorageRejectsConflictingSpends(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, storage PlasmaStorage) {
+		seedStorage(t, storage)
+
+		// both spend bob's deposit
+		_, err := storage.PackageBlock([]chain.ConfirmedTransaction{
+			testSpend(2, 0, 0, testOutput(alice, 50)),
+			testSpend(2, 0, 0, testOutput(carol, 50)),
+		})
+		require.True(t, IsConflictingSpend(err), "got %v", err)
+		require.Equal(t, &ConflictingSpendError{TxIdx: 1, InputIdx: 0}, err)
+
+		// spends alice's deposit, which block 3 already spent
+		_, err = storage.PackageBlock([]chain.ConfirmedTransaction{
+			testSpend(1, 0, 0, testOutput(carol, 100)),
+		})
+		require.True(t, IsConflictingSpend(err), "got %v", err)
+
+		latest, err := storage.LatestBlock()
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), latest.Header.Number)
+		requireBalance(t, storage, alice, 40)
+		requireBalance(t, storage, bob, 110)
+		requireBalance(t, storage, carol, 0)
+	})
+}
+
+func TestStorageAuthSigs(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, storage PlasmaStorage) {
+		seedStorage(t, storage)
+
+		has, err := storage.HasAuthSigs(3, 0)
+		require.NoError(t, err)
+		require.False(t, has)
+		_, err = storage.AuthSigsFor(3, 0)
+		require.True(t, IsNotFound(err), "got %v", err)
+
+		sigs := []chain.Signature{chain.RandomConfirmationSig(), chain.RandomConfirmationSig()}
+		confirmed, err := storage.ConfirmTransaction(3, 0, sigs)
+		require.NoError(t, err)
+		require.Equal(t, bob, confirmed.Transaction.Output0.Owner)
+
+		has, err = storage.HasAuthSigs(3, 0)
+		require.NoError(t, err)
+		require.True(t, has)
+		stored, err := storage.AuthSigsFor(3, 0)
+		require.NoError(t, err)
+		require.Equal(t, sigs, stored)
+	})
+}
+
+func TestStorageCursors(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, storage PlasmaStorage) {
+		cursors := []struct {
+			save func(uint64) error
+			load func() (uint64, error)
+		}{
+			{storage.SaveDepositPoll, storage.LastDepositPoll},
+			{storage.SaveTxExitPoll, storage.LastTxExitPoll},
+			{storage.SaveDepositExitEventIdx, storage.LastDepositExitEventIdx},
+			{storage.SaveLastSubmittedBlock, storage.LastSubmittedBlock},
+		}
+
+		for i, cursor := range cursors {
+			value, err := cursor.load()
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), value)
+
+			require.NoError(t, cursor.save(uint64(i+10)))
+			value, err = cursor.load()
+			require.NoError(t, err)
+			require.Equal(t, uint64(i+10), value)
+		}
+	})
+}
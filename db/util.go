@@ -3,7 +3,6 @@ package db
 import (
 	"bytes"
 	"fmt"
-	"github.com/kyokan/plasma/chain"
 	"log"
 	"sort"
 	"strconv"
@@ -40,12 +39,16 @@ func bytesToUint64(b []byte) uint64 {
 	return uint64(n)
 }
 
-func sortTransactions(txs []chain.ConfirmedTransaction) {
-	txLess := func (lhs, rhs int) bool {
-		if txs[lhs].Transaction.BlkNum == txs[rhs].Transaction.BlkNum {
-			return txs[lhs].Transaction.TxIdx < txs[rhs].Transaction.TxIdx
+func sortOutputs(outputs []OwnedOutput) {
+	outputLess := func(lhs, rhs int) bool {
+		l, r := &outputs[lhs].Transaction.Transaction, &outputs[rhs].Transaction.Transaction
+		if l.BlkNum != r.BlkNum {
+			return l.BlkNum < r.BlkNum
 		}
-		return txs[lhs].Transaction.BlkNum < txs[rhs].Transaction.BlkNum
+		if l.TxIdx != r.TxIdx {
+			return l.TxIdx < r.TxIdx
+		}
+		return outputs[lhs].OutIdx < outputs[rhs].OutIdx
 	}
-	sort.Slice(txs, txLess)
+	sort.Slice(outputs, outputLess)
 }
@@ -0,0 +1,304 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kyokan/plasma/chain"
+	"github.com/syndtr/goleveldb/leveldb"
+	levelutil "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	CheckHeader      = "header"
+	CheckMerkleRoot  = "merkle_root"
+	CheckPrevHash    = "prev_hash"
+	CheckFees        = "fees"
+	CheckDoubleSpend = "double_spend"
+	CheckInputs      = "inputs"
+	CheckEarnIndex   = "earn_index"
+	CheckSpendIndex  = "spend_index"
+)
+
+type VerifyIssue struct {
+	Check   string `json:"check"`
+	Block   uint64 `json:"block,omitempty"`
+	Message string `json:"message"`
+}
+
+type VerifyReport struct {
+	LatestBlock  uint64         `json:"latestBlock"`
+	Blocks       uint64         `json:"blocks"`
+	Transactions uint64         `json:"transactions"`
+	IssueCounts  map[string]int `json:"issueCounts"`
+	Issues       []VerifyIssue  `json:"issues"`
+}
+
+func (r *VerifyReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+func (r *VerifyReport) addIssue(check string, blkNum uint64, format string, args ...interface{}) {
+	r.IssueCounts[check]++
+	r.Issues = append(r.Issues, VerifyIssue{
+		Check:   check,
+		Block:   blkNum,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+type outpoint struct {
+	blkNum uint64
+	txIdx  uint32
+	outIdx uint8
+}
+
+// Verify walks every block from a consistent snapshot of the database and
+// checks the chain's headers, merkle roots, fees, spends and indexes. Problems
+// with the data are collected in the report; the returned error is only set
+// if the database could not be read. On a pruned database pruned blocks are
+// read from the archive, and the earn keys of their fully spent transactions
+// may be missing.
+func (ps *Storage) Verify(progress func(blkNum uint64)) (*VerifyReport, error) {
+	snap, err := ps.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Release()
+
+	var lastPruned uint64
+	b, err := snap.Get(prefixKey(lastPrunedBlockKey), nil)
+	if err != nil && err != leveldb.ErrNotFound {
+		return nil, err
+	}
+	if err == nil {
+		lastPruned = bytesToUint64(b)
+	}
+
+	report := &VerifyReport{
+		IssueCounts: make(map[string]int),
+		Issues:      []VerifyIssue{},
+	}
+	report.LatestBlock, err = snapshotLatestBlockNum(snap)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[outpoint]common.Address)
+	spenders := make(map[outpoint]chain.SpendIdentifier)
+	earnKeys := make(map[string]bool)
+	spendKeys := make(map[string][]byte)
+	var pruned []chain.ConfirmedTransaction
+
+	var prevBlock *chain.Block
+	for blkNum := uint64(1); blkNum <= report.LatestBlock; blkNum++ {
+		rec, err := ps.blockRecordAt(snap, blkNum)
+		if err != nil {
+			report.addIssue(CheckHeader, blkNum, "failed to read block: %s", err)
+			prevBlock = nil
+			continue
+		}
+
+		var block chain.Block
+		if err := rlp.DecodeBytes(rec.Block, &block); err != nil {
+			return nil, err
+		}
+		var meta chain.BlockMetadata
+		if err := meta.FromRLP(rec.Meta); err != nil {
+			return nil, err
+		}
+		txs := make([]chain.ConfirmedTransaction, len(rec.Transactions))
+		for i, txEnc := range rec.Transactions {
+			if err := rlp.DecodeBytes(txEnc, &txs[i]); err != nil {
+				return nil, err
+			}
+			txs[i].Transaction.BlkNum = blkNum
+			txs[i].Transaction.TxIdx = uint32(i)
+		}
+
+		if block.Header.Number != blkNum {
+			report.addIssue(CheckHeader, blkNum, "header has number %d", block.Header.Number)
+		}
+		if !bytes.Equal(block.Header.Hash(), block.BlockHash) {
+			report.addIssue(CheckHeader, blkNum, "block hash %s does not match header", hexutil.Encode(block.BlockHash))
+		}
+		if prevBlock != nil && !bytes.Equal(block.Header.PrevHash, prevBlock.BlockHash) {
+			report.addIssue(CheckPrevHash, blkNum, "previous hash %s does not match block %d hash %s", hexutil.Encode(block.Header.PrevHash), blkNum-1, hexutil.Encode(prevBlock.BlockHash))
+		}
+		if prevBlock == nil && blkNum == 1 && len(block.Header.PrevHash) != 0 {
+			report.addIssue(CheckPrevHash, blkNum, "first block has previous hash %s", hexutil.Encode(block.Header.PrevHash))
+		}
+
+		expected := nextBlock(nil, txs)
+		if !bytes.Equal(expected.Header.MerkleRoot, block.Header.MerkleRoot) {
+			report.addIssue(CheckMerkleRoot, blkNum, "merkle root %s does not match transactions root %s", hexutil.Encode(block.Header.MerkleRoot), hexutil.Encode(expected.Header.MerkleRoot))
+		}
+
+		fees := big.NewInt(0)
+		for _, tx := range txs {
+			fees = fees.Add(fees, tx.Transaction.Fee)
+		}
+		if stored := new(big.Int).SetBytes(rec.Fees); stored.Cmp(fees) != 0 {
+			report.addIssue(CheckFees, blkNum, "fee record %s does not match transaction fees %s", stored.Text(10), fees.Text(10))
+		}
+		if meta.Fees.Cmp(fees) != 0 {
+			report.addIssue(CheckFees, blkNum, "metadata fees %s do not match transaction fees %s", meta.Fees.Text(10), fees.Text(10))
+		}
+		if int(meta.TransactionCount) != len(txs) {
+			report.addIssue(CheckHeader, blkNum, "metadata counts %d transactions, found %d", meta.TransactionCount, len(txs))
+		}
+
+		for i := range txs {
+			verifyTransaction(report, &txs[i], owners, spenders, earnKeys, spendKeys)
+		}
+		if blkNum <= lastPruned {
+			pruned = append(pruned, txs...)
+		}
+
+		report.Blocks++
+		report.Transactions += uint64(len(txs))
+		prevBlock = &block
+		if progress != nil {
+			progress(blkNum)
+		}
+	}
+
+	prunedEarnKeys := make(map[string]bool)
+	for i := range pruned {
+		if !isSpentIn(&pruned[i].Transaction, spenders) {
+			continue
+		}
+		for key := range earnKeysOf(&pruned[i]) {
+			delete(earnKeys, key)
+			prunedEarnKeys[key] = true
+		}
+	}
+
+	if err := verifyIndex(report, snap, earnKeyPrefix, CheckEarnIndex, func(key []byte, value []byte) bool {
+		if prunedEarnKeys[string(key)] {
+			return true
+		}
+		if !earnKeys[string(key)] {
+			return false
+		}
+		delete(earnKeys, string(key))
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	for key := range earnKeys {
+		report.addIssue(CheckEarnIndex, 0, "missing earn key %s", key)
+	}
+
+	for _, prefix := range []string{spendKeyPrefix, spendExitKeyPrefix} {
+		err := verifyIndex(report, snap, prefix, CheckSpendIndex, func(key []byte, value []byte) bool {
+			expected, ok := spendKeys[string(key)]
+			if !ok {
+				return false
+			}
+			delete(spendKeys, string(key))
+			if !bytes.Equal(expected, value) {
+				report.addIssue(CheckSpendIndex, 0, "spend key %s points to the wrong spending transaction", key)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for key := range spendKeys {
+		report.addIssue(CheckSpendIndex, 0, "missing spend key %s", key)
+	}
+
+	return report, nil
+}
+
+func verifyTransaction(report *VerifyReport, confirmed *chain.ConfirmedTransaction, owners map[outpoint]common.Address, spenders map[outpoint]chain.SpendIdentifier, earnKeys map[string]bool, spendKeys map[string][]byte) {
+	tx := &confirmed.Transaction
+
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if input.IsZeroInput() {
+			continue
+		}
+
+		spent := outpoint{input.BlkNum, input.TxIdx, input.OutIdx}
+		owner, ok := owners[spent]
+		if !ok {
+			report.addIssue(CheckInputs, tx.BlkNum, "transaction %d input %d spends unknown output %d:%d:%d", tx.TxIdx, i, input.BlkNum, input.TxIdx, input.OutIdx)
+			continue
+		}
+		if prev, ok := spenders[spent]; ok {
+			report.addIssue(CheckDoubleSpend, tx.BlkNum, "transaction %d input %d spends output %d:%d:%d already spent by %d:%d", tx.TxIdx, i, input.BlkNum, input.TxIdx, input.OutIdx, prev.BlockNumber, prev.TransactionIndex)
+			continue
+		}
+
+		ident := chain.SpendIdentifier{
+			BlockNumber:      tx.BlkNum,
+			TransactionIndex: tx.TxIdx,
+			InputIndex:       i,
+		}
+		spenders[spent] = ident
+		identBytes, _ := ident.MarshalBinary()
+		if i == 0 && tx.Output0.IsExit() {
+			spendKeys[string(spendExit(&owner, input))] = identBytes
+		} else {
+			spendKeys[string(spend(&owner, input))] = identBytes
+		}
+	}
+
+	for key := range earnKeysOf(confirmed) {
+		earnKeys[key] = true
+	}
+	for i := uint8(0); i < tx.NumOutputs(); i++ {
+		output := tx.OutputAt(i)
+		if !output.IsZeroOutput() && !output.IsExit() {
+			owners[outpoint{tx.BlkNum, tx.TxIdx, i}] = output.Owner
+		}
+	}
+}
+
+func earnKeysOf(confirmed *chain.ConfirmedTransaction) map[string]bool {
+	keys := make(map[string]bool)
+	tx := &confirmed.Transaction
+	for i := uint8(0); i < tx.NumOutputs(); i++ {
+		output := tx.OutputAt(i)
+		if output.IsZeroOutput() {
+			continue
+		}
+		keys[string(earn(&output.Owner, *confirmed, i))] = true
+	}
+	return keys
+}
+
+// isSpentIn reports whether every output of tx has been spent or exited, the
+// condition under which Prune drops its earn keys.
+func isSpentIn(tx *chain.Transaction, spenders map[outpoint]chain.SpendIdentifier) bool {
+	for i := uint8(0); i < tx.NumOutputs(); i++ {
+		output := tx.OutputAt(i)
+		if output.IsZeroOutput() || output.IsExit() {
+			continue
+		}
+		if _, ok := spenders[outpoint{tx.BlkNum, tx.TxIdx, i}]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyIndex reports every key under prefix that check does not expect.
+func verifyIndex(report *VerifyReport, snap *leveldb.Snapshot, prefix string, name string, check func(key []byte, value []byte) bool) error {
+	iter := snap.NewIterator(levelutil.BytesPrefix([]byte(prefix+keyPartsSeparator)), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		if !check(iter.Key(), iter.Value()) {
+			report.addIssue(name, 0, "unexpected key %s", iter.Key())
+		}
+	}
+	return iter.Error()
+}
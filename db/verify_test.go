@@ -0,0 +1,79 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyIntactStore(t *testing.T) {
+	level, ps := newLevelStorage(t)
+	defer level.Close()
+	seedStorage(t, ps)
+
+	var verified []uint64
+	report, err := ps.Verify(func(blkNum uint64) {
+		verified = append(verified, blkNum)
+	})
+	require.NoError(t, err)
+	require.True(t, report.OK(), "issues: %v", report.Issues)
+	require.Equal(t, []uint64{1, 2, 3}, verified)
+	require.Equal(t, uint64(3), report.LatestBlock)
+	require.Equal(t, uint64(3), report.Blocks)
+	require.Equal(t, uint64(3), report.Transactions)
+}
+
+func TestVerifyDetectsCorruption(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(t *testing.T, ps *Storage)
+		check   string
+	}{
+		{
+			name: "fee record",
+			corrupt: func(t *testing.T, ps *Storage) {
+				require.NoError(t, ps.db.Put(blockFeesKey(3), []byte{1}, nil))
+			},
+			check: CheckFees,
+		},
+		{
+			name: "missing earn key",
+			corrupt: func(t *testing.T, ps *Storage) {
+				tx, err := ps.FindTransactionByBlockNumTxIdx(3, 0)
+				require.NoError(t, err)
+				require.NoError(t, ps.db.Delete(earn(&bob, *tx, 0), nil))
+			},
+			check: CheckEarnIndex,
+		},
+		{
+			name: "wrong spend key",
+			corrupt: func(t *testing.T, ps *Storage) {
+				tx, err := ps.FindTransactionByBlockNumTxIdx(3, 0)
+				require.NoError(t, err)
+				require.NoError(t, ps.db.Put(spend(&alice, tx.Transaction.Input0), []byte{0}, nil))
+			},
+			check: CheckSpendIndex,
+		},
+		{
+			name: "missing transaction",
+			corrupt: func(t *testing.T, ps *Storage) {
+				require.NoError(t, ps.db.Delete(blkNumTxIdxKey(2, 0), nil))
+			},
+			check: CheckMerkleRoot,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, ps := newLevelStorage(t)
+			defer level.Close()
+			seedStorage(t, ps)
+			tt.corrupt(t, ps)
+
+			report, err := ps.Verify(nil)
+			require.NoError(t, err)
+			require.False(t, report.OK())
+			require.NotZero(t, report.IssueCounts[tt.check], "issues: %v", report.Issues)
+		})
+	}
+}
@@ -10,10 +10,10 @@ import (
 	"github.com/kyokan/plasma/eth/contracts"
 	"github.com/kyokan/plasma/chain"
 	"crypto/ecdsa"
-	"github.com/ethereum/go-ethereum/crypto"
 			log2 "github.com/kyokan/plasma/log"
 	"github.com/sirupsen/logrus"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 const SignaturePreamble = "\x19Ethereum Signed Message:\n"
@@ -56,6 +56,7 @@ type ChallengeExitOpts struct {
 
 type Client interface {
 	UserAddress() common.Address
+	ContractAddress() common.Address
 	SubmitBlock(util.Hash, uint32, *big.Int, *big.Int) error
 	SubmitBlocks(merkleRoot []util.Hash, txCount []uint32, fees []*big.Int, blkNum *big.Int) error
 	Deposit(amount *big.Int) (*types.Receipt, error)
@@ -71,6 +72,8 @@ type Client interface {
 	StartedDepositExitFilter(uint64) ([]contracts.PlasmaStartedDepositExit, uint64, error)
 
 	EthereumBlockHeight() (uint64, error)
+	ChainID() (*big.Int, error)
+	Block(blkNum uint64) (*Block, error)
 }
 
 type DepositEvent struct {
@@ -80,13 +83,14 @@ type DepositEvent struct {
 }
 
 type clientState struct {
-	client     *ethclient.Client
-	rpc        *rpc.Client
-	contract   *contracts.Plasma
-	privateKey *ecdsa.PrivateKey
+	client       *ethclient.Client
+	rpc          *rpc.Client
+	contract     *contracts.Plasma
+	contractAddr common.Address
+	signer       Signer
 }
 
-func NewClient(nodeUrl string, contractAddr string, privateKey *ecdsa.PrivateKey) (Client, error) {
+func NewClient(nodeUrl string, contractAddr string, signer Signer) (Client, error) {
 	addr := common.HexToAddress(contractAddr)
 	c, err := rpc.Dial(nodeUrl)
 	if err != nil {
@@ -96,15 +100,20 @@ func NewClient(nodeUrl string, contractAddr string, privateKey *ecdsa.PrivateKey
 	client := ethclient.NewClient(c)
 	contract, err := contracts.NewPlasma(addr, client)
 	return &clientState{
-		client:     client,
-		rpc:        c,
-		contract:   contract,
-		privateKey: privateKey,
+		client:       client,
+		rpc:          c,
+		contract:     contract,
+		contractAddr: addr,
+		signer:       signer,
 	}, nil
 }
 
 func (c *clientState) UserAddress() common.Address {
-	return crypto.PubkeyToAddress(*(c.privateKey.Public()).(*ecdsa.PublicKey))
+	return c.signer.Address()
+}
+
+func (c *clientState) ContractAddress() common.Address {
+	return c.contractAddr
 }
 
 func (c *clientState) SubmitBlock(merkleHash util.Hash, txInBlock uint32, feesInBlock *big.Int, blkNum *big.Int) error {
@@ -112,7 +121,7 @@ func (c *clientState) SubmitBlock(merkleHash util.Hash, txInBlock uint32, feesIn
 }
 
 func (c *clientState) SubmitBlocks(merkleHashes []util.Hash, txInBlocks []uint32, feesInBlocks []*big.Int, firstBlkNum *big.Int) error {
-	opts := CreateKeyedTransactor(c.privateKey)
+	opts := CreateTransactor(c.signer)
 	hashes := make([][32]byte, len(merkleHashes))
 	for i := 0; i < len(merkleHashes); i++ {
 		copy(hashes[i][:], merkleHashes[i][:32])
@@ -130,7 +139,7 @@ func (c *clientState) SubmitBlocks(merkleHashes []util.Hash, txInBlocks []uint32
 }
 
 func (c *clientState) Deposit(amount *big.Int) (*types.Receipt, error) {
-	opts := CreateKeyedTransactor(c.privateKey)
+	opts := CreateTransactor(c.signer)
 	opts.Value = amount
 
 	clientLogger.WithFields(logrus.Fields{
@@ -138,7 +147,7 @@ func (c *clientState) Deposit(amount *big.Int) (*types.Receipt, error) {
 	}).Info("depositing funds")
 
 	receipt, err := ContractCall(c.client, func() (*types.Transaction, error) {
-		return c.contract.Deposit(opts, crypto.PubkeyToAddress(c.privateKey.PublicKey))
+		return c.contract.Deposit(opts, c.signer.Address())
 	})
 	if err != nil {
 		return nil, err
@@ -153,7 +162,7 @@ func (c *clientState) Deposit(amount *big.Int) (*types.Receipt, error) {
 }
 
 func (c *clientState) Challenge(exitingTx *chain.ConfirmedTransaction, exitingOutput uint8, exitingDepositNonce *big.Int, challengingTx *chain.ConfirmedTransaction, proof []byte, authSig chain.Signature) (*types.Receipt, error) {
-	opts := CreateKeyedTransactor(c.privateKey)
+	opts := CreateTransactor(c.signer)
 
 	exitingTxPos := [4]*big.Int{
 		util.Uint642Big(exitingTx.Transaction.BlkNum),
@@ -190,3 +199,29 @@ func (c *clientState) EthereumBlockHeight() (uint64, error) {
 
 	return header.Number.Uint64(), nil
 }
+
+// Block returns the root and creation time the contract recorded for blkNum.
+// The root is empty if the block hasn't been submitted yet.
+func (c *clientState) Block(blkNum uint64) (*Block, error) {
+	res, err := c.contract.PlasmaChain(CreateCallOpts(common.Address{}), new(big.Int).SetUint64(blkNum))
+	if err != nil {
+		return nil, err
+	}
+	if res.Header == [32]byte{} {
+		return &Block{StartedAt: res.CreatedAt}, nil
+	}
+	return &Block{
+		Root:      res.Header[:],
+		StartedAt: res.CreatedAt,
+	}, nil
+}
+
+// ChainID returns the EIP-155 chain ID of the Ethereum node, falling back to
+// its network ID if it does not support eth_chainId.
+func (c *clientState) ChainID() (*big.Int, error) {
+	var id hexutil.Big
+	if err := c.rpc.CallContext(context.Background(), &id, "eth_chainId"); err == nil {
+		return (*big.Int)(&id), nil
+	}
+	return c.client.NetworkID(context.Background())
+}
@@ -0,0 +1,185 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/util"
+	"github.com/pkg/errors"
+)
+
+// ExternalSignerTimeout bounds each signing request. It is generous because
+// Clef may wait for a human to approve the request.
+const ExternalSignerTimeout = 2 * time.Minute
+
+// ExternalSigner asks a Clef-style signer to sign over JSON-RPC on a Unix
+// socket, so the key never enters this process.
+type ExternalSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+// externalTxArgs mirrors the transaction arguments of Clef's
+// account_signTransaction.
+type externalTxArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      hexutil.Uint64  `json:"gas"`
+	GasPrice hexutil.Big     `json:"gasPrice"`
+	Value    hexutil.Big     `json:"value"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Data     *hexutil.Bytes  `json:"data"`
+}
+
+type externalSignTxResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+// NewExternalSigner connects to the signer listening on socketPath and
+// checks that it manages address.
+func NewExternalSigner(socketPath string, address common.Address) (*ExternalSigner, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ExternalSignerTimeout)
+	defer cancel()
+
+	client, err := rpc.DialIPC(ctx, socketPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to external signer")
+	}
+
+	var accounts []json.RawMessage
+	if err := client.CallContext(ctx, &accounts, "account_list"); err != nil {
+		client.Close()
+		return nil, errors.Wrap(err, "failed to list external signer accounts")
+	}
+	for _, raw := range accounts {
+		if listedAddress(raw) == address {
+			return &ExternalSigner{
+				client:  client,
+				address: address,
+			}, nil
+		}
+	}
+
+	client.Close()
+	return nil, errors.New(fmt.Sprintf("external signer does not manage %s", address.Hex()))
+}
+
+// listedAddress reads an account_list entry, which older versions of Clef
+// return as an object and newer ones as a bare address.
+func listedAddress(raw json.RawMessage) common.Address {
+	var addr common.Address
+	if err := json.Unmarshal(raw, &addr); err == nil {
+		return addr
+	}
+	var acct struct {
+		Address common.Address `json:"address"`
+	}
+	json.Unmarshal(raw, &acct)
+	return acct.Address
+}
+
+func (s *ExternalSigner) Address() common.Address {
+	return s.address
+}
+
+// SignHash uses account_sign, which prefixes the data with the Ethereum
+// signed message preamble just like Sign.
+func (s *ExternalSigner) SignHash(hash util.Hash) (chain.Signature, error) {
+	var sig chain.Signature
+	ctx, cancel := context.WithTimeout(context.Background(), ExternalSignerTimeout)
+	defer cancel()
+
+	var res hexutil.Bytes
+	if err := s.client.CallContext(ctx, &res, "account_sign", s.address, hexutil.Bytes(hash)); err != nil {
+		return sig, errors.Wrap(err, "external signer failed to sign")
+	}
+	if len(res) != len(sig) {
+		return sig, errors.New(fmt.Sprintf("external signer returned a %d byte signature", len(res)))
+	}
+
+	copy(sig[:], res)
+	// Clef returns V as 27 or 28, while Sign returns 0 or 1
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	return sig, nil
+}
+
+// SignTypedData uses account_signTypedData, so the signer can show the
+// typed data to its user.
+func (s *ExternalSigner) SignTypedData(data *TypedData) (chain.Signature, error) {
+	var sig chain.Signature
+	ctx, cancel := context.WithTimeout(context.Background(), ExternalSignerTimeout)
+	defer cancel()
+
+	var res hexutil.Bytes
+	if err := s.client.CallContext(ctx, &res, "account_signTypedData", s.address, data); err != nil {
+		return sig, errors.Wrap(err, "external signer failed to sign typed data")
+	}
+	if len(res) != len(sig) {
+		return sig, errors.New(fmt.Sprintf("external signer returned a %d byte signature", len(res)))
+	}
+
+	copy(sig[:], res)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	// make sure the signer hashed the typed data the same way we do
+	if err := ValidateTypedDataSignature(data, sig[:], s.address); err != nil {
+		return sig, errors.Wrap(err, "external signer signed different typed data")
+	}
+	return sig, nil
+}
+
+func (s *ExternalSigner) SignTx(tx *types.Transaction) (*types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ExternalSignerTimeout)
+	defer cancel()
+
+	data := hexutil.Bytes(tx.Data())
+	args := externalTxArgs{
+		From:     s.address,
+		To:       tx.To(),
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: hexutil.Big(*tx.GasPrice()),
+		Value:    hexutil.Big(*tx.Value()),
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+		Data:     &data,
+	}
+	var res externalSignTxResult
+	if err := s.client.CallContext(ctx, &res, "account_signTransaction", args); err != nil {
+		return nil, errors.Wrap(err, "external signer failed to sign transaction")
+	}
+
+	signed := new(types.Transaction)
+	if err := rlp.DecodeBytes(res.Raw, signed); err != nil {
+		return nil, errors.Wrap(err, "external signer returned an invalid transaction")
+	}
+	if !sameTxFields(tx, signed) {
+		return nil, errors.New("external signer returned a different transaction")
+	}
+	return signed, nil
+}
+
+func sameTxFields(a *types.Transaction, b *types.Transaction) bool {
+	if (a.To() == nil) != (b.To() == nil) || (a.To() != nil && *a.To() != *b.To()) {
+		return false
+	}
+	return a.Nonce() == b.Nonce() &&
+		a.Gas() == b.Gas() &&
+		a.GasPrice().Cmp(b.GasPrice()) == 0 &&
+		a.Value().Cmp(b.Value()) == 0 &&
+		bytes.Equal(a.Data(), b.Data())
+}
+
+func (s *ExternalSigner) Close() {
+	s.client.Close()
+}
@@ -0,0 +1,163 @@
+package eth
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/kyokan/plasma/util"
+	"github.com/stretchr/testify/require"
+)
+
+// StubSigner implements the parts of Clef's account API that
+// ExternalSigner uses. The rpc package only serves exported types.
+type StubSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+type StubTxArgs externalTxArgs
+
+type StubSignTxResult externalSignTxResult
+
+func (s *StubSigner) List() ([]common.Address, error) {
+	return []common.Address{crypto.PubkeyToAddress(s.key.PublicKey)}, nil
+}
+
+func (s *StubSigner) Sign(addr common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	sig, err := crypto.Sign(util.GethHash(data), s.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+func (s *StubSigner) SignTypedData(addr common.Address, data TypedData) (hexutil.Bytes, error) {
+	sig, err := SignTypedData(s.key, &data)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig[:], nil
+}
+
+func (s *StubSigner) SignTransaction(args StubTxArgs) (*StubSignTxResult, error) {
+	tx := types.NewTransaction(uint64(args.Nonce), *args.To, (*big.Int)(&args.Value), uint64(args.Gas), (*big.Int)(&args.GasPrice), *args.Data)
+	signed, err := types.SignTx(tx, types.HomesteadSigner{}, s.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		return nil, err
+	}
+	return &StubSignTxResult{Raw: raw}, nil
+}
+
+func startStubSigner(t *testing.T, key *ecdsa.PrivateKey) (string, func()) {
+	dir, err := ioutil.TempDir("", "signer")
+	require.NoError(t, err)
+	socket := filepath.Join(dir, "clef.ipc")
+
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("account", &StubSigner{key: key}))
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	go srv.ServeListener(l)
+
+	return socket, func() {
+		l.Close()
+		srv.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestExternalSignerSignHash(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	socket, stop := startStubSigner(t, key)
+	defer stop()
+
+	local := NewLocalSigner(key)
+	external, err := NewExternalSigner(socket, local.Address())
+	require.NoError(t, err)
+	defer external.Close()
+	require.Equal(t, local.Address(), external.Address())
+
+	hash := util.Sha256([]byte("plasma"))
+	expected, err := local.SignHash(hash)
+	require.NoError(t, err)
+	actual, err := external.SignHash(hash)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+	require.NoError(t, ValidateSignature(hash, actual[:], local.Address()))
+}
+
+func TestExternalSignerSignTypedData(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	socket, stop := startStubSigner(t, key)
+	defer stop()
+
+	local := NewLocalSigner(key)
+	external, err := NewExternalSigner(socket, local.Address())
+	require.NoError(t, err)
+	defer external.Close()
+
+	domain := NewPlasmaDomain(big.NewInt(1337), common.HexToAddress("0xf12b5dd4ead5f743c6baa640b0216200e89b60da"))
+	data := ConfirmationsRequestTypedData(domain, 12, 3, 1, make([]byte, 32))
+	expected, err := local.SignTypedData(data)
+	require.NoError(t, err)
+	actual, err := external.SignTypedData(data)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+	require.NoError(t, ValidateTypedDataSignature(data, actual[:], local.Address()))
+}
+
+func TestExternalSignerSignTx(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	socket, stop := startStubSigner(t, key)
+	defer stop()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	external, err := NewExternalSigner(socket, addr)
+	require.NoError(t, err)
+	defer external.Close()
+
+	to := common.HexToAddress("0xf25186b5081ff5ce73482ad761db0eb0d25abfbf")
+	tx := types.NewTransaction(7, to, big.NewInt(1000), 4712388, big.NewInt(10000000000), []byte{0x01, 0x02})
+	opts := CreateTransactor(external)
+	signed, err := opts.Signer(types.HomesteadSigner{}, addr, tx)
+	require.NoError(t, err)
+	sender, err := types.Sender(types.HomesteadSigner{}, signed)
+	require.NoError(t, err)
+	require.Equal(t, addr, sender)
+	require.Equal(t, tx.Nonce(), signed.Nonce())
+	require.Equal(t, tx.Data(), signed.Data())
+
+	_, err = opts.Signer(types.HomesteadSigner{}, to, tx)
+	require.Error(t, err)
+}
+
+func TestExternalSignerUnknownAccount(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	socket, stop := startStubSigner(t, key)
+	defer stop()
+
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	_, err = NewExternalSigner(socket, crypto.PubkeyToAddress(other.PublicKey))
+	require.Error(t, err)
+}
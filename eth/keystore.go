@@ -0,0 +1,107 @@
+package eth
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// PassphraseSource describes where a keystore passphrase comes from. The
+// password file wins over the environment variable, and the user is only
+// prompted if neither is set.
+type PassphraseSource struct {
+	PasswordFile string
+	EnvVar       string
+}
+
+// Passphrase returns the keystore passphrase. When prompting for a new
+// passphrase, confirm asks for it twice.
+func (p *PassphraseSource) Passphrase(prompt string, confirm bool) (string, error) {
+	if p.PasswordFile != "" {
+		data, err := ioutil.ReadFile(p.PasswordFile)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read password file")
+		}
+		// only the first line counts, like geth's --password
+		return strings.TrimRight(strings.SplitN(string(data), "\n", 2)[0], "\r"), nil
+	}
+	if p.EnvVar != "" {
+		if pass, ok := os.LookupEnv(p.EnvVar); ok {
+			return pass, nil
+		}
+	}
+
+	pass, err := readPassphrase(prompt)
+	if err != nil {
+		return "", err
+	}
+	if !confirm {
+		return pass, nil
+	}
+	again, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if pass != again {
+		return "", errors.New("passphrases do not match")
+	}
+	return pass, nil
+}
+
+func readPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	fd := int(os.Stdin.Fd())
+	if terminal.IsTerminal(fd) {
+		pass, err := terminal.ReadPassword(fd)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read passphrase")
+		}
+		return string(pass), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.Wrap(err, "failed to read passphrase")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// IsKeystoreJSON reports whether a key file holds an encrypted JSON keystore
+// rather than a hex private key.
+func IsKeystoreJSON(data []byte) bool {
+	return strings.HasPrefix(strings.TrimSpace(string(data)), "{")
+}
+
+// DecryptKeystore decrypts a go-ethereum v3 JSON keystore.
+func DecryptKeystore(data []byte, passphrase string) (*ecdsa.PrivateKey, error) {
+	key, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt keystore")
+	}
+	return key.PrivateKey, nil
+}
+
+// OpenKeystore opens a directory of v3 JSON keystores, creating it if needed.
+func OpenKeystore(dir string) *keystore.KeyStore {
+	return keystore.NewKeyStore(dir, keystore.StandardScryptN, keystore.StandardScryptP)
+}
+
+// KeystoreFile returns the path of the keystore in ks that holds addr.
+func KeystoreFile(ks *keystore.KeyStore, addr common.Address) (string, error) {
+	for _, acct := range ks.Accounts() {
+		if acct.Address == addr {
+			return acct.URL.Path, nil
+		}
+	}
+	return "", errors.New(fmt.Sprintf("no keystore found for %s", addr.Hex()))
+}
@@ -0,0 +1,84 @@
+package eth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const testPassphraseEnv = "PLASMA_TEST_KEYSTORE_PASSPHRASE"
+
+func TestPassphraseSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plasma-keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, ioutil.WriteFile(passwordFile, []byte("from file\r\nsecond line\n"), 0600))
+	require.NoError(t, os.Setenv(testPassphraseEnv, "from env"))
+	defer os.Unsetenv(testPassphraseEnv)
+
+	tests := []struct {
+		name     string
+		source   PassphraseSource
+		expected string
+	}{
+		{"password file wins", PassphraseSource{PasswordFile: passwordFile, EnvVar: testPassphraseEnv}, "from file"},
+		{"environment variable", PassphraseSource{EnvVar: testPassphraseEnv}, "from env"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pass, err := tt.source.Passphrase("Passphrase: ", true)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, pass)
+		})
+	}
+
+	missing := PassphraseSource{PasswordFile: filepath.Join(dir, "missing")}
+	_, err = missing.Passphrase("Passphrase: ", false)
+	require.Error(t, err)
+}
+
+func TestIsKeystoreJSON(t *testing.T) {
+	require.True(t, IsKeystoreJSON([]byte(`{"version":3}`)))
+	require.True(t, IsKeystoreJSON([]byte("\n  {\"version\":3}")))
+	require.False(t, IsKeystoreJSON([]byte("c87509a1c067bbde78beb793e6fa76530b6382a4c0241e5e4a9ec0a0f44dc0d3\n")))
+	require.False(t, IsKeystoreJSON(nil))
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plasma-keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	// light scrypt parameters keep the test fast, and decryption reads them
+	// from the file
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	acct, err := ks.ImportECDSA(key, "correct horse")
+	require.NoError(t, err)
+
+	path, err := KeystoreFile(OpenKeystore(dir), acct.Address)
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.True(t, IsKeystoreJSON(data))
+
+	decrypted, err := DecryptKeystore(data, "correct horse")
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(decrypted.PublicKey))
+
+	_, err = DecryptKeystore(data, "battery staple")
+	require.Error(t, err)
+
+	_, err = KeystoreFile(ks, common.HexToAddress("0xf17f52151ebef6c7334fad080c5704d77216b732"))
+	require.Error(t, err)
+}
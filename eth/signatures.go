@@ -20,9 +20,36 @@ func Sign(privKey *ecdsa.PrivateKey, hash util.Hash) (chain.Signature, error) {
 	return sig, nil
 }
 
+// SignTypedData signs the EIP-712 digest of data, without the Ethereum
+// signed message prefix.
+func SignTypedData(privKey *ecdsa.PrivateKey, data *TypedData) (chain.Signature, error) {
+	var sig chain.Signature
+	hash, err := data.Hash()
+	if err != nil {
+		return sig, err
+	}
+	rawSig, err := crypto.Sign(hash, privKey)
+	if err != nil {
+		return sig, err
+	}
+	copy(sig[:], rawSig)
+	return sig, nil
+}
+
 func ValidateSignature(hash, signature []byte, address common.Address) error {
-	ethHash := util.GethHash(hash)
+	return validateDigest(util.GethHash(hash), signature, address)
+}
 
+// ValidateTypedDataSignature checks a signature made with SignTypedData.
+func ValidateTypedDataSignature(data *TypedData, signature []byte, address common.Address) error {
+	hash, err := data.Hash()
+	if err != nil {
+		return err
+	}
+	return validateDigest(hash, signature, address)
+}
+
+func validateDigest(ethHash, signature []byte, address common.Address) error {
 	sigCopy := make([]byte, len(signature))
 	copy(sigCopy, signature)
 	if len(sigCopy) == 65 && sigCopy[64] > 26 {
@@ -40,3 +67,28 @@ func ValidateSignature(hash, signature []byte, address common.Address) error {
 	}
 	return nil
 }
+
+// SignatureVerifier checks the signatures on plasma transactions. They are
+// EIP-712 typed data in Domain or, if AcceptLegacy is set, Ethereum signed
+// messages over the older RLP based hashes.
+type SignatureVerifier struct {
+	Domain       TypedDataDomain
+	AcceptLegacy bool
+}
+
+func NewSignatureVerifier(domain TypedDataDomain, acceptLegacy bool) *SignatureVerifier {
+	return &SignatureVerifier{
+		Domain:       domain,
+		AcceptLegacy: acceptLegacy,
+	}
+}
+
+// Validate checks that address signed either typed or, if legacy signatures
+// are accepted, legacyHash.
+func (v *SignatureVerifier) Validate(typed *TypedData, legacyHash util.Hash, signature []byte, address common.Address) error {
+	err := ValidateTypedDataSignature(typed, signature, address)
+	if err == nil || !v.AcceptLegacy {
+		return err
+	}
+	return ValidateSignature(legacyHash, signature, address)
+}
@@ -0,0 +1,71 @@
+package eth
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/util"
+	"github.com/pkg/errors"
+)
+
+// Signer signs on behalf of a single address, so that callers never need to
+// hold its private key.
+type Signer interface {
+	Address() common.Address
+	// SignHash signs a plasma hash exactly like Sign does.
+	SignHash(hash util.Hash) (chain.Signature, error)
+	// SignTypedData signs EIP-712 typed data exactly like SignTypedData.
+	SignTypedData(data *TypedData) (chain.Signature, error)
+	// SignTx signs an Ethereum transaction sent to the root chain.
+	SignTx(tx *types.Transaction) (*types.Transaction, error)
+}
+
+// LocalSigner signs with a private key held in memory.
+type LocalSigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+func NewLocalSigner(privateKey *ecdsa.PrivateKey) *LocalSigner {
+	return &LocalSigner{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+	}
+}
+
+func (s *LocalSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *LocalSigner) SignHash(hash util.Hash) (chain.Signature, error) {
+	return Sign(s.privateKey, hash)
+}
+
+func (s *LocalSigner) SignTypedData(data *TypedData) (chain.Signature, error) {
+	return SignTypedData(s.privateKey, data)
+}
+
+func (s *LocalSigner) SignTx(tx *types.Transaction) (*types.Transaction, error) {
+	return types.SignTx(tx, types.HomesteadSigner{}, s.privateKey)
+}
+
+// CreateTransactor returns transact options that sign contract calls with
+// signer, using the same gas settings as CreateKeyedTransactor.
+func CreateTransactor(signer Signer) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: signer.Address(),
+		Signer: func(_ types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != signer.Address() {
+				return nil, errors.New("not authorized to sign this account")
+			}
+			return signer.SignTx(tx)
+		},
+		GasPrice: new(big.Int).SetUint64(10 * 1000000000),
+		GasLimit: uint64(4712388),
+	}
+}
@@ -0,0 +1,269 @@
+package eth
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/kyokan/plasma/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	PlasmaDomainName    = "Plasma"
+	PlasmaDomainVersion = "1"
+)
+
+// TypedDataField is one member of an EIP-712 struct type.
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedDataTypes maps the name of each struct type to its members.
+type TypedDataTypes map[string][]TypedDataField
+
+// TypedDataDomain binds typed data signatures to one plasma contract on one
+// chain.
+type TypedDataDomain struct {
+	Name              string         `json:"name"`
+	Version           string         `json:"version"`
+	ChainID           *big.Int       `json:"chainId"`
+	VerifyingContract common.Address `json:"verifyingContract"`
+}
+
+// TypedData is EIP-712 typed data in the format of eth_signTypedData, so
+// that signers can show what is being signed rather than an opaque hash.
+//
+// Message values are strings, nested maps for struct members, or slices for
+// array members. Integers are decimal or 0x-prefixed hex strings, addresses
+// and bytes are 0x-prefixed hex strings.
+type TypedData struct {
+	Types       TypedDataTypes         `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      TypedDataDomain        `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+var domainFields = []TypedDataField{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+}
+
+func NewPlasmaDomain(chainID *big.Int, contract common.Address) TypedDataDomain {
+	return TypedDataDomain{
+		Name:              PlasmaDomainName,
+		Version:           PlasmaDomainVersion,
+		ChainID:           new(big.Int).Set(chainID),
+		VerifyingContract: contract,
+	}
+}
+
+// NewTypedData returns typed data for message, which is of type primaryType.
+// types does not need to include EIP712Domain.
+func NewTypedData(domain TypedDataDomain, types TypedDataTypes, primaryType string, message map[string]interface{}) *TypedData {
+	allTypes := TypedDataTypes{
+		"EIP712Domain": domainFields,
+	}
+	for name, fields := range types {
+		allTypes[name] = fields
+	}
+	return &TypedData{
+		Types:       allTypes,
+		PrimaryType: primaryType,
+		Domain:      domain,
+		Message:     message,
+	}
+}
+
+// Hash returns the digest that is signed:
+// keccak256("\x19\x01" ‖ hashStruct(domain) ‖ hashStruct(message)).
+func (t *TypedData) Hash() (util.Hash, error) {
+	domainHash, err := t.DomainSeparator()
+	if err != nil {
+		return nil, err
+	}
+	messageHash, err := hashStruct(t.Types, t.PrimaryType, t.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write([]byte("\x19\x01"))
+	buf.Write(domainHash)
+	buf.Write(messageHash)
+	return util.Keccak256(buf.Bytes()), nil
+}
+
+func (t *TypedData) DomainSeparator() (util.Hash, error) {
+	chainID := "0"
+	if t.Domain.ChainID != nil {
+		chainID = t.Domain.ChainID.Text(10)
+	}
+	return hashStruct(t.Types, "EIP712Domain", map[string]interface{}{
+		"name":              t.Domain.Name,
+		"version":           t.Domain.Version,
+		"chainId":           chainID,
+		"verifyingContract": t.Domain.VerifyingContract.Hex(),
+	})
+}
+
+func hashStruct(types TypedDataTypes, name string, data map[string]interface{}) (util.Hash, error) {
+	fields, ok := types[name]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("unknown typed data type %s", name))
+	}
+
+	var buf bytes.Buffer
+	buf.Write(util.Keccak256([]byte(encodeType(types, name))))
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("typed data %s is missing %s", name, field.Name))
+		}
+		enc, err := encodeValue(types, field.Type, value)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("invalid %s.%s", name, field.Name))
+		}
+		buf.Write(enc)
+	}
+	return util.Keccak256(buf.Bytes()), nil
+}
+
+// encodeType returns name's signature followed by those of the struct types
+// it refers to, in alphabetical order.
+func encodeType(types TypedDataTypes, name string) string {
+	deps := make(map[string]bool)
+	findDependencies(types, name, deps)
+	delete(deps, name)
+	var sorted []string
+	for dep := range deps {
+		sorted = append(sorted, dep)
+	}
+	sort.Strings(sorted)
+
+	var buf bytes.Buffer
+	for _, typeName := range append([]string{name}, sorted...) {
+		buf.WriteString(typeName)
+		buf.WriteString("(")
+		for i, field := range types[typeName] {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString(field.Type)
+			buf.WriteString(" ")
+			buf.WriteString(field.Name)
+		}
+		buf.WriteString(")")
+	}
+	return buf.String()
+}
+
+func findDependencies(types TypedDataTypes, name string, deps map[string]bool) {
+	if deps[name] {
+		return
+	}
+	fields, ok := types[name]
+	if !ok {
+		return
+	}
+	deps[name] = true
+	for _, field := range fields {
+		findDependencies(types, strings.TrimSuffix(field.Type, "[]"), deps)
+	}
+}
+
+// encodeValue returns the 32 byte encoding of value as typ.
+func encodeValue(types TypedDataTypes, typ string, value interface{}) ([]byte, error) {
+	if strings.HasSuffix(typ, "[]") {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, errors.New("expected an array")
+		}
+		var buf bytes.Buffer
+		for _, item := range items {
+			enc, err := encodeValue(types, strings.TrimSuffix(typ, "[]"), item)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(enc)
+		}
+		return util.Keccak256(buf.Bytes()), nil
+	}
+	if _, ok := types[typ]; ok {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("expected a %s", typ))
+		}
+		return hashStruct(types, typ, data)
+	}
+
+	if typ == "bool" {
+		b, ok := value.(bool)
+		if !ok {
+			return nil, errors.New("expected a bool")
+		}
+		if b {
+			return common.LeftPadBytes([]byte{1}, 32), nil
+		}
+		return make([]byte, 32), nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("expected a string encoding a %s", typ))
+	}
+	switch {
+	case typ == "string":
+		return util.Keccak256([]byte(str)), nil
+	case typ == "bytes":
+		b, err := hexutil.Decode(str)
+		if err != nil {
+			return nil, err
+		}
+		return util.Keccak256(b), nil
+	case typ == "address":
+		if !common.IsHexAddress(str) {
+			return nil, errors.New("expected an address")
+		}
+		return common.LeftPadBytes(common.HexToAddress(str).Bytes(), 32), nil
+	case strings.HasPrefix(typ, "bytes"):
+		b, err := hexutil.Decode(str)
+		if err != nil {
+			return nil, err
+		}
+		if fmt.Sprintf("bytes%d", len(b)) != typ {
+			return nil, errors.New(fmt.Sprintf("expected %s", typ))
+		}
+		return common.RightPadBytes(b, 32), nil
+	case strings.HasPrefix(typ, "uint"):
+		n, err := parseTypedUint(str)
+		if err != nil {
+			return nil, err
+		}
+		return common.LeftPadBytes(n.Bytes(), 32), nil
+	}
+	return nil, errors.New(fmt.Sprintf("unsupported type %s", typ))
+}
+
+func parseTypedUint(str string) (*big.Int, error) {
+	base := 10
+	if strings.HasPrefix(str, "0x") {
+		str = str[2:]
+		base = 16
+	}
+	n, ok := new(big.Int).SetString(str, base)
+	if !ok {
+		return nil, errors.New("expected an integer")
+	}
+	if n.Sign() < 0 || n.BitLen() > 256 {
+		return nil, errors.New("integer out of range")
+	}
+	return n, nil
+}
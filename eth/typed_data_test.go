@@ -0,0 +1,135 @@
+package eth
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/kyokan/plasma/chain"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTypedDataHash checks the example from EIP-712.
+func TestTypedDataHash(t *testing.T) {
+	domain := TypedDataDomain{
+		Name:              "Ether Mail",
+		Version:           "1",
+		ChainID:           big.NewInt(1),
+		VerifyingContract: common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"),
+	}
+	types := TypedDataTypes{
+		"Person": {
+			{Name: "name", Type: "string"},
+			{Name: "wallet", Type: "address"},
+		},
+		"Mail": {
+			{Name: "from", Type: "Person"},
+			{Name: "to", Type: "Person"},
+			{Name: "contents", Type: "string"},
+		},
+	}
+	data := NewTypedData(domain, types, "Mail", map[string]interface{}{
+		"from": map[string]interface{}{
+			"name":   "Cow",
+			"wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826",
+		},
+		"to": map[string]interface{}{
+			"name":   "Bob",
+			"wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+		},
+		"contents": "Hello, Bob!",
+	})
+
+	require.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", encodeType(data.Types, "Mail"))
+	separator, err := data.DomainSeparator()
+	require.NoError(t, err)
+	require.Equal(t, "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", hexutil.Encode(separator))
+	hash, err := data.Hash()
+	require.NoError(t, err)
+	require.Equal(t, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hexutil.Encode(hash))
+}
+
+func TestTypedDataJSONRoundTrip(t *testing.T) {
+	domain := NewPlasmaDomain(big.NewInt(1337), common.HexToAddress("0xf12b5dd4ead5f743c6baa640b0216200e89b60da"))
+	data := ConfirmationsRequestTypedData(domain, 12, 3, 1, make([]byte, 32))
+	expected, err := data.Hash()
+	require.NoError(t, err)
+
+	enc, err := json.Marshal(data)
+	require.NoError(t, err)
+	var decoded TypedData
+	require.NoError(t, json.Unmarshal(enc, &decoded))
+	actual, err := decoded.Hash()
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestSignTypedData(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	domain := NewPlasmaDomain(big.NewInt(1337), common.HexToAddress("0xf12b5dd4ead5f743c6baa640b0216200e89b60da"))
+	data := ConfirmationsRequestTypedData(domain, 12, 3, 1, make([]byte, 32))
+
+	sig, err := SignTypedData(key, data)
+	require.NoError(t, err)
+	require.NoError(t, ValidateTypedDataSignature(data, sig[:], addr))
+
+	otherDomain := NewPlasmaDomain(big.NewInt(1), domain.VerifyingContract)
+	require.Error(t, ValidateTypedDataSignature(ConfirmationsRequestTypedData(otherDomain, 12, 3, 1, make([]byte, 32)), sig[:], addr))
+	require.Error(t, ValidateTypedDataSignature(ConfirmationsRequestTypedData(domain, 12, 3, 0, make([]byte, 32)), sig[:], addr))
+}
+
+func TestSignatureVerifier(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	domain := NewPlasmaDomain(big.NewInt(1337), common.HexToAddress("0xf12b5dd4ead5f743c6baa640b0216200e89b60da"))
+
+	tx := chain.ZeroTransaction()
+	tx.Input0 = chain.NewInput(1, 0, 0, big.NewInt(0), addr)
+	tx.Output0 = chain.NewOutput(common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"), big.NewInt(90), big.NewInt(0))
+	tx.Fee = big.NewInt(10)
+	typed := TransactionTypedData(domain, tx)
+
+	typedSig, err := SignTypedData(key, typed)
+	require.NoError(t, err)
+	legacySig, err := Sign(key, tx.Input0.SignatureHash())
+	require.NoError(t, err)
+
+	strict := NewSignatureVerifier(domain, false)
+	require.NoError(t, strict.Validate(typed, tx.Input0.SignatureHash(), typedSig[:], addr))
+	require.Error(t, strict.Validate(typed, tx.Input0.SignatureHash(), legacySig[:], addr))
+
+	compatible := NewSignatureVerifier(domain, true)
+	require.NoError(t, compatible.Validate(typed, tx.Input0.SignatureHash(), typedSig[:], addr))
+	require.NoError(t, compatible.Validate(typed, tx.Input0.SignatureHash(), legacySig[:], addr))
+
+	// the typed signature covers the outputs, unlike the legacy input one
+	tx.Output0.Denom = big.NewInt(80)
+	tx.Fee = big.NewInt(20)
+	require.Error(t, strict.Validate(TransactionTypedData(domain, tx), tx.Input0.SignatureHash(), typedSig[:], addr))
+}
+
+func TestConfirmationTypedData(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	domain := NewPlasmaDomain(big.NewInt(1337), common.HexToAddress("0xf12b5dd4ead5f743c6baa640b0216200e89b60da"))
+	tx := chain.NewMultiTransaction(
+		[]*chain.Input{chain.NewInput(1, 0, 0, big.NewInt(0), addr)},
+		nil,
+		[]*chain.Output{chain.NewOutput(addr, big.NewInt(100), big.NewInt(0))},
+		big.NewInt(0),
+	)
+	root := make([]byte, 32)
+
+	sig, err := SignTypedData(key, ConfirmationTypedData(domain, tx, 2, 0, root))
+	require.NoError(t, err)
+	require.NoError(t, ValidateTypedDataSignature(ConfirmationTypedData(domain, tx, 2, 0, root), sig[:], addr))
+	require.Error(t, ValidateTypedDataSignature(ConfirmationTypedData(domain, tx, 2, 1, root), sig[:], addr))
+	require.Error(t, ValidateTypedDataSignature(TransactionTypedData(domain, tx), sig[:], addr))
+}
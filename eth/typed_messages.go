@@ -0,0 +1,145 @@
+package eth
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/kyokan/plasma/chain"
+)
+
+// confirmationsRequestTypes describe the request an output's owner signs to
+// fetch the confirmation signatures of the transaction that created it. The
+// nonce is issued by the node and can only be used once.
+var confirmationsRequestTypes = TypedDataTypes{
+	"ConfirmationsRequest": {
+		{Name: "blockNumber", Type: "uint256"},
+		{Name: "transactionIndex", Type: "uint256"},
+		{Name: "outputIndex", Type: "uint256"},
+		{Name: "nonce", Type: "bytes32"},
+	},
+}
+
+func ConfirmationsRequestTypedData(domain TypedDataDomain, blockNumber uint64, transactionIndex uint32, outputIndex uint8, nonce []byte) *TypedData {
+	return NewTypedData(domain, confirmationsRequestTypes, "ConfirmationsRequest", map[string]interface{}{
+		"blockNumber":      strconv.FormatUint(blockNumber, 10),
+		"transactionIndex": strconv.FormatUint(uint64(transactionIndex), 10),
+		"outputIndex":      strconv.FormatUint(uint64(outputIndex), 10),
+		"nonce":            hexutil.Encode(nonce),
+	})
+}
+
+// ownConfirmationsRequestTypes describe the request the owner of an output
+// signs to fetch the confirmation signatures it sent for the transaction that
+// spent it. The request names that transaction's signature hash, so it can't
+// be used for another one, and expires at expiresAt, a Unix timestamp.
+var ownConfirmationsRequestTypes = TypedDataTypes{
+	"OwnConfirmationsRequest": {
+		{Name: "blockNumber", Type: "uint256"},
+		{Name: "transactionIndex", Type: "uint256"},
+		{Name: "inputIndex", Type: "uint256"},
+		{Name: "transactionHash", Type: "bytes32"},
+		{Name: "expiresAt", Type: "uint256"},
+	},
+}
+
+func OwnConfirmationsRequestTypedData(domain TypedDataDomain, blockNumber uint64, transactionIndex uint32, inputIndex uint8, transactionHash []byte, expiresAt uint64) *TypedData {
+	return NewTypedData(domain, ownConfirmationsRequestTypes, "OwnConfirmationsRequest", map[string]interface{}{
+		"blockNumber":      strconv.FormatUint(blockNumber, 10),
+		"transactionIndex": strconv.FormatUint(uint64(transactionIndex), 10),
+		"inputIndex":       strconv.FormatUint(uint64(inputIndex), 10),
+		"transactionHash":  hexutil.Encode(transactionHash),
+		"expiresAt":        strconv.FormatUint(expiresAt, 10),
+	})
+}
+
+// transactionTypes describe a plasma transaction. Legacy transactions always
+// have two inputs and two outputs, which are all zero if unused. The owners
+// of inputs are left out since they are looked up from the outputs spent.
+var transactionTypes = TypedDataTypes{
+	"Transaction": {
+		{Name: "version", Type: "uint256"},
+		{Name: "inputs", Type: "Input[]"},
+		{Name: "outputs", Type: "Output[]"},
+		{Name: "fee", Type: "uint256"},
+	},
+	"Input": {
+		{Name: "blockNumber", Type: "uint256"},
+		{Name: "transactionIndex", Type: "uint256"},
+		{Name: "outputIndex", Type: "uint256"},
+		{Name: "depositNonce", Type: "uint256"},
+	},
+	"Output": {
+		{Name: "owner", Type: "address"},
+		{Name: "amount", Type: "uint256"},
+	},
+}
+
+// confirmationTypes describe the confirmation an input's owner signs once
+// the transaction has been included in a block.
+var confirmationTypes = TypedDataTypes{
+	"Confirmation": {
+		{Name: "transaction", Type: "Transaction"},
+		{Name: "blockNumber", Type: "uint256"},
+		{Name: "transactionIndex", Type: "uint256"},
+		{Name: "merkleRoot", Type: "bytes32"},
+	},
+}
+
+// TransactionTypedData is signed by every input of tx. Legacy transactions
+// use it for both their input and their confirm signatures.
+func TransactionTypedData(domain TypedDataDomain, tx *chain.Transaction) *TypedData {
+	return NewTypedData(domain, transactionTypes, "Transaction", transactionMessage(tx))
+}
+
+// ConfirmationTypedData is signed by every input of tx once it has been
+// included at blockNumber and transactionIndex in a block with merkleRoot.
+func ConfirmationTypedData(domain TypedDataDomain, tx *chain.Transaction, blockNumber uint64, transactionIndex uint32, merkleRoot []byte) *TypedData {
+	types := TypedDataTypes{}
+	for name, fields := range transactionTypes {
+		types[name] = fields
+	}
+	for name, fields := range confirmationTypes {
+		types[name] = fields
+	}
+	return NewTypedData(domain, types, "Confirmation", map[string]interface{}{
+		"transaction":      transactionMessage(tx),
+		"blockNumber":      strconv.FormatUint(blockNumber, 10),
+		"transactionIndex": strconv.FormatUint(uint64(transactionIndex), 10),
+		"merkleRoot":       hexutil.Encode(merkleRoot),
+	})
+}
+
+func transactionMessage(tx *chain.Transaction) map[string]interface{} {
+	inputs := make([]interface{}, 0)
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		inputs = append(inputs, map[string]interface{}{
+			"blockNumber":      strconv.FormatUint(input.BlkNum, 10),
+			"transactionIndex": strconv.FormatUint(uint64(input.TxIdx), 10),
+			"outputIndex":      strconv.FormatUint(uint64(input.OutIdx), 10),
+			"depositNonce":     typedUint(input.DepositNonce),
+		})
+	}
+	outputs := make([]interface{}, 0)
+	for i := uint8(0); i < tx.NumOutputs(); i++ {
+		output := tx.OutputAt(i)
+		outputs = append(outputs, map[string]interface{}{
+			"owner":  output.Owner.Hex(),
+			"amount": typedUint(output.Denom),
+		})
+	}
+	return map[string]interface{}{
+		"version": strconv.FormatUint(uint64(tx.Version), 10),
+		"inputs":  inputs,
+		"outputs": outputs,
+		"fee":     typedUint(tx.Fee),
+	}
+}
+
+func typedUint(n *big.Int) string {
+	if n == nil {
+		return "0"
+	}
+	return n.Text(10)
+}
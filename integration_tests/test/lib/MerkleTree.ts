@@ -1,49 +1,69 @@
 import {tmSHA256} from './hash';
 
-export default class MerkleTree {
-  private leaves: Buffer[] = [];
+// TREE_DEPTH matches merkle.TreeDepth in the Go node and the depth of the
+// proofs the rootchain contract verifies.
+export const TREE_DEPTH = 16;
 
-  public addItem (leaf: Buffer) {
-    this.leaves.push(leaf);
-  }
+export const MAX_LEAVES = 1 << TREE_DEPTH;
 
-  public generateProofAndRoot (index: number): { root: Buffer, proof: Buffer } {
-    const [root, proof] = this.doProofAndRoot(this.leaves, index);
-    return {
-      root,
-      proof,
-    };
-  }
+const EMPTY_HASHES: Buffer[] = [Buffer.alloc(32)];
+for (let h = 1; h <= TREE_DEPTH; h++) {
+  EMPTY_HASHES.push(innerHash(EMPTY_HASHES[h - 1], EMPTY_HASHES[h - 1]));
+}
 
-  private doProofAndRoot (leaves: Buffer[], index: number): [Buffer, Buffer] {
-    if (leaves.length === 0) {
-      return [Buffer.from(''), Buffer.from('')];
-    }
+function innerHash (left: Buffer, right: Buffer): Buffer {
+  const prefix = Buffer.from([0x20]);
+  return tmSHA256([prefix, left, prefix, right]);
+}
 
-    if (leaves.length === 1) {
-      return [leaves[0], Buffer.from('')];
+// MerkleTree is a fixed-depth tree whose leaves are filled from the left.
+// Everything to the right of the last leaf hashes to an empty subtree.
+export default class MerkleTree {
+  private levels: Buffer[][] = [];
+
+  constructor () {
+    for (let h = 0; h <= TREE_DEPTH; h++) {
+      this.levels.push([]);
     }
+  }
 
-    let pivot = Math.floor((leaves.length + 1) / 2);
-    let proof = Buffer.from('');
-    let left, right;
+  public addItem (leaf: Buffer) {
+    if (leaf.length !== 32) {
+      throw new Error('leaves must be 32 bytes');
+    }
+    if (this.levels[0].length === MAX_LEAVES) {
+      throw new Error('tree is full');
+    }
 
-    if (index < pivot) {
-      left = this.doProofAndRoot(leaves.slice(0, pivot), index);
-      right = this.doProofAndRoot(leaves.slice(pivot), -1);
+    let idx = this.levels[0].length;
+    this.levels[0].push(leaf);
+    for (let h = 0; h < TREE_DEPTH; h++) {
+      const parent = Math.floor(idx / 2);
+      this.levels[h + 1][parent] = innerHash(this.node(h, parent * 2), this.node(h, parent * 2 + 1));
+      idx = parent;
+    }
+  }
 
-      if (index >= 0) {
-        proof = Buffer.concat([left[1], right[0]]);
-      }
-    } else {
-      left = this.doProofAndRoot(leaves.slice(0, pivot), -1);
-      right = this.doProofAndRoot(leaves.slice(pivot), index - pivot);
+  public generateProofAndRoot (index: number): { root: Buffer, proof: Buffer } {
+    if (index < 0 || index >= this.levels[0].length) {
+      throw new Error('leaf index out of range');
+    }
 
-      if (index >= 0) {
-        proof = Buffer.concat([right[1], left[0]]);
-      }
+    const siblings: Buffer[] = [];
+    for (let h = 0; h < TREE_DEPTH; h++) {
+      siblings.push(this.node(h, index ^ 1));
+      index = Math.floor(index / 2);
     }
+    return {
+      root: this.node(TREE_DEPTH, 0),
+      proof: Buffer.concat(siblings),
+    };
+  }
 
-    return [tmSHA256([left[0], right[0]]), proof];
+  private node (h: number, idx: number): Buffer {
+    if (idx < this.levels[h].length) {
+      return this.levels[h][idx];
+    }
+    return EMPTY_HASHES[h];
   }
-}
\ No newline at end of file
+}
@@ -0,0 +1,38 @@
+// +build gofuzz
+
+package merkle
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/kyokan/plasma/util"
+)
+
+// Fuzz is a go-fuzz entry point. The first four bytes of data pick the size
+// of a tree and the index of a leaf, the rest seeds the leaves. It panics if
+// the leaf's proof doesn't verify, or if a truncated proof does.
+func Fuzz(data []byte) int {
+	if len(data) < 4 {
+		return 0
+	}
+	n := int(binary.BigEndian.Uint16(data))%512 + 1
+	i := int(binary.BigEndian.Uint16(data[2:])) % n
+	seed := data[4:]
+
+	leaves := make([]util.Hash, n)
+	for j := range leaves {
+		leaves[j] = util.Sha256(append([]byte(fmt.Sprintf("%d", j)), seed...))
+	}
+	root, proof, err := RootAndProof(leaves, i)
+	if err != nil {
+		panic(err)
+	}
+	if !VerifyProof(root, leaves[i], uint64(i), proof) {
+		panic("proof does not verify")
+	}
+	if VerifyProof(root, leaves[i], uint64(i), proof[:len(proof)-32]) {
+		panic("truncated proof verifies")
+	}
+	return 1
+}
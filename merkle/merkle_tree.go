@@ -1,74 +1,157 @@
 package merkle
 
 import (
-	"encoding/binary"
-	"github.com/kyokan/plasma/util"
 	"bytes"
-		"math"
-		)
-
-func getMerkleRoot(hasher util.Hasher, leaves []util.RLPHashable) ([]byte) {
-	switch len(leaves) {
-	case 0:
-		return nil
-	case 1:
-		return leaves[0].RLPHash(hasher)
-	default:
-		mid := (1 + len(leaves)) / 2
-		lhsRLP := getMerkleRoot(hasher, leaves[:mid])
-		rhsRLP := getMerkleRoot(hasher, leaves[mid:])
-		return hasher(encodeLength(lhsRLP, rhsRLP))
+	"errors"
+
+	"github.com/kyokan/plasma/util"
+)
+
+// TreeDepth is the depth of every block's transaction tree, matching the
+// proofs the rootchain contract verifies.
+const TreeDepth = 16
+
+// MaxLeaves is the number of leaves in a tree of depth TreeDepth.
+const MaxLeaves = 1 << TreeDepth
+
+// ProofLength is the length in bytes of every proof.
+const ProofLength = TreeDepth * 32
+
+// emptyHashes[h] is the root of an empty subtree of height h. Empty leaves
+// are 32 zero bytes.
+var emptyHashes [TreeDepth + 1]util.Hash
+
+func init() {
+	emptyHashes[0] = make([]byte, 32)
+	for h := 1; h <= TreeDepth; h++ {
+		emptyHashes[h] = innerHash(emptyHashes[h-1], emptyHashes[h-1])
 	}
 }
 
-func encodeLength(lhs, rhs []byte) []byte {
-	var lbuf, rbuf [10]byte
-	lsize := binary.PutUvarint(lbuf[:], uint64(len(lhs)))
-	rsize := binary.PutUvarint(rbuf[:], uint64(len(rhs)))
-	left := append(lbuf[0:lsize], lhs...)
-	right := append(rbuf[0:rsize], rhs...)
-	return append(left, right...)
+// Tree is a fixed-depth Merkle tree whose leaves are filled from the left.
+// Only the nodes above appended leaves are stored; everything to their right
+// hashes to the cached empty subtree roots.
+type Tree struct {
+	levels [TreeDepth + 1][]util.Hash
+}
+
+func NewTree() *Tree {
+	return &Tree{}
+}
+
+// NewTreeFromHashables builds a tree whose leaves are the SHA-256 RLP hashes
+// of leaves.
+func NewTreeFromHashables(leaves []util.RLPHashable) (*Tree, error) {
+	tree := NewTree()
+	for _, leaf := range leaves {
+		if err := tree.Append(leaf.RLPHash(util.Sha256)); err != nil {
+			return nil, err
+		}
+	}
+	return tree, nil
 }
 
-func Root(leaves []util.RLPHashable) ([]byte) {
-	return getMerkleRoot(util.Sha256, leaves)
+func (t *Tree) Len() int {
+	return len(t.levels[0])
 }
 
-func RootAndProof(leaves []util.Hash, i int64) ([]byte, []byte) {
-	if len(leaves) == 0 {
-		return nil, nil
+// Append adds leaf to the right of the existing leaves and updates the nodes
+// on its path to the root.
+func (t *Tree) Append(leaf util.Hash) error {
+	if len(leaf) != 32 {
+		return errors.New("leaves must be 32 bytes")
+	}
+	if t.Len() == MaxLeaves {
+		return errors.New("tree is full")
 	}
 
-	if len(leaves) == 1 {
-		return leaves[0], nil
+	idx := len(t.levels[0])
+	t.levels[0] = append(t.levels[0], leaf)
+	for h := 0; h < TreeDepth; h++ {
+		parent := idx / 2
+		hash := innerHash(t.node(h, parent*2), t.node(h, parent*2+1))
+		if parent < len(t.levels[h+1]) {
+			t.levels[h+1][parent] = hash
+		} else {
+			t.levels[h+1] = append(t.levels[h+1], hash)
+		}
+		idx = parent
+	}
+	return nil
+}
+
+func (t *Tree) node(h int, idx int) util.Hash {
+	if idx < len(t.levels[h]) {
+		return t.levels[h][idx]
+	}
+	return emptyHashes[h]
+}
+
+func (t *Tree) Root() util.Hash {
+	return t.node(TreeDepth, 0)
+}
+
+// Proof returns the sibling hashes from leaf index up to the root.
+func (t *Tree) Proof(index int) ([]byte, error) {
+	if index < 0 || index >= t.Len() {
+		return nil, errors.New("leaf index out of range")
 	}
 
-	pivot := int64(math.Floor(float64(len(leaves) + 1) / 2))
-	var leftHash []byte
-	var rightHash []byte
-	var leftProof []byte
-	var rightProof []byte
 	var proof bytes.Buffer
+	for h := 0; h < TreeDepth; h++ {
+		proof.Write(t.node(h, index^1))
+		index /= 2
+	}
+	return proof.Bytes(), nil
+}
 
-	if i < pivot {
-		leftHash, leftProof = RootAndProof(leaves[0:pivot], i)
-		rightHash, rightProof = RootAndProof(leaves[pivot:], -1)
+// Root returns the root of the tree built from the SHA-256 RLP hashes of
+// leaves. It panics if there are more than MaxLeaves leaves, which block
+// production never allows.
+func Root(leaves []util.RLPHashable) []byte {
+	tree, err := NewTreeFromHashables(leaves)
+	if err != nil {
+		panic(err)
+	}
+	return tree.Root()
+}
 
-		if i >= 0 {
-			proof.Write(leftProof)
-			proof.Write(rightHash)
+// RootAndProof returns the root of the tree built from leaves and the proof
+// for leaf i.
+func RootAndProof(leaves []util.Hash, i int) ([]byte, []byte, error) {
+	tree := NewTree()
+	for _, leaf := range leaves {
+		if err := tree.Append(leaf); err != nil {
+			return nil, nil, err
 		}
-	} else {
-		leftHash, leftProof = RootAndProof(leaves[0:pivot], -1)
-		rightHash, rightProof = RootAndProof(leaves[pivot:], i - pivot)
+	}
+
+	proof, err := tree.Proof(i)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree.Root(), proof, nil
+}
+
+// VerifyProof checks that leaf is leaf number index of the tree with the given
+// root, the same way the rootchain contract does.
+func VerifyProof(root util.Hash, leaf util.Hash, index uint64, proof []byte) bool {
+	if len(leaf) != 32 || len(proof) != ProofLength || index >= MaxLeaves {
+		return false
+	}
 
-		if i >= 0 {
-			proof.Write(rightProof)
-			proof.Write(leftHash)
+	computed := leaf
+	for i := 0; i < len(proof); i += 32 {
+		sibling := proof[i : i+32]
+		if index%2 == 0 {
+			computed = innerHash(computed, sibling)
+		} else {
+			computed = innerHash(sibling, computed)
 		}
+		index /= 2
 	}
 
-	return innerHash(leftHash, rightHash), proof.Bytes()
+	return bytes.Equal(computed, root)
 }
 
 func innerHash(left util.Hash, right util.Hash) util.Hash {
@@ -85,4 +168,4 @@ func innerHash(left util.Hash, right util.Hash) util.Hash {
 	buf.WriteByte(0x20)
 	buf.Write(right)
 	return util.Sha256(buf.Bytes())
-}
\ No newline at end of file
+}
@@ -1,42 +1,174 @@
 package merkle
 
 import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"testing"
-		"github.com/kyokan/plasma/util"
-		"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/kyokan/plasma/util"
 	"github.com/stretchr/testify/require"
 )
 
+func TestEmptyRoot(t *testing.T) {
+	require.Equal(t, "0x4e2bc42d661d5e6d01e0c563ba66ad0a8b97214f1a3561c7259c69b55835d6b0", hexutil.Encode(NewTree().Root()))
+	require.Equal(t, "0x4e2bc42d661d5e6d01e0c563ba66ad0a8b97214f1a3561c7259c69b55835d6b0", hexutil.Encode(Root(nil)))
+}
+
 func TestRootAndProof3LeavesIdx0(t *testing.T) {
-	leaf1 := util.Keccak256([]byte("inputSeed1"))
-	leaf2 := util.Keccak256([]byte("inputSeed2"))
-	leaf3 := util.Keccak256([]byte("inputSeed3"))
+	leaf1 := util.Sha256([]byte("inputSeed1"))
+	leaf2 := util.Sha256([]byte("inputSeed2"))
+	leaf3 := util.Sha256([]byte("inputSeed3"))
 
-	root, proof := RootAndProof([]util.Hash{
+	root, proof, err := RootAndProof([]util.Hash{
 		leaf1,
 		leaf2,
 		leaf3,
 	}, 0)
+	require.NoError(t, err)
 
-	require.Equal(t, hexutil.Encode(root), "0xf323e1445d86cafa60e816c46696f2bd260f795fd76325b9c4aea430b668416a")
-	require.Equal(t, hexutil.Encode(proof), "0xda2a5c2c3f35f3257026c91ae38f612664abd5c8a59135febb7ce80609013a261d7a0fdf5b4e222d2b55fcdb7f24bbda511e961bd71e54ca8204bb33a7cbd9d9")
+	require.Equal(t, hexutil.Encode(root), "0x7dab78b64a13e37716f2d78df4e45d882eb5708c87057573b18ccae449e86a5c")
+	require.Equal(t, hexutil.Encode(proof), "0x466a43c97270a742e8f6bbe601e232fee1dc2cdd518a12d5292e0b1eb262b66ff881be025c9462f5d169535846b5cdb615429caf970c5c0be4e4c16fab6be986b82b9774e5831b27ea2ce2dd9f227d3d77e9215e7f1366ffb85bc6d78de92ca8618dedd13ec6633ddb1c10829f3caf618fe39f6e6b0643c49f050b2817c9496bf1dacfdeda166619c76c283d84353c0f77d3066bb6e9f65332822a92eafd43d575ae77bb5455f2851666ff693e71689b94566204bdbd6c85dd91399fac0e5c3f72aff447224f0b8d5e05a5f02614245d789338af0ca5bb741bb9d3e3bb51e3d5488452119815a80374e522583bcb803018af5682d6be8d697585a03d60730e2a7fcb5e5997e7cce5adcbf81a3134439f1c8542d6d7862c9e0dbe671a19cb028b9a70aa79f1a3ec7d9392c09500d47b2c0e85a7c9a7f7502274f50e685f35d8010a777382b4494c103c7321bdd9d07498c3d7fb38523e741625a990290510cb4d3d3a15527054d6a1742c515f6d08a8323f44ad444a1785164fbe9bdfa473c324eeed9471c5523116e43da7a0651a6d6724b1911bbb8c7cf298fa5b806ae0fcdaa9f509a86892965de96de07b7db6af795832f80be54a447df6e89e70c35462f6763700207e4bd9b0df9b08bd50aa06529dbce3349ff27089e7bbdbfe18e0bb581806fa0ea7567a9618cc246721c069bce98dc46d9ac27b32aa8e82be6e8d4311")
 }
 
 func TestRootAndProof5LeavesIdx3(t *testing.T) {
-	leaf1 := util.Keccak256([]byte("inputSeed1"))
-	leaf2 := util.Keccak256([]byte("inputSeed2"))
-	leaf3 := util.Keccak256([]byte("inputSeed3"))
-	leaf4 := util.Keccak256([]byte("inputSeed4"))
-	leaf5 := util.Keccak256([]byte("inputSeed5"))
+	leaf1 := util.Sha256([]byte("inputSeed1"))
+	leaf2 := util.Sha256([]byte("inputSeed2"))
+	leaf3 := util.Sha256([]byte("inputSeed3"))
+	leaf4 := util.Sha256([]byte("inputSeed4"))
+	leaf5 := util.Sha256([]byte("inputSeed5"))
 
-	root, proof := RootAndProof([]util.Hash{
+	root, proof, err := RootAndProof([]util.Hash{
 		leaf1,
 		leaf2,
 		leaf3,
 		leaf4,
 		leaf5,
 	}, 3)
+	require.NoError(t, err)
+
+	require.Equal(t, hexutil.Encode(root), "0xb323db7d681ead3cdca84ee4eb9933784c41d86e39a32dc13fc862ec6262427d")
+	require.Equal(t, hexutil.Encode(proof), "0x7a7398acafcb4a76476e3a4a9cec5252ab7f402d4aa0b4f578723f1f15f5da56e9767fc0c868d6f5e0f11251018e026fc86b8692aca7e984117d96c893ede60f58e6aa4c1c54664a1c4293d29bf56e84dfdeb750f1a874e50981b95a5f7dda3e618dedd13ec6633ddb1c10829f3caf618fe39f6e6b0643c49f050b2817c9496bf1dacfdeda166619c76c283d84353c0f77d3066bb6e9f65332822a92eafd43d575ae77bb5455f2851666ff693e71689b94566204bdbd6c85dd91399fac0e5c3f72aff447224f0b8d5e05a5f02614245d789338af0ca5bb741bb9d3e3bb51e3d5488452119815a80374e522583bcb803018af5682d6be8d697585a03d60730e2a7fcb5e5997e7cce5adcbf81a3134439f1c8542d6d7862c9e0dbe671a19cb028b9a70aa79f1a3ec7d9392c09500d47b2c0e85a7c9a7f7502274f50e685f35d8010a777382b4494c103c7321bdd9d07498c3d7fb38523e741625a990290510cb4d3d3a15527054d6a1742c515f6d08a8323f44ad444a1785164fbe9bdfa473c324eeed9471c5523116e43da7a0651a6d6724b1911bbb8c7cf298fa5b806ae0fcdaa9f509a86892965de96de07b7db6af795832f80be54a447df6e89e70c35462f6763700207e4bd9b0df9b08bd50aa06529dbce3349ff27089e7bbdbfe18e0bb581806fa0ea7567a9618cc246721c069bce98dc46d9ac27b32aa8e82be6e8d4311")
+}
+
+func TestRootAndProofOutOfRange(t *testing.T) {
+	_, _, err := RootAndProof([]util.Hash{util.Sha256([]byte("inputSeed1"))}, 1)
+	require.Error(t, err)
+}
+
+func TestVerifyProof(t *testing.T) {
+	for _, size := range []int{1, 2, 3, 5, 8, 13} {
+		tree := NewTree()
+		var leaves []util.Hash
+		for i := 0; i < size; i++ {
+			leaf := util.Sha256([]byte(fmt.Sprintf("inputSeed%d", i)))
+			leaves = append(leaves, leaf)
+			require.NoError(t, tree.Append(leaf))
+		}
+
+		for i := range leaves {
+			root, proof, err := RootAndProof(leaves, i)
+			require.NoError(t, err)
+			require.Equal(t, []byte(tree.Root()), root)
+			require.Len(t, proof, ProofLength)
+			require.True(t, VerifyProof(root, leaves[i], uint64(i), proof), "size %d, index %d", size, i)
+			if size > 1 {
+				require.False(t, VerifyProof(root, leaves[(i+1)%size], uint64(i), proof), "size %d, index %d", size, i)
+			}
+		}
+	}
+}
+
+func TestGoldenProofs(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/proofs.json")
+	require.NoError(t, err)
+	var golden struct {
+		EmptyRoot string `json:"emptyRoot"`
+		Trees     []struct {
+			Leaves []string `json:"leaves"`
+			Root   string   `json:"root"`
+			Proofs []string `json:"proofs"`
+		} `json:"trees"`
+	}
+	require.NoError(t, json.Unmarshal(data, &golden))
+	require.Equal(t, golden.EmptyRoot, hex.EncodeToString(NewTree().Root()))
+
+	for _, tree := range golden.Trees {
+		var leaves []util.Hash
+		for _, leaf := range tree.Leaves {
+			b, err := hex.DecodeString(leaf)
+			require.NoError(t, err)
+			leaves = append(leaves, b)
+		}
+
+		for i := range leaves {
+			root, proof, err := RootAndProof(leaves, i)
+			require.NoError(t, err)
+			require.Equal(t, tree.Root, hex.EncodeToString(root))
+			require.Equal(t, tree.Proofs[i], hex.EncodeToString(proof))
+			require.True(t, VerifyProof(root, leaves[i], uint64(i), proof))
+		}
+	}
+}
+
+func TestProofsAtManySizes(t *testing.T) {
+	sizes := []int{63, 64, 65, 127, 128, 129, 255, 256, 257}
+	for size := 1; size <= 32; size++ {
+		sizes = append(sizes, size)
+	}
+
+	for _, size := range sizes {
+		leaves := testLeaves(size)
+		for i := range leaves {
+			root, proof, err := RootAndProof(leaves, i)
+			require.NoError(t, err)
+			require.True(t, VerifyProof(root, leaves[i], uint64(i), proof), "size %d, index %d", size, i)
+			require.False(t, VerifyProof(root, leaves[i], uint64(i^1), proof), "size %d, index %d", size, i)
+
+			tampered := append([]byte{}, proof...)
+			tampered[i%ProofLength] ^= 0x01
+			require.False(t, VerifyProof(root, leaves[i], uint64(i), tampered), "size %d, index %d", size, i)
+		}
+	}
+}
+
+func TestAppendMatchesRebuild(t *testing.T) {
+	leaves := testLeaves(300)
+	tree := NewTree()
+	for i, leaf := range leaves {
+		require.NoError(t, tree.Append(leaf))
+		rebuilt, _, err := RootAndProof(leaves[:i+1], i)
+		require.NoError(t, err)
+		require.Equal(t, rebuilt, []byte(tree.Root()))
+	}
+}
+
+func TestFullTree(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping full tree in short mode")
+	}
+
+	tree := NewTree()
+	leaves := testLeaves(MaxLeaves)
+	for _, leaf := range leaves {
+		require.NoError(t, tree.Append(leaf))
+	}
+	require.Error(t, tree.Append(leaves[0]))
+
+	for _, i := range []int{0, 1, MaxLeaves / 2, MaxLeaves - 1} {
+		proof, err := tree.Proof(i)
+		require.NoError(t, err)
+		require.True(t, VerifyProof(tree.Root(), leaves[i], uint64(i), proof))
+	}
+	require.False(t, VerifyProof(tree.Root(), leaves[0], MaxLeaves, make([]byte, ProofLength)))
+}
 
-	require.Equal(t, hexutil.Encode(root), "0xffbaed089612123586b4f74e04cb0a02e300f33551182eaff703f4feda8927f4")
-	require.Equal(t, hexutil.Encode(proof), "0xb3ca086bdc6d4011c11b58e1790c6b50800069a25eeace6ba73c580694e7e2c6f323e1445d86cafa60e816c46696f2bd260f795fd76325b9c4aea430b668416a")
+func testLeaves(n int) []util.Hash {
+	leaves := make([]util.Hash, n)
+	for i := range leaves {
+		leaves[i] = util.Sha256([]byte(fmt.Sprintf("inputSeed%d", i)))
+	}
+	return leaves
 }
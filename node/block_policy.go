@@ -0,0 +1,80 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MaxBlockTxCount is the largest number of transactions that fit in a block.
+// Transaction index 65535 is reserved for the block's fee output.
+const MaxBlockTxCount = 65534
+
+const blockPollInterval = 100 * time.Millisecond
+
+// BlockPolicy decides when PlasmaNode packages pending spends into a block.
+type BlockPolicy struct {
+	// MinBlockInterval is the shortest time allowed between two blocks.
+	MinBlockInterval time.Duration
+	// MaxBlockInterval is the longest time pending spends will wait for
+	// MinTxCount to be reached before being packaged anyway. Zero disables it.
+	MaxBlockInterval time.Duration
+	// MinTxCount is the number of pending spends that triggers a block.
+	MinTxCount int
+	// MaxTxCount caps the number of spends in a single block.
+	MaxTxCount int
+	// EmptyBlockInterval, when non-zero, packages an empty block if no other
+	// block has been created for this long.
+	EmptyBlockInterval time.Duration
+}
+
+func DefaultBlockPolicy() BlockPolicy {
+	return BlockPolicy{
+		MinBlockInterval: blockPollInterval,
+		MaxBlockInterval: time.Second,
+		MinTxCount:       1,
+		MaxTxCount:       MaxBlockTxCount,
+	}
+}
+
+func (p BlockPolicy) Validate() error {
+	if p.MinBlockInterval < 0 || p.MaxBlockInterval < 0 || p.EmptyBlockInterval < 0 {
+		return errors.New("block intervals must not be negative")
+	}
+	if p.MaxBlockInterval != 0 && p.MaxBlockInterval < p.MinBlockInterval {
+		return errors.New("maximum block interval must not be less than the minimum block interval")
+	}
+	if p.MinTxCount < 1 {
+		return errors.New("minimum transaction count must be at least 1")
+	}
+	if p.MaxTxCount < 1 || p.MaxTxCount > MaxBlockTxCount {
+		return errors.New(fmt.Sprintf("maximum transaction count must be between 1 and %d", MaxBlockTxCount))
+	}
+	if p.MinTxCount > p.MaxTxCount {
+		return errors.New("minimum transaction count must not exceed the maximum transaction count")
+	}
+	return nil
+}
+
+// ShouldPackage reports whether a block should be created given the number of
+// pending spends and the time elapsed since the last block.
+func (p BlockPolicy) ShouldPackage(pending int, sinceLast time.Duration) bool {
+	if sinceLast < p.MinBlockInterval {
+		return false
+	}
+	if pending >= p.MinTxCount {
+		return true
+	}
+	if pending > 0 {
+		return p.MaxBlockInterval != 0 && sinceLast >= p.MaxBlockInterval
+	}
+	return p.EmptyBlockInterval != 0 && sinceLast >= p.EmptyBlockInterval
+}
+
+func (p BlockPolicy) pollInterval() time.Duration {
+	if p.MinBlockInterval > 0 && p.MinBlockInterval < blockPollInterval {
+		return p.MinBlockInterval
+	}
+	return blockPollInterval
+}
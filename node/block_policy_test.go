@@ -0,0 +1,72 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockPolicyShouldPackage(t *testing.T) {
+	policy := BlockPolicy{
+		MinBlockInterval:   time.Second,
+		MaxBlockInterval:   10 * time.Second,
+		MinTxCount:         5,
+		MaxTxCount:         100,
+		EmptyBlockInterval: time.Minute,
+	}
+
+	tests := []struct {
+		name      string
+		policy    BlockPolicy
+		pending   int
+		sinceLast time.Duration
+		expected  bool
+	}{
+		{"before minimum interval", policy, 100, 500 * time.Millisecond, false},
+		{"enough spends", policy, 5, time.Second, true},
+		{"too few spends", policy, 4, 5 * time.Second, false},
+		{"too few spends past maximum interval", policy, 1, 10 * time.Second, true},
+		{"no spends", policy, 0, 30 * time.Second, false},
+		{"no spends past empty block interval", policy, 0, time.Minute, true},
+		{"no maximum interval", BlockPolicy{MinTxCount: 5, MaxTxCount: 100}, 1, time.Hour, false},
+		{"no empty blocks", BlockPolicy{MinTxCount: 1, MaxTxCount: 100}, 0, time.Hour, false},
+		{"default policy", DefaultBlockPolicy(), 1, blockPollInterval, true},
+		{"default policy before minimum interval", DefaultBlockPolicy(), 1, blockPollInterval - 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.policy.ShouldPackage(tt.pending, tt.sinceLast))
+		})
+	}
+}
+
+func TestBlockPolicyValidate(t *testing.T) {
+	require.NoError(t, DefaultBlockPolicy().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(p *BlockPolicy)
+	}{
+		{"negative interval", func(p *BlockPolicy) { p.EmptyBlockInterval = -1 }},
+		{"maximum below minimum interval", func(p *BlockPolicy) { p.MaxBlockInterval = p.MinBlockInterval - 1 }},
+		{"no minimum transaction count", func(p *BlockPolicy) { p.MinTxCount = 0 }},
+		{"maximum transaction count too large", func(p *BlockPolicy) { p.MaxTxCount = MaxBlockTxCount + 1 }},
+		{"minimum above maximum transaction count", func(p *BlockPolicy) { p.MinTxCount = p.MaxTxCount + 1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := DefaultBlockPolicy()
+			tt.modify(&policy)
+			require.Error(t, policy.Validate())
+		})
+	}
+}
+
+func TestBlockPolicyPollInterval(t *testing.T) {
+	require.Equal(t, blockPollInterval, BlockPolicy{}.pollInterval())
+	require.Equal(t, 10*time.Millisecond, BlockPolicy{MinBlockInterval: 10 * time.Millisecond}.pollInterval())
+	require.Equal(t, blockPollInterval, BlockPolicy{MinBlockInterval: time.Second}.pollInterval())
+}
@@ -8,7 +8,6 @@ import (
 	log2 "github.com/kyokan/plasma/log"
 	"github.com/sirupsen/logrus"
 	"github.com/kyokan/plasma/util"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 var bsLogger = log2.ForSubsystem("BlockSubmitter")
@@ -42,10 +41,16 @@ func NewBlockSubmitter(client eth.Client, ps db.PlasmaStorage) *BlockSubmitter {
 
 func (s *BlockSubmitter) Start() error {
 	lastSubmitted, err := s.ps.LastSubmittedBlock()
-	if err == leveldb.ErrNotFound {
+	if db.IsNotFound(err) {
 		return nil
 	}
+	if err != nil {
+		return err
+	}
 	latest, err := s.ps.LatestBlock()
+	if err != nil {
+		return err
+	}
 	if latest == nil {
 		return nil
 	}
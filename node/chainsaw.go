@@ -151,25 +151,55 @@ func (c *Chainsaw) processTxExits(wg *sync.WaitGroup, head uint64) {
 			return
 		}
 
-		txsInChallengeBlock, err := c.storage.FindTransactionsByBlockNum(challengingTx.Transaction.BlkNum)
+		challengeBlkNum := challengingTx.Transaction.BlkNum
+		challengeTxIdx := challengingTx.Transaction.TxIdx
+		inputIdx, ok := spendingInput(&challengingTx.Transaction, blkNum, txIdx, outIdx)
+		if !ok {
+			logFields.WithFields(evFields).Error("double spending transaction does not spend the exiting output")
+			continue
+		}
+		txsInChallengeBlock, err := c.storage.FindTransactionsByBlockNum(challengeBlkNum)
 		if err != nil {
 			log.WithError(logFields, err).WithFields(evFields).Error("failed to query transactions in block")
 			return
 		}
-		authSigs, err := c.storage.AuthSigsFor(blkNum, txIdx)
+		authSigs, err := c.storage.AuthSigsFor(challengeBlkNum, challengeTxIdx)
 		if err != nil {
 			log.WithError(logFields, err).WithFields(evFields).Error("failed to query authSigs")
 			return
 		}
+		if int(inputIdx) >= len(authSigs) {
+			logFields.WithFields(evFields).Error("no authSigs found")
+			return
+		}
 
-		// note: proof is expected to be nil for single-transaction proofs
-		proof := genTxMerkleProof(txsInChallengeBlock, txIdx)
+		proof, err := genTxMerkleProof(txsInChallengeBlock, challengeTxIdx)
+		if err != nil {
+			log.WithError(logFields, err).WithFields(evFields).Error("failed to generate merkle proof")
+			return
+		}
 
-		_, err = c.client.Challenge(exitingTx, outIdx, big.NewInt(0), challengingTx, proof, authSigs[0])
+		_, err = c.client.Challenge(exitingTx, outIdx, big.NewInt(0), challengingTx, proof, authSigs[inputIdx])
 		if err != nil {
-			log.WithError(logFields, err).WithFields(evFields).Error("failed to broadcast exit challenge")
+			log.WithError(logFields, err).WithFields(evFields).WithFields(logrus.Fields{
+				"challengeBlockNumber":      challengeBlkNum,
+				"challengeTransactionIndex": challengeTxIdx,
+				"challengeVersion":          challengingTx.Transaction.Version,
+			}).Error("failed to broadcast exit challenge")
+		}
+	}
+}
+
+// spendingInput returns the index of the input of tx that spends the given
+// output.
+func spendingInput(tx *chain.Transaction, blkNum uint64, txIdx uint32, outIdx uint8) (uint8, bool) {
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if !input.IsZeroInput() && input.BlkNum == blkNum && input.TxIdx == txIdx && input.OutIdx == outIdx {
+			return i, true
 		}
 	}
+	return 0, false
 }
 
 func (c *Chainsaw) processDeposits(wg *sync.WaitGroup, head uint64) {
@@ -243,11 +273,11 @@ func (c *Chainsaw) processDeposits(wg *sync.WaitGroup, head uint64) {
 	logFields.WithFields(logrus.Fields{"depositCount": len(events)}).Info("added deposits to mempool")
 }
 
-func genTxMerkleProof(txs []chain.ConfirmedTransaction, txIdx uint32) []byte {
+func genTxMerkleProof(txs []chain.ConfirmedTransaction, txIdx uint32) ([]byte, error) {
 	var hashes []util.Hash
 	for _, tx := range txs {
 		hashes = append(hashes, tx.RLPHash(util.Sha256))
 	}
-	_, proof := merkle.RootAndProof(hashes, int64(txIdx))
-	return proof
+	_, proof, err := merkle.RootAndProof(hashes, int(txIdx))
+	return proof, err
 }
@@ -0,0 +1,150 @@
+package node
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ConfirmationChallengeTTL is how long a challenge issued for
+// GetConfirmations can be answered.
+const ConfirmationChallengeTTL = 30 * time.Second
+
+// MaxConfirmationChallenges bounds how many unanswered challenges are kept,
+// so that requesting them can't exhaust the node's memory. Once it is
+// reached, challenges of the client with the most outstanding are dropped to
+// make room.
+const MaxConfirmationChallenges = 10000
+
+// MaxConfirmationChallengesPerClient bounds how many unanswered challenges
+// one client can hold, so that a single client can't crowd out the others.
+const MaxConfirmationChallengesPerClient = 64
+
+type confirmationChallenge struct {
+	client           string
+	blockNumber      uint64
+	transactionIndex uint32
+	outIndex         uint8
+	expires          time.Time
+}
+
+// challengeCache holds the nonces handed out to clients that want to read
+// confirmation signatures. Each nonce is tied to one output and can be
+// redeemed once.
+type challengeCache struct {
+	mtx        sync.Mutex
+	challenges map[string]*confirmationChallenge
+	// clients holds the nonces issued to each client, oldest first.
+	clients   map[string][]string
+	ttl       time.Duration
+	max       int
+	maxClient int
+}
+
+func newChallengeCache(ttl time.Duration, max int, maxClient int) *challengeCache {
+	return &challengeCache{
+		challenges: make(map[string]*confirmationChallenge),
+		clients:    make(map[string][]string),
+		ttl:        ttl,
+		max:        max,
+		maxClient:  maxClient,
+	}
+}
+
+// issue returns a new 32 byte nonce for the given output, and when it
+// expires. client identifies the requester, for example by its IP address.
+// Clients holding too many unanswered challenges are refused, and if the
+// cache is full the busiest client's oldest challenge is dropped, so that
+// one client can't lock out the others.
+func (c *challengeCache) issue(client string, blockNumber uint64, transactionIndex uint32, outIndex uint8) ([]byte, time.Time, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := time.Now()
+	if len(c.clients[client]) >= c.maxClient || len(c.challenges) >= c.max {
+		c.expire(now)
+	}
+	if len(c.clients[client]) >= c.maxClient {
+		return nil, time.Time{}, NewError(ReasonTooManyChallenges, "too many outstanding challenges")
+	}
+	if len(c.challenges) >= c.max {
+		c.remove(c.clients[c.busiestClient()][0])
+	}
+
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, time.Time{}, err
+	}
+	key := hexutil.Encode(nonce)
+	challenge := &confirmationChallenge{
+		client:           client,
+		blockNumber:      blockNumber,
+		transactionIndex: transactionIndex,
+		outIndex:         outIndex,
+		expires:          now.Add(c.ttl),
+	}
+	c.challenges[key] = challenge
+	c.clients[client] = append(c.clients[client], key)
+	return nonce, challenge.expires, nil
+}
+
+// redeem removes the challenge for nonce, and reports whether it was issued
+// for the given output and has not expired.
+func (c *challengeCache) redeem(nonce []byte, blockNumber uint64, transactionIndex uint32, outIndex uint8) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	key := hexutil.Encode(nonce)
+	challenge, ok := c.challenges[key]
+	if !ok {
+		return false
+	}
+	c.remove(key)
+	return time.Now().Before(challenge.expires) &&
+		challenge.blockNumber == blockNumber &&
+		challenge.transactionIndex == transactionIndex &&
+		challenge.outIndex == outIndex
+}
+
+func (c *challengeCache) expire(now time.Time) {
+	for key, challenge := range c.challenges {
+		if !now.Before(challenge.expires) {
+			c.remove(key)
+		}
+	}
+}
+
+func (c *challengeCache) remove(key string) {
+	challenge, ok := c.challenges[key]
+	if !ok {
+		return
+	}
+	delete(c.challenges, key)
+
+	keys := c.clients[challenge.client]
+	for i, k := range keys {
+		if k == key {
+			keys = append(keys[:i], keys[i+1:]...)
+			break
+		}
+	}
+	if len(keys) == 0 {
+		delete(c.clients, challenge.client)
+	} else {
+		c.clients[challenge.client] = keys
+	}
+}
+
+func (c *challengeCache) busiestClient() string {
+	var busiest string
+	most := 0
+	for client, keys := range c.clients {
+		if len(keys) > most {
+			busiest = client
+			most = len(keys)
+		}
+	}
+	return busiest
+}
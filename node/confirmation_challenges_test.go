@@ -0,0 +1,76 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengeCacheRedeem(t *testing.T) {
+	c := newChallengeCache(time.Minute, 10, 10)
+	nonce, expires, err := c.issue("alice", 1, 2, 3)
+	require.NoError(t, err)
+	require.Len(t, nonce, 32)
+	require.True(t, expires.After(time.Now()))
+
+	require.False(t, c.redeem(nonce, 1, 2, 4))
+	// a failed redemption still uses up the nonce
+	require.False(t, c.redeem(nonce, 1, 2, 3))
+
+	nonce, _, err = c.issue("alice", 1, 2, 3)
+	require.NoError(t, err)
+	require.True(t, c.redeem(nonce, 1, 2, 3))
+	require.False(t, c.redeem(nonce, 1, 2, 3))
+	require.Empty(t, c.challenges)
+	require.Empty(t, c.clients)
+
+	expired := newChallengeCache(0, 10, 10)
+	nonce, _, err = expired.issue("alice", 1, 2, 3)
+	require.NoError(t, err)
+	require.False(t, expired.redeem(nonce, 1, 2, 3))
+}
+
+func TestChallengeCachePerClientLimit(t *testing.T) {
+	c := newChallengeCache(time.Minute, 10, 3)
+	var nonces [][]byte
+	for i := 0; i < 3; i++ {
+		nonce, _, err := c.issue("mallory", 1, 0, 0)
+		require.NoError(t, err)
+		nonces = append(nonces, nonce)
+	}
+
+	_, _, err := c.issue("mallory", 1, 0, 0)
+	require.Error(t, err)
+	require.Equal(t, ReasonTooManyChallenges, AsError(err).Reason)
+
+	// other clients are unaffected
+	_, _, err = c.issue("alice", 1, 0, 0)
+	require.NoError(t, err)
+
+	// answering a challenge makes room for another
+	require.True(t, c.redeem(nonces[0], 1, 0, 0))
+	_, _, err = c.issue("mallory", 1, 0, 0)
+	require.NoError(t, err)
+}
+
+func TestChallengeCacheFullDropsBusiestClient(t *testing.T) {
+	c := newChallengeCache(time.Minute, 4, 3)
+	var mallory [][]byte
+	for i := 0; i < 3; i++ {
+		nonce, _, err := c.issue("mallory", 1, 0, 0)
+		require.NoError(t, err)
+		mallory = append(mallory, nonce)
+	}
+	alice, _, err := c.issue("alice", 1, 0, 1)
+	require.NoError(t, err)
+
+	// the cache is full, so mallory's oldest challenge makes room for bob's
+	bob, _, err := c.issue("bob", 1, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, c.challenges, 4)
+	require.False(t, c.redeem(mallory[0], 1, 0, 0))
+	require.True(t, c.redeem(mallory[1], 1, 0, 0))
+	require.True(t, c.redeem(alice, 1, 0, 1))
+	require.True(t, c.redeem(bob, 1, 0, 2))
+}
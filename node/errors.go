@@ -0,0 +1,83 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/kyokan/plasma/db"
+	"github.com/pkg/errors"
+)
+
+// ErrorReason says what was wrong with a request, so that clients can handle
+// errors without parsing their messages.
+type ErrorReason string
+
+const (
+	ReasonNotFound           ErrorReason = "NOT_FOUND"
+	ReasonDoubleSpend        ErrorReason = "DOUBLE_SPEND"
+	ReasonInvalidSignature   ErrorReason = "INVALID_SIGNATURE"
+	ReasonInsufficientInputs ErrorReason = "INSUFFICIENT_INPUTS"
+	ReasonInvalidTransaction ErrorReason = "INVALID_TRANSACTION"
+	ReasonMempoolFull        ErrorReason = "MEMPOOL_FULL"
+	ReasonAlreadyConfirmed   ErrorReason = "ALREADY_CONFIRMED"
+	ReasonUnauthorized       ErrorReason = "UNAUTHORIZED"
+	ReasonInvalidChallenge   ErrorReason = "INVALID_CHALLENGE"
+	ReasonTooManyChallenges  ErrorReason = "TOO_MANY_CHALLENGES"
+	ReasonStaleRequest       ErrorReason = "STALE_REQUEST"
+	ReasonPruned             ErrorReason = "PRUNED"
+	ReasonInvalidHeight      ErrorReason = "INVALID_HEIGHT"
+)
+
+// NoInput is the Input of errors that are not about a single input.
+const NoInput = -1
+
+// Error is an error caused by a request rather than by the node.
+type Error struct {
+	Reason  ErrorReason
+	Message string
+	// Input is the index of the input the error is about, or NoInput.
+	Input int
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewError returns an error that is not about a single input.
+func NewError(reason ErrorReason, message string) *Error {
+	return &Error{
+		Reason:  reason,
+		Message: message,
+		Input:   NoInput,
+	}
+}
+
+// NewInputError returns an error about input, whose message is prefixed with
+// the input's index.
+func NewInputError(reason ErrorReason, input uint8, message string) *Error {
+	return &Error{
+		Reason:  reason,
+		Message: fmt.Sprintf("input %d %s", input, message),
+		Input:   int(input),
+	}
+}
+
+// AsError returns the Error err is or wraps, translating the db package's
+// typed errors into their reasons. It returns nil for errors that are the
+// node's own fault.
+func AsError(err error) *Error {
+	cause := errors.Cause(err)
+	if e, ok := cause.(*Error); ok {
+		return e
+	}
+	switch {
+	case db.IsInvalidHeight(cause):
+		return NewError(ReasonInvalidHeight, cause.Error())
+	case db.IsNotFound(cause):
+		return NewError(ReasonNotFound, cause.Error())
+	case db.IsPruned(cause):
+		return NewError(ReasonPruned, cause.Error())
+	case db.IsConflictingSpend(cause):
+		return NewError(ReasonDoubleSpend, cause.Error())
+	}
+	return nil
+}
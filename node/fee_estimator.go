@@ -0,0 +1,61 @@
+package node
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/kyokan/plasma/db"
+)
+
+// FeeEstimateBlocks is the number of recent blocks sampled by EstimateFee.
+const FeeEstimateBlocks = 10
+
+// FeeEstimate is the median fee paid by the spends in the most recent
+// blocks. Fee is zero when none of the sampled blocks contain spends.
+type FeeEstimate struct {
+	Fee                 *big.Int
+	SampledBlocks       uint64
+	SampledTransactions uint64
+}
+
+// EstimateFee samples the fees paid in the last FeeEstimateBlocks blocks.
+// Deposits never pay a fee, so they are left out.
+func EstimateFee(storage db.PlasmaStorage) (*FeeEstimate, error) {
+	estimate := &FeeEstimate{
+		Fee: big.NewInt(0),
+	}
+
+	latest, err := storage.LatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return estimate, nil
+	}
+
+	var fees []*big.Int
+	for blkNum := latest.Header.Number; blkNum > 0 && estimate.SampledBlocks < FeeEstimateBlocks; blkNum-- {
+		txs, err := storage.FindTransactionsByBlockNum(blkNum)
+		if err != nil {
+			return nil, err
+		}
+		estimate.SampledBlocks++
+
+		for _, tx := range txs {
+			if tx.Transaction.IsDeposit() || tx.Transaction.GetFee() == nil {
+				continue
+			}
+			fees = append(fees, tx.Transaction.GetFee())
+		}
+	}
+
+	estimate.SampledTransactions = uint64(len(fees))
+	if len(fees) == 0 {
+		return estimate, nil
+	}
+	sort.Slice(fees, func(i, j int) bool {
+		return fees[i].Cmp(fees[j]) < 0
+	})
+	estimate.Fee = new(big.Int).Set(fees[len(fees)/2])
+	return estimate, nil
+}
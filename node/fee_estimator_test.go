@@ -0,0 +1,75 @@
+package node
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/db"
+	"github.com/stretchr/testify/require"
+)
+
+// feeStorage holds the transactions of blocks 1 to len(blocks).
+type feeStorage struct {
+	db.PlasmaStorage
+	blocks [][]chain.ConfirmedTransaction
+}
+
+func (s *feeStorage) LatestBlock() (*chain.Block, error) {
+	if len(s.blocks) == 0 {
+		return nil, nil
+	}
+	return &chain.Block{Header: &chain.BlockHeader{Number: uint64(len(s.blocks))}}, nil
+}
+
+func (s *feeStorage) FindTransactionsByBlockNum(blkNum uint64) ([]chain.ConfirmedTransaction, error) {
+	return s.blocks[blkNum-1], nil
+}
+
+func feeBlock(fees ...int64) []chain.ConfirmedTransaction {
+	var txs []chain.ConfirmedTransaction
+	for _, fee := range fees {
+		tx := chain.ZeroTransaction()
+		tx.Fee = big.NewInt(fee)
+		txs = append(txs, chain.ConfirmedTransaction{Transaction: *tx})
+	}
+	return txs
+}
+
+func depositBlock() []chain.ConfirmedTransaction {
+	tx := chain.ZeroTransaction()
+	tx.Output0 = chain.NewOutput(common.Address{}, big.NewInt(100), big.NewInt(1))
+	return []chain.ConfirmedTransaction{{Transaction: *tx}}
+}
+
+func TestEstimateFee(t *testing.T) {
+	var manyBlocks [][]chain.ConfirmedTransaction
+	for i := 0; i < FeeEstimateBlocks; i++ {
+		manyBlocks = append(manyBlocks, feeBlock(7))
+	}
+
+	tests := []struct {
+		name        string
+		blocks      [][]chain.ConfirmedTransaction
+		fee         int64
+		sampledBlks uint64
+		sampledTxs  uint64
+	}{
+		{"empty chain", nil, 0, 0, 0},
+		{"deposits only", [][]chain.ConfirmedTransaction{depositBlock(), depositBlock()}, 0, 2, 0},
+		{"median of odd count", [][]chain.ConfirmedTransaction{feeBlock(5, 1), depositBlock(), feeBlock(3)}, 3, 3, 3},
+		{"upper median of even count", [][]chain.ConfirmedTransaction{feeBlock(1, 2, 3, 4)}, 3, 1, 4},
+		{"only recent blocks", append([][]chain.ConfirmedTransaction{feeBlock(1000, 1000, 1000)}, manyBlocks...), 7, FeeEstimateBlocks, FeeEstimateBlocks},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate, err := EstimateFee(&feeStorage{blocks: tt.blocks})
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(tt.fee).String(), estimate.Fee.String())
+			require.Equal(t, tt.sampledBlks, estimate.SampledBlocks)
+			require.Equal(t, tt.sampledTxs, estimate.SampledTransactions)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyokan/plasma/db"
+	"github.com/kyokan/plasma/eth"
+	"github.com/pkg/errors"
+)
+
+type SyncStatus struct {
+	EthereumHeight     uint64
+	LastDepositPoll    uint64
+	LastTxExitPoll     uint64
+	LatestBlock        uint64
+	LastSubmittedBlock uint64
+	Ready              bool
+	Reason             string
+}
+
+type HealthChecker struct {
+	client             eth.Client
+	storage            db.PlasmaStorage
+	maxChainsawLag     uint64
+	maxSubmissionDelay time.Duration
+}
+
+func NewHealthChecker(client eth.Client, storage db.PlasmaStorage, maxChainsawLag uint64, maxSubmissionDelay time.Duration) *HealthChecker {
+	return &HealthChecker{
+		client:             client,
+		storage:            storage,
+		maxChainsawLag:     maxChainsawLag,
+		maxSubmissionDelay: maxSubmissionDelay,
+	}
+}
+
+// Alive only checks that the node can still read from its own database.
+func (h *HealthChecker) Alive() error {
+	_, err := h.storage.LatestBlock()
+	return err
+}
+
+// Ready returns an error describing why the node shouldn't receive traffic,
+// or nil if every subsystem is caught up.
+func (h *HealthChecker) Ready() error {
+	status, err := h.SyncStatus()
+	if err != nil {
+		return err
+	}
+	if !status.Ready {
+		return errors.New(status.Reason)
+	}
+	return nil
+}
+
+func (h *HealthChecker) SyncStatus() (*SyncStatus, error) {
+	status := &SyncStatus{}
+
+	latest, err := h.storage.LatestBlock()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch latest block")
+	}
+	if latest != nil {
+		status.LatestBlock = latest.Header.Number
+	}
+	status.LastSubmittedBlock, err = h.storage.LastSubmittedBlock()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch last submitted block")
+	}
+	status.LastDepositPoll, err = h.storage.LastDepositPoll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch last deposit poll")
+	}
+	status.LastTxExitPoll, err = h.storage.LastTxExitPoll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch last transaction exit poll")
+	}
+
+	status.EthereumHeight, err = h.client.EthereumBlockHeight()
+	if err != nil {
+		status.Reason = fmt.Sprintf("ethereum node is unreachable: %s", err)
+		return status, nil
+	}
+
+	if lag := chainsawLag(status.EthereumHeight, status.LastDepositPoll); lag > h.maxChainsawLag {
+		status.Reason = fmt.Sprintf("deposit poller is %d blocks behind", lag)
+		return status, nil
+	}
+	if lag := chainsawLag(status.EthereumHeight, status.LastTxExitPoll); lag > h.maxChainsawLag {
+		status.Reason = fmt.Sprintf("transaction exit poller is %d blocks behind", lag)
+		return status, nil
+	}
+
+	if status.LastSubmittedBlock < status.LatestBlock {
+		meta, err := h.storage.BlockMetaAtHeight(status.LastSubmittedBlock + 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch unsubmitted block metadata")
+		}
+		age := time.Since(time.Unix(int64(meta.CreatedAt), 0))
+		if age > h.maxSubmissionDelay {
+			status.Reason = fmt.Sprintf("block %d has been awaiting submission for %s", status.LastSubmittedBlock+1, age.Round(time.Second))
+			return status, nil
+		}
+	}
+
+	status.Ready = true
+	return status, nil
+}
+
+func chainsawLag(head uint64, tail uint64) uint64 {
+	if tail >= head {
+		return 0
+	}
+	return head - tail
+}
@@ -0,0 +1,130 @@
+package node
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/db"
+	"github.com/kyokan/plasma/eth"
+	"github.com/stretchr/testify/require"
+)
+
+type healthStorage struct {
+	db.PlasmaStorage
+	latest        uint64
+	lastSubmitted uint64
+	depositPoll   uint64
+	txExitPoll    uint64
+	createdAt     time.Time
+}
+
+func (s *healthStorage) LatestBlock() (*chain.Block, error) {
+	return &chain.Block{Header: &chain.BlockHeader{Number: s.latest}}, nil
+}
+
+func (s *healthStorage) LastSubmittedBlock() (uint64, error) {
+	return s.lastSubmitted, nil
+}
+
+func (s *healthStorage) LastDepositPoll() (uint64, error) {
+	return s.depositPoll, nil
+}
+
+func (s *healthStorage) LastTxExitPoll() (uint64, error) {
+	return s.txExitPoll, nil
+}
+
+func (s *healthStorage) BlockMetaAtHeight(num uint64) (*chain.BlockMetadata, error) {
+	return &chain.BlockMetadata{CreatedAt: uint64(s.createdAt.Unix())}, nil
+}
+
+type healthClient struct {
+	eth.Client
+	height uint64
+	err    error
+}
+
+func (c *healthClient) EthereumBlockHeight() (uint64, error) {
+	return c.height, c.err
+}
+
+func TestHealthCheckerSyncStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage healthStorage
+		client  healthClient
+		reason  string
+	}{
+		{
+			name:    "caught up",
+			storage: healthStorage{latest: 5, lastSubmitted: 5, depositPoll: 100, txExitPoll: 100},
+			client:  healthClient{height: 100},
+		},
+		{
+			name:    "pollers within lag",
+			storage: healthStorage{latest: 5, lastSubmitted: 5, depositPoll: 90, txExitPoll: 90},
+			client:  healthClient{height: 100},
+		},
+		{
+			name:    "ethereum unreachable",
+			storage: healthStorage{latest: 5, lastSubmitted: 5},
+			client:  healthClient{err: errors.New("connection refused")},
+			reason:  "ethereum node is unreachable: connection refused",
+		},
+		{
+			name:    "deposit poller behind",
+			storage: healthStorage{latest: 5, lastSubmitted: 5, depositPoll: 50, txExitPoll: 100},
+			client:  healthClient{height: 100},
+			reason:  "deposit poller is 50 blocks behind",
+		},
+		{
+			name:    "exit poller behind",
+			storage: healthStorage{latest: 5, lastSubmitted: 5, depositPoll: 100, txExitPoll: 11},
+			client:  healthClient{height: 100},
+			reason:  "transaction exit poller is 89 blocks behind",
+		},
+		{
+			name:    "recent unsubmitted block",
+			storage: healthStorage{latest: 6, lastSubmitted: 5, depositPoll: 100, txExitPoll: 100, createdAt: time.Now()},
+			client:  healthClient{height: 100},
+		},
+		{
+			name:    "stale unsubmitted block",
+			storage: healthStorage{latest: 6, lastSubmitted: 5, depositPoll: 100, txExitPoll: 100, createdAt: time.Now().Add(-time.Hour)},
+			client:  healthClient{height: 100},
+			reason:  "block 6 has been awaiting submission for 1h0m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewHealthChecker(&tt.client, &tt.storage, 10, time.Minute)
+			status, err := checker.SyncStatus()
+			require.NoError(t, err)
+			require.Equal(t, tt.storage.latest, status.LatestBlock)
+
+			// the age of a stale block depends on when the test runs, so
+			// only the start of the reason is compared
+			if tt.reason == "" {
+				require.True(t, status.Ready)
+				require.Empty(t, status.Reason)
+				require.NoError(t, checker.Ready())
+			} else {
+				require.False(t, status.Ready)
+				require.True(t, strings.HasPrefix(status.Reason, tt.reason), "got %q", status.Reason)
+				err := checker.Ready()
+				require.Error(t, err)
+				require.True(t, strings.HasPrefix(err.Error(), tt.reason), "got %q", err)
+			}
+		})
+	}
+}
+
+func TestChainsawLag(t *testing.T) {
+	require.Equal(t, uint64(0), chainsawLag(10, 10))
+	require.Equal(t, uint64(0), chainsawLag(10, 12))
+	require.Equal(t, uint64(3), chainsawLag(10, 7))
+}
@@ -1,15 +1,16 @@
 package node
 
 import (
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/kyokan/plasma/chain"
 	"github.com/kyokan/plasma/db"
-	"errors"
 	"fmt"
 	"math/big"
 	"github.com/kyokan/plasma/log"
 	"github.com/sirupsen/logrus"
 	"github.com/kyokan/plasma/eth"
 	"github.com/kyokan/plasma/util"
+	"github.com/pkg/errors"
 )
 
 const MaxMempoolSize = 65534
@@ -32,11 +33,14 @@ type Mempool struct {
 	txReqs          chan *txRequest
 	quit            chan bool
 	flushSpendReq   chan flushSpendReq
+	pendingReq      chan chan int
 	flushDepositReq chan chan *MempoolTx
 	txPool          []MempoolTx
 	depositPool     []MempoolTx
 	poolSpends      map[string]bool
 	storage         db.PlasmaStorage
+	verifier        *eth.SignatureVerifier
+	acceptMulti     bool
 }
 
 type txRequest struct {
@@ -45,20 +49,26 @@ type txRequest struct {
 }
 
 type flushSpendReq struct {
+	max  int
 	res  chan []MempoolTx
 	done chan bool
 }
 
-func NewMempool(storage db.PlasmaStorage) *Mempool {
+// NewMempool creates a mempool that verifies transactions against storage.
+// Multi-input transactions are rejected unless acceptMulti is set.
+func NewMempool(storage db.PlasmaStorage, verifier *eth.SignatureVerifier, acceptMulti bool) *Mempool {
 	return &Mempool{
 		txReqs:          make(chan *txRequest),
 		quit:            make(chan bool),
 		flushSpendReq:   make(chan flushSpendReq),
+		pendingReq:      make(chan chan int),
 		flushDepositReq: make(chan chan *MempoolTx),
 		txPool:          make([]MempoolTx, 0),
 		depositPool:     make([]MempoolTx, 0),
 		poolSpends:      make(map[string]bool),
 		storage:         storage,
+		verifier:        verifier,
+		acceptMulti:     acceptMulti,
 	}
 }
 
@@ -69,7 +79,7 @@ func (m *Mempool) Start() error {
 			case req := <-m.txReqs:
 				if len(m.txPool) == MaxMempoolSize {
 					req.res <- TxInclusionResponse{
-						Error: errors.New("mempool is full"),
+						Error: NewError(ReasonMempoolFull, "mempool is full"),
 					}
 					continue
 				}
@@ -78,6 +88,8 @@ func (m *Mempool) Start() error {
 				var err error
 				if tx.Transaction.IsDeposit() {
 					err = m.VerifyDepositTransaction(&tx)
+				} else if input, conflict := m.conflictingInput(&tx); conflict {
+					err = NewInputError(ReasonDoubleSpend, input, "is already spent by a pending transaction")
 				} else {
 					err = m.VerifySpendTransaction(&tx)
 				}
@@ -102,14 +114,23 @@ func (m *Mempool) Start() error {
 						Tx:       tx,
 						Response: req.res,
 					})
+					m.updatePoolSpends(&tx)
 				}
-				m.updatePoolSpends(&tx)
 			case req := <-m.flushSpendReq:
 				res := m.txPool
 				m.txPool = make([]MempoolTx, 0)
+				if req.max > 0 && len(res) > req.max {
+					m.txPool = append(m.txPool, res[req.max:]...)
+					res = res[:req.max]
+				}
 				m.poolSpends = make(map[string]bool)
+				for _, mtx := range m.txPool {
+					m.updatePoolSpends(&mtx.Tx)
+				}
 				req.res <- res
 				<-req.done
+			case resCh := <-m.pendingReq:
+				resCh <- len(m.txPool)
 			case resCh := <-m.flushDepositReq:
 				if len(m.depositPool) == 0 {
 					resCh <- nil
@@ -131,15 +152,24 @@ func (m *Mempool) Stop() error {
 	return nil
 }
 
-func (m *Mempool) FlushSpends(done chan bool) []MempoolTx {
+// FlushSpends removes up to max spends from the pool, or all of them if max
+// is zero. The pool stops accepting transactions until done is signalled.
+func (m *Mempool) FlushSpends(max int, done chan bool) []MempoolTx {
 	res := make(chan []MempoolTx)
 	m.flushSpendReq <- flushSpendReq{
+		max:  max,
 		res:  res,
 		done: done,
 	}
 	return <-res
 }
 
+func (m *Mempool) PendingSpends() int {
+	res := make(chan int)
+	m.pendingReq <- res
+	return <-res
+}
+
 func (m *Mempool) FlushDeposit() *MempoolTx {
 	res := make(chan *MempoolTx)
 	m.flushDepositReq <- res
@@ -157,33 +187,39 @@ func (m *Mempool) Append(tx chain.ConfirmedTransaction) TxInclusionResponse {
 }
 
 func (m *Mempool) VerifySpendTransaction(confirmed *chain.ConfirmedTransaction) (error) {
+	if confirmed.Transaction.IsMulti() {
+		if !m.acceptMulti {
+			return NewError(ReasonInvalidTransaction, "multi-input transactions are not accepted")
+		}
+		return m.verifyMultiTransaction(confirmed)
+	}
+
 	txLog := mPoolLogger.WithFields(logrus.Fields{
 		"hash": confirmed.Transaction.SignatureHash().Hex(),
 	})
 
 	if confirmed.Transaction.Output0.Denom.Cmp(big.NewInt(0)) == -1 {
-		return errors.New("transaction rejected due to negative output0 denomination")
+		return NewError(ReasonInvalidTransaction, "transaction rejected due to negative output0 denomination")
 	}
 
-	prevTx0, err := m.storage.FindTransactionByBlockNumTxIdx(confirmed.Transaction.Input0.BlkNum, confirmed.Transaction.Input0.TxIdx)
+	prevTx0Output, err := m.spentOutput(confirmed.Transaction.Input0, 0)
 	if err != nil {
 		return err
 	}
-	if prevTx0 == nil {
-		return errors.New("input 0 not found")
-	}
 
-	prevTx0Output := prevTx0.Transaction.OutputAt(confirmed.Transaction.Input0.OutIdx)
+	// typed input signatures sign the whole transaction
+	typed := eth.TransactionTypedData(m.verifier.Domain, &confirmed.Transaction)
 	sigHash0 := confirmed.Transaction.Input0.SignatureHash()
-	err = eth.ValidateSignature(sigHash0, confirmed.Transaction.Sig0[:], prevTx0Output.Owner)
+	err = m.verifier.Validate(typed, sigHash0, confirmed.Transaction.Sig0[:], prevTx0Output.Owner)
 	if err != nil {
 		txLog.Warn("transaction rejected due to invalid sig 0")
-		return err
+		return NewInputError(ReasonInvalidSignature, 0, "has an invalid signature")
 	}
-	err = eth.ValidateSignature(confirmed.Transaction.SignatureHash(), confirmed.Signatures[0][:], prevTx0Output.Owner)
+	confirmSig0 := confirmed.ConfirmSigAt(0)
+	err = verifyConfirmSig(typed, confirmed.Transaction.Sig0, confirmSig0, confirmed.Transaction.SignatureHash(), prevTx0Output.Owner)
 	if err != nil {
 		txLog.Warn("transaction rejected due to invalid confirm sig 0")
-		return err
+		return NewInputError(ReasonInvalidSignature, 0, "has an invalid confirm signature")
 	}
 
 	totalInput := big.NewInt(0)
@@ -191,28 +227,24 @@ func (m *Mempool) VerifySpendTransaction(confirmed *chain.ConfirmedTransaction)
 
 	if !confirmed.Transaction.Input1.IsZeroInput() {
 		if confirmed.Transaction.Output1.Denom.Cmp(big.NewInt(0)) == -1 {
-			return errors.New("transaction rejected due to negative output1 denomination")
+			return NewError(ReasonInvalidTransaction, "transaction rejected due to negative output1 denomination")
 		}
 
-		prevTx1, err := m.storage.FindTransactionByBlockNumTxIdx(confirmed.Transaction.Input1.BlkNum, confirmed.Transaction.Input1.TxIdx)
+		prevTx1Output, err := m.spentOutput(confirmed.Transaction.Input1, 1)
 		if err != nil {
 			return err
 		}
-		if prevTx1 == nil {
-			return errors.New("input 1 not found")
-		}
-
-		prevTx1Output := prevTx1.Transaction.OutputAt(confirmed.Transaction.Input1.OutIdx)
 		sigHash1 := confirmed.Transaction.Input1.SignatureHash()
-		err = eth.ValidateSignature(sigHash1, confirmed.Transaction.Sig1[:], prevTx1Output.Owner)
+		err = m.verifier.Validate(typed, sigHash1, confirmed.Transaction.Sig1[:], prevTx1Output.Owner)
 		if err != nil {
 			txLog.Warn("transaction rejected due to invalid sig 1")
-			return err
+			return NewInputError(ReasonInvalidSignature, 1, "has an invalid signature")
 		}
-		err = eth.ValidateSignature(confirmed.Transaction.SignatureHash(), confirmed.Signatures[1][:], prevTx1Output.Owner)
+		confirmSig1 := confirmed.ConfirmSigAt(1)
+		err = verifyConfirmSig(typed, confirmed.Transaction.Sig1, confirmSig1, confirmed.Transaction.SignatureHash(), prevTx1Output.Owner)
 		if err != nil {
 			txLog.Warn("transaction rejected due to invalid confirm sig 1")
-			return err
+			return NewInputError(ReasonInvalidSignature, 1, "has an invalid confirm signature")
 		}
 
 		totalInput = totalInput.Add(totalInput, prevTx1Output.Denom)
@@ -227,7 +259,7 @@ func (m *Mempool) VerifySpendTransaction(confirmed *chain.ConfirmedTransaction)
 
 	if totalInput.Cmp(totalOutput) != 0 {
 		txLog.Warn("transaction rejected due inputs not equalling outputs plus fees")
-		return errors.New("inputs and outputs do not have the same sum")
+		return NewError(ReasonInsufficientInputs, "inputs and outputs do not have the same sum")
 	}
 
 	isDoubleSpent, err := m.storage.IsDoubleSpent(confirmed)
@@ -236,7 +268,81 @@ func (m *Mempool) VerifySpendTransaction(confirmed *chain.ConfirmedTransaction)
 	}
 
 	if isDoubleSpent {
-		return errors.New("transaction double spent")
+		return NewError(ReasonDoubleSpend, "transaction double spent")
+	}
+
+	return nil
+}
+
+// spentOutput finds the output spent by input idx of a transaction. The
+// output must exist, and must not be empty or exited.
+func (m *Mempool) spentOutput(input *chain.Input, idx uint8) (*chain.Output, error) {
+	prevTx, err := m.storage.FindTransactionByBlockNumTxIdx(input.BlkNum, input.TxIdx)
+	if err != nil && !db.IsNotFound(err) {
+		return nil, err
+	}
+	if prevTx == nil || input.OutIdx >= prevTx.Transaction.NumOutputs() {
+		return nil, NewInputError(ReasonNotFound, idx, "not found")
+	}
+
+	prevOutput := prevTx.Transaction.OutputAt(input.OutIdx)
+	if prevOutput.IsZeroOutput() || prevOutput.IsExit() {
+		return nil, NewInputError(ReasonInvalidTransaction, idx, "spends an empty or exited output")
+	}
+	return prevOutput, nil
+}
+
+// verifyMultiTransaction checks a multi transaction. Like legacy
+// transactions, every input needs an input signature from the owner of the
+// output it spends, and a confirm signature unless it was signed as typed
+// data.
+func (m *Mempool) verifyMultiTransaction(confirmed *chain.ConfirmedTransaction) error {
+	tx := &confirmed.Transaction
+	txLog := mPoolLogger.WithFields(logrus.Fields{
+		"hash": tx.SignatureHash().Hex(),
+	})
+
+	if err := tx.Validate(); err != nil {
+		return NewError(ReasonInvalidTransaction, err.Error())
+	}
+
+	sigHash := tx.SigningHash()
+	confirmHash := tx.SignatureHash()
+	typed := eth.TransactionTypedData(m.verifier.Domain, tx)
+	totalInput := big.NewInt(0)
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		prevOutput, err := m.spentOutput(tx.InputAt(i), i)
+		if err != nil {
+			return err
+		}
+		sig := tx.SigAt(i)
+		if err := m.verifier.Validate(typed, sigHash, sig[:], prevOutput.Owner); err != nil {
+			txLog.Warn(fmt.Sprintf("transaction rejected due to invalid sig %d", i))
+			return NewInputError(ReasonInvalidSignature, i, "has an invalid signature")
+		}
+		if err := verifyConfirmSig(typed, sig, confirmed.ConfirmSigAt(i), confirmHash, prevOutput.Owner); err != nil {
+			txLog.Warn(fmt.Sprintf("transaction rejected due to invalid confirm sig %d", i))
+			return NewInputError(ReasonInvalidSignature, i, "has an invalid confirm signature")
+		}
+
+		totalInput = totalInput.Add(totalInput, prevOutput.Denom)
+	}
+
+	totalOutput := new(big.Int).Set(tx.Fee)
+	for i := uint8(0); i < tx.NumOutputs(); i++ {
+		totalOutput = totalOutput.Add(totalOutput, tx.OutputAt(i).Denom)
+	}
+	if totalInput.Cmp(totalOutput) != 0 {
+		txLog.Warn("transaction rejected due inputs not equalling outputs plus fees")
+		return NewError(ReasonInsufficientInputs, "inputs and outputs do not have the same sum")
+	}
+
+	isDoubleSpent, err := m.storage.IsDoubleSpent(confirmed)
+	if err != nil {
+		return err
+	}
+	if isDoubleSpent {
+		return NewError(ReasonDoubleSpend, "transaction double spent")
 	}
 
 	return nil
@@ -246,12 +352,53 @@ func (m *Mempool) VerifyDepositTransaction(tx *chain.ConfirmedTransaction) (erro
 	return nil
 }
 
+// conflictingInput returns the first input of confirmed that spends an
+// output a pooled transaction, or an earlier input, already spends. Without
+// it both spends could end up in the same block.
+func (m *Mempool) conflictingInput(confirmed *chain.ConfirmedTransaction) (uint8, bool) {
+	tx := &confirmed.Transaction
+	seen := make(map[string]bool)
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		key, ok := poolSpendKey(tx, i)
+		if !ok {
+			continue
+		}
+		if m.poolSpends[key] || seen[key] {
+			return i, true
+		}
+		seen[key] = true
+	}
+	return 0, false
+}
+
 func (m *Mempool) updatePoolSpends(confirmed *chain.ConfirmedTransaction) {
-	tx := confirmed.Transaction
-	key0 := fmt.Sprintf("%d:%d:%d", tx.Input0.BlkNum, tx.Input0.TxIdx, tx.Input0.OutIdx)
-	m.poolSpends[key0] = true
-	if !tx.Input1.IsZeroInput() {
-		key1 := fmt.Sprintf("%d:%d:%d", tx.Input1.BlkNum, tx.Input1.TxIdx, tx.Input1.OutIdx)
-		m.poolSpends[key1] = true
+	tx := &confirmed.Transaction
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		if key, ok := poolSpendKey(tx, i); ok {
+			m.poolSpends[key] = true
+		}
+	}
+}
+
+func poolSpendKey(tx *chain.Transaction, i uint8) (string, bool) {
+	input := tx.InputAt(i)
+	if i > 0 && input.IsZeroInput() {
+		return "", false
+	}
+	return fmt.Sprintf("%d:%d:%d:%s", input.BlkNum, input.TxIdx, input.OutIdx, input.DepositNonce), true
+}
+
+// verifyConfirmSig checks the confirm signature sent along with an input
+// whose signature sig has already been verified. Typed inputs are only
+// confirmed once the transaction is included, over
+// eth.ConfirmationTypedData, so they must not carry one. Legacy inputs must
+// sign confirmHash.
+func verifyConfirmSig(typed *eth.TypedData, sig chain.Signature, confirmSig chain.Signature, confirmHash util.Hash, owner common.Address) error {
+	if eth.ValidateTypedDataSignature(typed, sig[:], owner) == nil {
+		if confirmSig != (chain.Signature{}) {
+			return errors.New("typed inputs are confirmed once included")
+		}
+		return nil
 	}
+	return eth.ValidateSignature(confirmHash, confirmSig[:], owner)
 }
@@ -0,0 +1,220 @@
+package node
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/db"
+	"github.com/kyokan/plasma/eth"
+	"github.com/stretchr/testify/require"
+)
+
+func spendOf(inputs ...*chain.Input) *chain.ConfirmedTransaction {
+	tx := chain.ZeroTransaction()
+	tx.Input0 = inputs[0]
+	if len(inputs) > 1 {
+		tx.Input1 = inputs[1]
+	}
+	return &chain.ConfirmedTransaction{Transaction: *tx}
+}
+
+func multiSpendOf(inputs ...*chain.Input) *chain.ConfirmedTransaction {
+	tx := chain.NewMultiTransaction(inputs, nil, []*chain.Output{chain.ZeroOutput()}, big.NewInt(0))
+	return &chain.ConfirmedTransaction{Transaction: *tx}
+}
+
+func TestMempoolConflictingInput(t *testing.T) {
+	a := chain.NewInput(1, 0, 0, chain.Zero(), chain.RandomAddress())
+	b := chain.NewInput(1, 0, 1, chain.Zero(), chain.RandomAddress())
+	c := chain.NewInput(2, 3, 0, chain.Zero(), chain.RandomAddress())
+	deposit := chain.NewInput(0, 0, 0, big.NewInt(7), chain.RandomAddress())
+	otherDeposit := chain.NewInput(0, 0, 0, big.NewInt(8), chain.RandomAddress())
+
+	m := NewMempool(nil, nil, false)
+	m.updatePoolSpends(spendOf(a, b))
+	m.updatePoolSpends(spendOf(deposit))
+
+	tests := []struct {
+		name     string
+		tx       *chain.ConfirmedTransaction
+		conflict bool
+		input    uint8
+	}{
+		{"unspent input", spendOf(c), false, 0},
+		{"pooled input", spendOf(c, b), true, 1},
+		{"same input twice", spendOf(c, c), true, 1},
+		{"pooled deposit", spendOf(deposit), true, 0},
+		{"other deposit", spendOf(otherDeposit), false, 0},
+		{"multi with pooled input", multiSpendOf(c, otherDeposit, a), true, 2},
+		{"multi with same input twice", multiSpendOf(otherDeposit, c, otherDeposit), true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, conflict := m.conflictingInput(tt.tx)
+			require.Equal(t, tt.conflict, conflict)
+			require.Equal(t, tt.input, input)
+		})
+	}
+}
+
+func TestVerifyConfirmSig(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := eth.NewLocalSigner(key)
+	owner := signer.Address()
+
+	confirmed := spendOf(chain.NewInput(1, 0, 0, chain.Zero(), owner))
+	tx := &confirmed.Transaction
+	typed := eth.TransactionTypedData(eth.NewPlasmaDomain(big.NewInt(1), chain.RandomAddress()), tx)
+	typedSig, err := signer.SignTypedData(typed)
+	require.NoError(t, err)
+	sigHash := tx.Input0.SignatureHash()
+	legacySig, err := signer.SignHash(sigHash)
+	require.NoError(t, err)
+	confirmHash := tx.SignatureHash()
+	legacyConfirmSig, err := signer.SignHash(confirmHash)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		sig        chain.Signature
+		confirmSig chain.Signature
+		valid      bool
+	}{
+		{"typed without confirm signature", typedSig, chain.Signature{}, true},
+		{"typed with its input signature copied", typedSig, typedSig, false},
+		{"typed with a legacy confirm signature", typedSig, legacyConfirmSig, false},
+		{"legacy", legacySig, legacyConfirmSig, true},
+		{"legacy without confirm signature", legacySig, chain.Signature{}, false},
+		{"legacy with its input signature copied", legacySig, legacySig, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyConfirmSig(typed, tt.sig, tt.confirmSig, confirmHash, owner)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+// spendStorage holds the transactions legacy spends may point at, keyed by
+// block number. Every transaction is at index 0.
+type spendStorage struct {
+	db.PlasmaStorage
+	txs map[uint64]*chain.Transaction
+}
+
+func (s *spendStorage) FindTransactionByBlockNumTxIdx(blkNum uint64, txIdx uint32) (*chain.ConfirmedTransaction, error) {
+	tx, ok := s.txs[blkNum]
+	if !ok || txIdx != 0 {
+		return nil, nil
+	}
+	return &chain.ConfirmedTransaction{Transaction: *tx}, nil
+}
+
+func (s *spendStorage) IsDoubleSpent(confirmed *chain.ConfirmedTransaction) (bool, error) {
+	return false, nil
+}
+
+func TestVerifySpendTransactionSpentOutputs(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := eth.NewLocalSigner(key)
+	owner := signer.Address()
+
+	// block 1 has a single output multi transaction, block 2 a legacy
+	// transaction whose second output is empty, and block 3 an exit
+	multi := chain.NewMultiTransaction(
+		[]*chain.Input{chain.NewInput(0, 0, 0, big.NewInt(1), owner)},
+		[]chain.Signature{{}},
+		[]*chain.Output{chain.NewOutput(owner, big.NewInt(10), big.NewInt(0))},
+		big.NewInt(0),
+	)
+	legacy := chain.ZeroTransaction()
+	legacy.Output0 = chain.NewOutput(owner, big.NewInt(10), big.NewInt(0))
+	exit := chain.ZeroTransaction()
+	exit.Output0 = chain.ExitOutput()
+	storage := &spendStorage{txs: map[uint64]*chain.Transaction{1: multi, 2: legacy, 3: exit}}
+	m := NewMempool(storage, eth.NewSignatureVerifier(eth.NewPlasmaDomain(big.NewInt(1), chain.RandomAddress()), true), false)
+
+	// spend signs a legacy transaction spending inputs, with outputs adding
+	// up to the first input
+	spend := func(inputs ...*chain.Input) *chain.ConfirmedTransaction {
+		confirmed := spendOf(inputs...)
+		tx := &confirmed.Transaction
+		tx.Output0 = chain.NewOutput(owner, big.NewInt(10), big.NewInt(0))
+		for i := uint8(0); i < uint8(len(inputs)); i++ {
+			sig, err := signer.SignHash(tx.InputAt(i).SignatureHash())
+			require.NoError(t, err)
+			if i == 0 {
+				tx.Sig0 = sig
+			} else {
+				tx.Sig1 = sig
+			}
+		}
+		for i := uint8(0); i < uint8(len(inputs)); i++ {
+			confirmSig, err := signer.SignHash(tx.SignatureHash())
+			require.NoError(t, err)
+			confirmed.SetConfirmSig(i, confirmSig)
+		}
+		return confirmed
+	}
+	input := func(blkNum uint64, outIdx uint8) *chain.Input {
+		return chain.NewInput(blkNum, 0, outIdx, big.NewInt(0), owner)
+	}
+
+	tests := []struct {
+		name   string
+		tx     *chain.ConfirmedTransaction
+		reason ErrorReason
+		input  uint8
+	}{
+		{"output of a multi transaction", spend(input(1, 0)), "", 0},
+		{"missing output of a multi transaction", spend(input(1, 1)), ReasonNotFound, 0},
+		{"missing output as the second input", spend(input(1, 0), input(1, 1)), ReasonNotFound, 1},
+		{"missing transaction", spend(input(4, 0)), ReasonNotFound, 0},
+		{"empty output", spend(input(2, 1)), ReasonInvalidTransaction, 0},
+		{"empty output as the second input", spend(input(2, 0), input(2, 1)), ReasonInvalidTransaction, 1},
+		{"exited output", spend(input(3, 0)), ReasonInvalidTransaction, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.VerifySpendTransaction(tt.tx)
+			if tt.reason == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tt.reason, AsError(err).Reason)
+			require.Equal(t, int(tt.input), AsError(err).Input)
+		})
+	}
+}
+
+func TestVerifySpendTransactionMultiFlag(t *testing.T) {
+	storage := &spendStorage{txs: map[uint64]*chain.Transaction{}}
+	verifier := eth.NewSignatureVerifier(eth.NewPlasmaDomain(big.NewInt(1), chain.RandomAddress()), true)
+	tx := &chain.ConfirmedTransaction{Transaction: *chain.NewMultiTransaction(
+		[]*chain.Input{chain.NewInput(1, 0, 0, chain.Zero(), chain.RandomAddress())},
+		[]chain.Signature{{}},
+		[]*chain.Output{chain.NewOutput(chain.RandomAddress(), big.NewInt(10), big.NewInt(0))},
+		big.NewInt(0),
+	)}
+
+	err := NewMempool(storage, verifier, false).VerifySpendTransaction(tx)
+	require.Equal(t, ReasonInvalidTransaction, AsError(err).Reason)
+	require.Equal(t, NoInput, AsError(err).Input)
+
+	// with the flag set, the transaction is verified, and its input is missing
+	err = NewMempool(storage, verifier, true).VerifySpendTransaction(tx)
+	require.Equal(t, ReasonNotFound, AsError(err).Reason)
+	require.Equal(t, 0, AsError(err).Input)
+}
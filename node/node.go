@@ -15,25 +15,28 @@ type PlasmaNode struct {
 	mPool     *Mempool
 	client    eth.Client
 	submitter *BlockSubmitter
+	policy    BlockPolicy
 }
 
-func NewPlasmaNode(storage db.PlasmaStorage, mPool *Mempool, client eth.Client, submitter *BlockSubmitter) *PlasmaNode {
+func NewPlasmaNode(storage db.PlasmaStorage, mPool *Mempool, client eth.Client, submitter *BlockSubmitter, policy BlockPolicy) *PlasmaNode {
 	return &PlasmaNode{
 		storage:   storage,
 		mPool:     mPool,
 		client:    client,
 		submitter: submitter,
+		policy:    policy,
 	}
 }
 
 func (node *PlasmaNode) Start() {
-	go node.awaitTxs(100 * time.Millisecond)
+	go node.awaitTxs(node.policy.pollInterval())
 }
 
 func (node *PlasmaNode) awaitTxs(interval time.Duration) {
 	log.Print("Awaiting transactions.")
 
 	tick := time.NewTicker(interval)
+	lastBlock := time.Now()
 
 	for {
 		select {
@@ -41,14 +44,18 @@ func (node *PlasmaNode) awaitTxs(interval time.Duration) {
 			deposit := node.mPool.FlushDeposit()
 			if deposit != nil {
 				node.packageDepositBlocks(*deposit)
+				lastBlock = time.Now()
 				continue
 			}
 
-			done := make(chan bool)
-			spends := node.mPool.FlushSpends(done)
-			if len(spends) > 0 {
-				node.packageBlock(spends)
+			if !node.policy.ShouldPackage(node.mPool.PendingSpends(), time.Since(lastBlock)) {
+				continue
 			}
+
+			done := make(chan bool)
+			spends := node.mPool.FlushSpends(node.policy.MaxTxCount, done)
+			node.packageBlock(spends)
+			lastBlock = time.Now()
 			done <- true
 		}
 	}
@@ -0,0 +1,59 @@
+package node
+
+import (
+	"sync"
+	"time"
+)
+
+// MaxOwnConfirmationsRequestTTL bounds how far ahead a GetOwnConfirmations
+// request may expire. Answered requests are remembered until they expire, so
+// it also bounds how long that is.
+const MaxOwnConfirmationsRequestTTL = 5 * time.Minute
+
+// ownRequestSweepInterval is how often expired requests are forgotten.
+const ownRequestSweepInterval = time.Minute
+
+type ownConfirmationsRequest struct {
+	blockNumber      uint64
+	transactionIndex uint32
+	inputIndex       uint8
+	expiresAt        uint64
+}
+
+// ownRequestLog remembers the GetOwnConfirmations requests that were
+// answered, so that a captured request can't be replayed. Requests are told
+// apart by what was signed rather than by their signatures, which can be
+// altered without invalidating them.
+type ownRequestLog struct {
+	mtx       sync.Mutex
+	used      map[ownConfirmationsRequest]bool
+	lastSweep time.Time
+}
+
+func newOwnRequestLog() *ownRequestLog {
+	return &ownRequestLog{
+		used: make(map[ownConfirmationsRequest]bool),
+	}
+}
+
+// use records req, and reports whether it hasn't expired and wasn't used
+// before.
+func (l *ownRequestLog) use(req ownConfirmationsRequest, now time.Time) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if now.Sub(l.lastSweep) >= ownRequestSweepInterval {
+		for used := range l.used {
+			if !now.Before(time.Unix(int64(used.expiresAt), 0)) {
+				delete(l.used, used)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	if !now.Before(time.Unix(int64(req.expiresAt), 0)) || l.used[req] {
+		return false
+	}
+	l.used[req] = true
+	return true
+}
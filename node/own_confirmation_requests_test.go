@@ -0,0 +1,28 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnRequestLogUse(t *testing.T) {
+	l := newOwnRequestLog()
+	now := time.Now()
+	req := ownConfirmationsRequest{
+		blockNumber: 2,
+		expiresAt:   uint64(now.Add(time.Minute).Unix()),
+	}
+
+	require.True(t, l.use(req, now))
+	require.False(t, l.use(req, now))
+	other := req
+	other.inputIndex = 1
+	require.True(t, l.use(other, now))
+
+	// expired requests are refused, and forgotten once swept
+	later := now.Add(2 * time.Minute)
+	require.False(t, l.use(req, later))
+	require.Empty(t, l.used)
+}
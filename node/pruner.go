@@ -0,0 +1,117 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyokan/plasma/db"
+	log2 "github.com/kyokan/plasma/log"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var prunerLogger = log2.ForSubsystem("Pruner")
+
+const pruneInterval = time.Minute
+
+// ExitPeriod is how long the root chain contract waits before finalizing an
+// exit, during which it can be challenged.
+const ExitPeriod = 7 * 24 * time.Hour
+
+// DefaultPruneRetention keeps blocks for two exit periods, so that exits
+// started around the time a block is pruned can still be challenged from a
+// complete database.
+const DefaultPruneRetention = 2 * ExitPeriod
+
+// Pruner periodically archives and prunes blocks that were created more than
+// retention ago. Blocks that haven't been submitted to the root chain yet are
+// never pruned.
+type Pruner struct {
+	quit      chan bool
+	storage   db.PlasmaStorage
+	pruner    db.PrunableStorage
+	retention time.Duration
+}
+
+func NewPruner(storage db.PlasmaStorage, pruner db.PrunableStorage, retention time.Duration) (*Pruner, error) {
+	if retention < ExitPeriod {
+		return nil, errors.New(fmt.Sprintf("prune retention %s is shorter than the exit period of %s", retention, ExitPeriod))
+	}
+	return &Pruner{
+		quit:      make(chan bool),
+		storage:   storage,
+		pruner:    pruner,
+		retention: retention,
+	}, nil
+}
+
+func (p *Pruner) Start() error {
+	go func() {
+		prunerLogger.Info("pruner started")
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+
+		for {
+			p.prune()
+
+			select {
+			case <-p.quit:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (p *Pruner) Stop() error {
+	p.quit <- true
+	return nil
+}
+
+func (p *Pruner) prune() {
+	before, err := p.pruneBefore(time.Now())
+	if err != nil {
+		log2.WithError(prunerLogger, err).Error("failed to find blocks to prune")
+		return
+	}
+
+	removed, err := p.pruner.Prune(before)
+	if err != nil {
+		log2.WithError(prunerLogger, err).Error("failed to prune blocks")
+		return
+	}
+	if removed > 0 {
+		prunerLogger.WithFields(logrus.Fields{
+			"before":  before,
+			"removed": removed,
+		}).Info("pruned spent transactions")
+	}
+}
+
+// pruneBefore returns the first block that must not be pruned yet: the first
+// block created less than retention before now, or the first unsubmitted one.
+func (p *Pruner) pruneBefore(now time.Time) (uint64, error) {
+	lastPruned, err := p.pruner.LastPrunedBlock()
+	if err != nil {
+		return 0, err
+	}
+	lastSubmitted, err := p.storage.LastSubmittedBlock()
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := uint64(now.Add(-p.retention).Unix())
+	blkNum := lastPruned + 1
+	for ; blkNum <= lastSubmitted; blkNum++ {
+		meta, err := p.storage.BlockMetaAtHeight(blkNum)
+		if err != nil {
+			return 0, err
+		}
+		if meta.CreatedAt > cutoff {
+			break
+		}
+	}
+	return blkNum, nil
+}
@@ -0,0 +1,70 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/db"
+	"github.com/stretchr/testify/require"
+)
+
+type pruneStorage struct {
+	db.PlasmaStorage
+	lastSubmitted uint64
+	createdAt     []time.Time
+}
+
+func (s *pruneStorage) LastSubmittedBlock() (uint64, error) {
+	return s.lastSubmitted, nil
+}
+
+func (s *pruneStorage) BlockMetaAtHeight(num uint64) (*chain.BlockMetadata, error) {
+	return &chain.BlockMetadata{CreatedAt: uint64(s.createdAt[num-1].Unix())}, nil
+}
+
+type prunable struct {
+	db.PrunableStorage
+	lastPruned uint64
+}
+
+func (p *prunable) LastPrunedBlock() (uint64, error) {
+	return p.lastPruned, nil
+}
+
+func TestNewPrunerRetention(t *testing.T) {
+	_, err := NewPruner(nil, nil, ExitPeriod-time.Hour)
+	require.Error(t, err)
+	_, err = NewPruner(nil, nil, ExitPeriod)
+	require.NoError(t, err)
+}
+
+func TestPrunerPruneBefore(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-DefaultPruneRetention - time.Hour)
+	storage := &pruneStorage{
+		createdAt: []time.Time{old, old, old, now.Add(-ExitPeriod), now},
+	}
+
+	tests := []struct {
+		name          string
+		lastPruned    uint64
+		lastSubmitted uint64
+		before        uint64
+	}{
+		{"nothing submitted", 0, 0, 1},
+		{"old blocks", 0, 5, 4},
+		{"unsubmitted old block", 0, 2, 3},
+		{"already pruned", 3, 5, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage.lastSubmitted = tt.lastSubmitted
+			pruner, err := NewPruner(storage, &prunable{lastPruned: tt.lastPruned}, DefaultPruneRetention)
+			require.NoError(t, err)
+			before, err := pruner.pruneBefore(now)
+			require.NoError(t, err)
+			require.Equal(t, tt.before, before)
+		})
+	}
+}
@@ -3,36 +3,76 @@ package node
 import (
 	"github.com/kyokan/plasma/db"
 	"github.com/kyokan/plasma/chain"
-	"github.com/pkg/errors"
 	"github.com/kyokan/plasma/eth"
 	"bytes"
+	"fmt"
 	"time"
 	"github.com/kyokan/plasma/util"
-	"strconv"
 		"github.com/kyokan/plasma/log"
 	"github.com/sirupsen/logrus"
+	"github.com/ethereum/go-ethereum/common"
+	"sync"
 )
 
 type TransactionConfirmer struct {
-	storage db.PlasmaStorage
+	storage     db.PlasmaStorage
+	verifier    *eth.SignatureVerifier
+	domain      eth.TypedDataDomain
+	challenges  *challengeCache
+	ownRequests *ownRequestLog
+	// mtx makes checking for and storing confirmations atomic, so that a
+	// transaction can only be confirmed once
+	mtx sync.Mutex
 }
 
 var tcfLogger = log.ForSubsystem("TransactionConfirmer")
 
-func NewTransactionConfirmer(storage db.PlasmaStorage) *TransactionConfirmer {
+// NewTransactionConfirmer returns a confirmer that checks signatures with
+// verifier, and whose typed data signatures are bound to its domain.
+func NewTransactionConfirmer(storage db.PlasmaStorage, verifier *eth.SignatureVerifier) *TransactionConfirmer {
 	return &TransactionConfirmer{
-		storage: storage,
+		storage:     storage,
+		verifier:    verifier,
+		domain:      verifier.Domain,
+		challenges:  newChallengeCache(ConfirmationChallengeTTL, MaxConfirmationChallenges, MaxConfirmationChallengesPerClient),
+		ownRequests: newOwnRequestLog(),
 	}
 }
 
-func (t *TransactionConfirmer) Confirm(blockNumber uint64, transactionIndex uint32, signatures [2]chain.Signature) (*chain.ConfirmedTransaction, error) {
+// Confirm checks and stores one confirmation signature per input: two for
+// legacy transactions, and as many as there are inputs for multi transactions.
+// Each signature must come from the owner of the output its input spends, and
+// sign either eth.ConfirmationTypedData or, if legacy signatures are
+// accepted, the hash of the transaction and the block's merkle root.
+func (t *TransactionConfirmer) Confirm(blockNumber uint64, transactionIndex uint32, signatures []chain.Signature) (*chain.ConfirmedTransaction, error) {
 	lgr := tcfLogger.WithFields(logrus.Fields{
 		"blockNumber": blockNumber,
 		"transactionIndex": transactionIndex,
 	})
 
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
 	var emptySig chain.Signature
 	confirmed, err := t.storage.FindTransactionByBlockNumTxIdx(blockNumber, transactionIndex)
+	if err != nil && !db.IsNotFound(err) {
+		return nil, err
+	}
+	if confirmed == nil {
+		return nil, NewError(ReasonNotFound, "transaction not found")
+	}
+	if len(signatures) != int(confirmed.Transaction.NumInputs()) {
+		return nil, NewError(ReasonInvalidSignature, fmt.Sprintf("expected %d confirmation signatures", confirmed.Transaction.NumInputs()))
+	}
+	alreadyConfirmed, err := t.storage.HasAuthSigs(blockNumber, transactionIndex)
+	if err != nil {
+		return nil, err
+	}
+	if alreadyConfirmed {
+		lgr.Warn("rejected confirmation of an already confirmed transaction")
+		return nil, NewError(ReasonAlreadyConfirmed, "transaction is already confirmed")
+	}
+	owners, err := t.inputOwners(confirmed)
 	if err != nil {
 		return nil, err
 	}
@@ -47,9 +87,10 @@ func (t *TransactionConfirmer) Confirm(blockNumber uint64, transactionIndex uint
 	sigBuf.Write(txHash[:])
 	sigBuf.Write(merkleRoot[:])
 	sigHash := util.Sha256(sigBuf.Bytes())
+	typed := eth.ConfirmationTypedData(t.domain, &confirmed.Transaction, blockNumber, transactionIndex, merkleRoot)
 	for i, sig := range signatures {
 		if sig == emptySig {
-			return nil, errors.New("confirmation signature is empty")
+			return nil, NewInputError(ReasonInvalidSignature, uint8(i), "has an empty confirmation signature")
 		}
 
 		input := confirmed.Transaction.InputAt(uint8(i))
@@ -57,10 +98,9 @@ func (t *TransactionConfirmer) Confirm(blockNumber uint64, transactionIndex uint
 			continue
 		}
 
-		owner := input.Owner
-		if err := eth.ValidateSignature(sigHash, sig[:], owner); err != nil {
-			lgr.Warn("rejected confirmation due to invalid signatures")
-			return nil, err
+		if err := t.verifier.Validate(typed, sigHash, sig[:], owners[i]); err != nil {
+			lgr.Warn(fmt.Sprintf("rejected confirmation due to invalid signature %d", i))
+			return nil, NewInputError(ReasonInvalidSignature, uint8(i), "has an invalid confirmation signature")
 		}
 	}
 
@@ -68,31 +108,130 @@ func (t *TransactionConfirmer) Confirm(blockNumber uint64, transactionIndex uint
 	return t.storage.ConfirmTransaction(blockNumber, transactionIndex, signatures)
 }
 
-func (t *TransactionConfirmer) GetConfirmations(sig []byte, nonce uint64, blockNumber uint64, transactionIndex uint32, outIndex uint8) ([2]chain.Signature, error) {
-	var sigs [2]chain.Signature
+// inputOwners looks up the owner of the output spent by each input, rather
+// than trusting the owners the transaction claims. Zero inputs are owned by
+// the zero address.
+func (t *TransactionConfirmer) inputOwners(confirmed *chain.ConfirmedTransaction) ([]common.Address, error) {
+	tx := &confirmed.Transaction
+	owners := make([]common.Address, tx.NumInputs())
+	for i := uint8(0); i < tx.NumInputs(); i++ {
+		input := tx.InputAt(i)
+		if input.IsZeroInput() {
+			continue
+		}
+		prevTx, err := t.storage.FindTransactionByBlockNumTxIdx(input.BlkNum, input.TxIdx)
+		if err != nil && !db.IsNotFound(err) {
+			return nil, err
+		}
+		if prevTx == nil || input.OutIdx >= prevTx.Transaction.NumOutputs() {
+			return nil, NewInputError(ReasonNotFound, i, "not found")
+		}
+		owners[i] = prevTx.Transaction.OutputAt(input.OutIdx).Owner
+	}
+	return owners, nil
+}
 
-	now := uint64(time.Now().Unix())
-	if nonce > now || now-nonce > 10 {
-		return sigs, errors.New("invalid nonce")
+// GetOwnConfirmations returns the confirmation signatures sent by the owner
+// of the output spent by input inputIndex, so that a spender can recover the
+// confirmations it sent. The owner proves itself by signing
+// eth.OwnConfirmationsRequestTypedData for the stored transaction, which
+// must expire within MaxOwnConfirmationsRequestTTL and is only answered
+// once, so no challenge has to be fetched first. The signatures of other
+// owners' inputs are left empty.
+func (t *TransactionConfirmer) GetOwnConfirmations(sig []byte, expiresAt uint64, blockNumber uint64, transactionIndex uint32, inputIndex uint8) ([]chain.Signature, error) {
+	confirmed, err := t.storage.FindTransactionByBlockNumTxIdx(blockNumber, transactionIndex)
+	if err != nil && !db.IsNotFound(err) {
+		return nil, err
+	}
+	if confirmed == nil {
+		return nil, NewError(ReasonNotFound, "transaction not found")
+	}
+	tx := &confirmed.Transaction
+	if inputIndex >= tx.NumInputs() || tx.InputAt(inputIndex).IsZeroInput() {
+		return nil, NewInputError(ReasonNotFound, inputIndex, "not found")
+	}
+	now := time.Now()
+	if expiresAt > uint64(now.Add(MaxOwnConfirmationsRequestTTL).Unix()) {
+		return nil, NewError(ReasonStaleRequest, fmt.Sprintf("request must expire within %s", MaxOwnConfirmationsRequestTTL))
 	}
 
-	tx, err := t.storage.FindTransactionByBlockNumTxIdx(blockNumber, transactionIndex)
+	owners, err := t.inputOwners(confirmed)
 	if err != nil {
-		return sigs, err
+		return nil, err
 	}
-	addr := tx.Transaction.OutputAt(outIndex).Owner
-	var buf bytes.Buffer
-	buf.Write([]byte(strconv.FormatUint(nonce, 10)))
-	buf.Write([]byte("kyo-plasma-mvp"))
-	hash := util.Keccak256(buf.Bytes())
-	if err := eth.ValidateSignature(hash[:], sig, addr); err != nil {
-		return sigs, errors.New("unauthorized to view signatures")
+	requester := owners[inputIndex]
+	data := eth.OwnConfirmationsRequestTypedData(t.domain, blockNumber, transactionIndex, inputIndex, tx.SignatureHash(), expiresAt)
+	if err := eth.ValidateTypedDataSignature(data, sig, requester); err != nil {
+		return nil, NewError(ReasonUnauthorized, "unauthorized to view signatures")
+	}
+	req := ownConfirmationsRequest{
+		blockNumber:      blockNumber,
+		transactionIndex: transactionIndex,
+		inputIndex:       inputIndex,
+		expiresAt:        expiresAt,
+	}
+	if !t.ownRequests.use(req, now) {
+		return nil, NewError(ReasonStaleRequest, "request has expired or was already used")
 	}
 
 	authSigs, err := t.storage.AuthSigsFor(blockNumber, transactionIndex)
 	if err != nil {
-		return sigs, err
+		return nil, err
+	}
+	own := make([]chain.Signature, len(authSigs))
+	for i := range authSigs {
+		if i < len(owners) && owners[i] == requester {
+			own[i] = authSigs[i]
+		}
+	}
+	// single input transactions repeat the first signature in the second slot
+	if !tx.IsMulti() && tx.Input1.IsZeroInput() && len(own) == 2 {
+		own[1] = own[0]
+	}
+	return own, nil
+}
+
+// Challenge issues a single use nonce that the owner of the given output
+// signs, as eth.ConfirmationsRequestTypedData, to read the confirmation
+// signatures of the transaction that created it. client identifies the requester, so that the
+// number of outstanding challenges can be bounded per client.
+func (t *TransactionConfirmer) Challenge(client string, blockNumber uint64, transactionIndex uint32, outIndex uint8) ([]byte, time.Time, error) {
+	if _, err := t.outputOwner(blockNumber, transactionIndex, outIndex); err != nil {
+		return nil, time.Time{}, err
+	}
+	return t.challenges.issue(client, blockNumber, transactionIndex, outIndex)
+}
+
+// Domain returns the EIP-712 domain that challenge responses and own
+// confirmations requests are signed in.
+func (t *TransactionConfirmer) Domain() eth.TypedDataDomain {
+	return t.domain
+}
+
+func (t *TransactionConfirmer) GetConfirmations(sig []byte, nonce []byte, blockNumber uint64, transactionIndex uint32, outIndex uint8) ([]chain.Signature, error) {
+	if !t.challenges.redeem(nonce, blockNumber, transactionIndex, outIndex) {
+		return nil, NewError(ReasonInvalidChallenge, "invalid or expired challenge")
+	}
+
+	addr, err := t.outputOwner(blockNumber, transactionIndex, outIndex)
+	if err != nil {
+		return nil, err
 	}
+	data := eth.ConfirmationsRequestTypedData(t.domain, blockNumber, transactionIndex, outIndex, nonce)
+	if err := eth.ValidateTypedDataSignature(data, sig, addr); err != nil {
+		return nil, NewError(ReasonUnauthorized, "unauthorized to view signatures")
+	}
+
+	return t.storage.AuthSigsFor(blockNumber, transactionIndex)
+}
 
-	return authSigs, err
+func (t *TransactionConfirmer) outputOwner(blockNumber uint64, transactionIndex uint32, outIndex uint8) (common.Address, error) {
+	tx, err := t.storage.FindTransactionByBlockNumTxIdx(blockNumber, transactionIndex)
+	if err != nil && !db.IsNotFound(err) {
+		return common.Address{}, err
+	}
+	if tx == nil || outIndex >= tx.Transaction.NumOutputs() {
+		return common.Address{}, NewError(ReasonNotFound, "output not found")
+	}
+	return tx.Transaction.OutputAt(outIndex).Owner, nil
 }
@@ -0,0 +1,123 @@
+package node
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/db"
+	"github.com/kyokan/plasma/eth"
+	"github.com/stretchr/testify/require"
+)
+
+// confirmerStorage holds a block 1 paying alice and bob, and a block 2 whose
+// only transaction spends both outputs and has the given auth sigs.
+type confirmerStorage struct {
+	db.PlasmaStorage
+	txs      map[uint64]*chain.ConfirmedTransaction
+	authSigs []chain.Signature
+}
+
+func newConfirmerStorage(alice common.Address, bob common.Address) *confirmerStorage {
+	paid := chain.ZeroTransaction()
+	paid.BlkNum = 1
+	paid.Output0 = chain.NewOutput(alice, big.NewInt(10), big.NewInt(0))
+	paid.Output1 = chain.NewOutput(bob, big.NewInt(20), big.NewInt(0))
+
+	spend := chain.ZeroTransaction()
+	spend.BlkNum = 2
+	spend.Input0 = chain.NewInput(1, 0, 0, big.NewInt(0), alice)
+	spend.Input1 = chain.NewInput(1, 0, 1, big.NewInt(0), bob)
+	spend.Output0 = chain.NewOutput(bob, big.NewInt(30), big.NewInt(0))
+
+	return &confirmerStorage{
+		txs: map[uint64]*chain.ConfirmedTransaction{
+			1: {Transaction: *paid},
+			2: {Transaction: *spend},
+		},
+		authSigs: []chain.Signature{chain.Signature(chain.RandomConfirmationSig()), chain.Signature(chain.RandomConfirmationSig())},
+	}
+}
+
+func (s *confirmerStorage) FindTransactionByBlockNumTxIdx(blkNum uint64, txIdx uint32) (*chain.ConfirmedTransaction, error) {
+	if txIdx != 0 {
+		return nil, nil
+	}
+	return s.txs[blkNum], nil
+}
+
+func (s *confirmerStorage) AuthSigsFor(blockNumber uint64, transactionIndex uint32) ([]chain.Signature, error) {
+	return s.authSigs, nil
+}
+
+func newConfirmerSigner(t *testing.T) *eth.LocalSigner {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return eth.NewLocalSigner(key)
+}
+
+func TestGetOwnConfirmations(t *testing.T) {
+	alice := newConfirmerSigner(t)
+	bob := newConfirmerSigner(t)
+	storage := newConfirmerStorage(alice.Address(), bob.Address())
+	domain := eth.NewPlasmaDomain(big.NewInt(1), chain.RandomAddress())
+	confirmer := NewTransactionConfirmer(storage, eth.NewSignatureVerifier(domain, true))
+	spendHash := storage.txs[2].Transaction.SignatureHash()
+	expiresAt := uint64(time.Now().Add(time.Minute).Unix())
+
+	// request has signer ask for its confirmations of input inputIdx of the
+	// transaction with hash txHash in block 2
+	request := func(signer eth.Signer, inputIdx uint8, txHash []byte, expiresAt uint64) []byte {
+		sig, err := signer.SignTypedData(eth.OwnConfirmationsRequestTypedData(domain, 2, 0, inputIdx, txHash, expiresAt))
+		require.NoError(t, err)
+		return sig[:]
+	}
+
+	tests := []struct {
+		name      string
+		signer    eth.Signer
+		signedIdx uint8
+		txHash    []byte
+		expiresAt uint64
+		inputIdx  uint8
+		expected  []chain.Signature
+		reason    ErrorReason
+	}{
+		{"first input", alice, 0, spendHash, expiresAt, 0, []chain.Signature{storage.authSigs[0], {}}, ""},
+		{"second input", bob, 1, spendHash, expiresAt, 1, []chain.Signature{{}, storage.authSigs[1]}, ""},
+		{"someone else's input", bob, 0, spendHash, expiresAt + 1, 0, nil, ReasonUnauthorized},
+		{"signed for another input", alice, 1, spendHash, expiresAt + 2, 0, nil, ReasonUnauthorized},
+		{"signed for another transaction", alice, 0, chain.RandomSig()[:32], expiresAt + 3, 0, nil, ReasonUnauthorized},
+		{"expired", alice, 0, spendHash, uint64(time.Now().Add(-time.Second).Unix()), 0, nil, ReasonStaleRequest},
+		{"expires too late", alice, 0, spendHash, uint64(time.Now().Add(MaxOwnConfirmationsRequestTTL + time.Minute).Unix()), 0, nil, ReasonStaleRequest},
+		{"no such input", alice, 0, spendHash, expiresAt + 4, 2, nil, ReasonNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := request(tt.signer, tt.signedIdx, tt.txHash, tt.expiresAt)
+			sigs, err := confirmer.GetOwnConfirmations(sig, tt.expiresAt, 2, 0, tt.inputIdx)
+			if tt.reason != "" {
+				require.Error(t, err)
+				require.Equal(t, tt.reason, AsError(err).Reason)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, sigs)
+		})
+	}
+
+	// requests are single use, so a captured request cannot be replayed
+	expiresAt += 10
+	sig := request(alice, 0, spendHash, expiresAt)
+	_, err := confirmer.GetOwnConfirmations(sig, expiresAt, 2, 0, 0)
+	require.NoError(t, err)
+	_, err = confirmer.GetOwnConfirmations(sig, expiresAt, 2, 0, 0)
+	require.Equal(t, ReasonStaleRequest, AsError(err).Reason)
+
+	_, err = confirmer.GetOwnConfirmations(sig, expiresAt, 3, 0, 0)
+	require.Equal(t, ReasonNotFound, AsError(err).Reason)
+}
@@ -0,0 +1,66 @@
+package root
+
+import (
+	"context"
+
+	"github.com/kyokan/plasma/node"
+	"github.com/kyokan/plasma/rpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var reasonCodes = map[node.ErrorReason]codes.Code{
+	node.ReasonNotFound:           codes.NotFound,
+	node.ReasonDoubleSpend:        codes.FailedPrecondition,
+	node.ReasonInvalidSignature:   codes.InvalidArgument,
+	node.ReasonInsufficientInputs: codes.InvalidArgument,
+	node.ReasonInvalidTransaction: codes.InvalidArgument,
+	node.ReasonMempoolFull:        codes.ResourceExhausted,
+	node.ReasonAlreadyConfirmed:   codes.AlreadyExists,
+	node.ReasonUnauthorized:       codes.PermissionDenied,
+	node.ReasonInvalidChallenge:   codes.FailedPrecondition,
+	node.ReasonTooManyChallenges:  codes.ResourceExhausted,
+	node.ReasonStaleRequest:       codes.FailedPrecondition,
+	node.ReasonPruned:             codes.NotFound,
+	node.ReasonInvalidHeight:      codes.InvalidArgument,
+}
+
+// translateErrors is a grpc.UnaryServerInterceptor that turns the errors
+// handlers return into gRPC statuses. A node.Error gets the code for its
+// reason and a pb.ErrorDetail, and any other error is codes.Internal.
+func translateErrors(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	res, err := handler(ctx, req)
+	if err == nil {
+		return res, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return res, err
+	}
+	return res, errorStatus(err).Err()
+}
+
+func errorStatus(err error) *status.Status {
+	e := node.AsError(err)
+	if e == nil {
+		return status.New(codes.Internal, err.Error())
+	}
+
+	code, ok := reasonCodes[e.Reason]
+	if !ok {
+		code = codes.Unknown
+	}
+	st := status.New(code, e.Message)
+	detail := &pb.ErrorDetail{
+		Reason: string(e.Reason),
+	}
+	if e.Input != node.NoInput {
+		detail.HasInput = true
+		detail.InputIndex = uint32(e.Input)
+	}
+	withDetail, err := st.WithDetails(detail)
+	if err != nil {
+		return st
+	}
+	return withDetail
+}
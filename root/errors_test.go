@@ -0,0 +1,51 @@
+package root
+
+import (
+	"testing"
+
+	"github.com/kyokan/plasma/db"
+	"github.com/kyokan/plasma/node"
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   codes.Code
+		reason node.ErrorReason
+		input  int
+	}{
+		{"node error", node.NewError(node.ReasonMempoolFull, "mempool is full"), codes.ResourceExhausted, node.ReasonMempoolFull, node.NoInput},
+		{"input error", node.NewInputError(node.ReasonInvalidSignature, 1, "has an invalid signature"), codes.InvalidArgument, node.ReasonInvalidSignature, 1},
+		{"wrapped", errors.Wrap(node.NewError(node.ReasonUnauthorized, "unauthorized"), "failed"), codes.PermissionDenied, node.ReasonUnauthorized, node.NoInput},
+		{"not found", &db.NotFoundError{What: "block 4"}, codes.NotFound, node.ReasonNotFound, node.NoInput},
+		{"invalid height", &db.InvalidHeightError{Height: 0}, codes.InvalidArgument, node.ReasonInvalidHeight, node.NoInput},
+		{"pruned", &db.PrunedError{What: "block 2 is incomplete"}, codes.NotFound, node.ReasonPruned, node.NoInput},
+		{"conflicting spend", &db.ConflictingSpendError{TxIdx: 3, InputIdx: 1}, codes.FailedPrecondition, node.ReasonDoubleSpend, node.NoInput},
+		{"node's own fault", errors.New("disk full"), codes.Internal, "", node.NoInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := errorStatus(tt.err)
+			require.Equal(t, tt.code, st.Code())
+			require.Equal(t, errors.Cause(tt.err).Error(), st.Message())
+			if tt.reason == "" {
+				require.Empty(t, st.Details())
+				return
+			}
+
+			require.Len(t, st.Details(), 1)
+			detail := st.Details()[0].(*pb.ErrorDetail)
+			require.Equal(t, string(tt.reason), detail.Reason)
+			require.Equal(t, tt.input != node.NoInput, detail.HasInput)
+			if detail.HasInput {
+				require.Equal(t, uint32(tt.input), detail.InputIndex)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package root
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/kyokan/plasma/node"
+)
+
+type HealthServer struct {
+	ctx     context.Context
+	checker *node.HealthChecker
+	limiter *RPCLimiter
+}
+
+type healthResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// NewHealthServer creates the node's HTTP server. limiter may be nil if RPC
+// counters should not be served.
+func NewHealthServer(ctx context.Context, checker *node.HealthChecker, limiter *RPCLimiter) *HealthServer {
+	return &HealthServer{
+		ctx:     ctx,
+		checker: checker,
+		limiter: limiter,
+	}
+}
+
+func (h *HealthServer) Start(port int) error {
+	s := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: h.handler(),
+	}
+
+	go func() {
+		if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Println("error", err)
+		}
+	}()
+
+	go func() {
+		<-h.ctx.Done()
+		s.Close()
+	}()
+
+	log.Printf("Started health server on port %d", port)
+
+	return nil
+}
+
+func (h *HealthServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, h.checker.Alive())
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, h.checker.Ready())
+	})
+	if h.limiter != nil {
+		mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+			if err := h.limiter.WriteMetrics(w); err != nil {
+				log.Println("failed to write metrics", err)
+			}
+		})
+	}
+	return mux
+}
+
+func writeHealth(w http.ResponseWriter, err error) {
+	res := &healthResponse{
+		Status: "ok",
+	}
+	code := http.StatusOK
+	if err != nil {
+		res.Status = "unavailable"
+		res.Reason = err.Error()
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(res)
+}
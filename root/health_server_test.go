@@ -0,0 +1,94 @@
+package root
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyokan/plasma/chain"
+	"github.com/kyokan/plasma/db"
+	"github.com/kyokan/plasma/eth"
+	"github.com/kyokan/plasma/node"
+	"github.com/stretchr/testify/require"
+)
+
+// healthStorage is a chain of five submitted blocks whose pollers have seen
+// Ethereum block 100, unless err is set.
+type healthStorage struct {
+	db.PlasmaStorage
+	err error
+}
+
+func (s *healthStorage) LatestBlock() (*chain.Block, error) {
+	return &chain.Block{Header: &chain.BlockHeader{Number: 5}}, s.err
+}
+
+func (s *healthStorage) LastSubmittedBlock() (uint64, error) {
+	return 5, nil
+}
+
+func (s *healthStorage) LastDepositPoll() (uint64, error) {
+	return 100, nil
+}
+
+func (s *healthStorage) LastTxExitPoll() (uint64, error) {
+	return 100, nil
+}
+
+type healthClient struct {
+	eth.Client
+	err error
+}
+
+func (c *healthClient) EthereumBlockHeight() (uint64, error) {
+	return 100, c.err
+}
+
+func TestHealthServerEndpoints(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		storageErr error
+		clientErr  error
+		code       int
+		reason     string
+	}{
+		{"alive", "/healthz", nil, errors.New("connection refused"), http.StatusOK, ""},
+		{"database unreadable", "/healthz", errors.New("closed"), nil, http.StatusServiceUnavailable, "closed"},
+		{"ready", "/readyz", nil, nil, http.StatusOK, ""},
+		{"not ready", "/readyz", nil, errors.New("connection refused"), http.StatusServiceUnavailable, "ethereum node is unreachable: connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := node.NewHealthChecker(&healthClient{err: tt.clientErr}, &healthStorage{err: tt.storageErr}, 10, time.Minute)
+			server := NewHealthServer(context.Background(), checker, nil)
+
+			rec := httptest.NewRecorder()
+			server.handler().ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+			require.Equal(t, tt.code, rec.Code)
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var res healthResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			require.Equal(t, tt.reason, res.Reason)
+			if tt.code == http.StatusOK {
+				require.Equal(t, "ok", res.Status)
+			} else {
+				require.Equal(t, "unavailable", res.Status)
+			}
+		})
+	}
+
+	// without snapshots or a limiter, their endpoints are not served
+	checker := node.NewHealthChecker(&healthClient{}, &healthStorage{}, 10, time.Minute)
+	for _, path := range []string{"/snapshot", "/metrics"} {
+		rec := httptest.NewRecorder()
+		NewHealthServer(context.Background(), checker, nil).handler().ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,322 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/protobuf/proto"
+	"github.com/kyokan/plasma/config"
+	"github.com/kyokan/plasma/rpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// maxBuckets bounds how many clients or addresses are tracked per method.
+// Idle buckets are dropped once it is reached, and if that is not enough,
+// some of the rest.
+const maxBuckets = 100000
+
+const (
+	rejectRateLimited = "rate_limited"
+	rejectTooLarge    = "too_large"
+	rejectOverloaded  = "overloaded"
+)
+
+// RPCLimiter enforces config.RPCLimits on every RPC, and counts requests and
+// rejections per method.
+//
+// Per address limits apply to the address a request asks about. That address
+// is not authenticated, so it is only checked once a request has passed its
+// per IP limit. Send has no per address limit: the input owners it names are
+// only verified along with the transaction's signatures, and limiting them
+// first would let anyone lock an owner out by sending bogus transactions in
+// their name.
+type RPCLimiter struct {
+	limits *config.RPCLimits
+
+	mtx     sync.Mutex
+	methods map[string]*methodLimiter
+}
+
+type methodLimiter struct {
+	limits    config.MethodLimits
+	ips       *bucketSet
+	addresses *bucketSet
+	slots     chan struct{}
+
+	requests    uint64
+	rateLimited uint64
+	tooLarge    uint64
+	overloaded  uint64
+}
+
+// NewRPCLimiter returns a limiter enforcing limits, or the default limits if
+// it is nil.
+func NewRPCLimiter(limits *config.RPCLimits) *RPCLimiter {
+	if limits == nil {
+		limits = config.DefaultRPCLimits()
+	}
+	return &RPCLimiter{
+		limits:  limits,
+		methods: make(map[string]*methodLimiter),
+	}
+}
+
+// MaxMessageSize is the largest request any method accepts, which bounds
+// what the server reads before a request is decoded. It is 0 if some method
+// has no size limit.
+func (l *RPCLimiter) MaxMessageSize() int {
+	max := l.limits.Default.MaxRequestSize
+	if max <= 0 {
+		return 0
+	}
+	for method := range l.limits.Methods {
+		size := l.limits.For(method).MaxRequestSize
+		if size <= 0 {
+			return 0
+		}
+		if size > max {
+			max = size
+		}
+	}
+	return max
+}
+
+// Intercept is a grpc.UnaryServerInterceptor that rejects requests beyond
+// their method's limits with codes.ResourceExhausted.
+func (l *RPCLimiter) Intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	m := l.method(methodName(info.FullMethod))
+	atomic.AddUint64(&m.requests, 1)
+
+	if msg, ok := req.(proto.Message); ok && m.limits.MaxRequestSize > 0 && proto.Size(msg) > m.limits.MaxRequestSize {
+		atomic.AddUint64(&m.tooLarge, 1)
+		return nil, status.Error(codes.ResourceExhausted, fmt.Sprintf("request is larger than %d bytes", m.limits.MaxRequestSize))
+	}
+
+	now := time.Now()
+	if m.ips != nil && !m.ips.allow(clientIP(ctx), now) {
+		atomic.AddUint64(&m.rateLimited, 1)
+		return nil, status.Error(codes.ResourceExhausted, "too many requests, try again later")
+	}
+	if m.addresses != nil {
+		for _, addr := range requestAddresses(req) {
+			if !m.addresses.allow(addr.Hex(), now) {
+				atomic.AddUint64(&m.rateLimited, 1)
+				return nil, status.Error(codes.ResourceExhausted, fmt.Sprintf("too many requests for %s, try again later", addr.Hex()))
+			}
+		}
+	}
+
+	if m.slots != nil {
+		select {
+		case m.slots <- struct{}{}:
+			defer func() { <-m.slots }()
+		default:
+			atomic.AddUint64(&m.overloaded, 1)
+			return nil, status.Error(codes.ResourceExhausted, "too many concurrent requests, try again later")
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+func (l *RPCLimiter) method(name string) *methodLimiter {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if m, ok := l.methods[name]; ok {
+		return m
+	}
+	limits := l.limits.For(name)
+	m := &methodLimiter{
+		limits:    limits,
+		ips:       newBucketSet(limits.IPRate, limits.IPBurst),
+		addresses: newBucketSet(limits.AddressRate, limits.AddressBurst),
+	}
+	if limits.MaxConcurrent > 0 {
+		m.slots = make(chan struct{}, limits.MaxConcurrent)
+	}
+	l.methods[name] = m
+	return m
+}
+
+// WriteMetrics writes the request and rejection counters in the Prometheus
+// text format.
+func (l *RPCLimiter) WriteMetrics(w io.Writer) error {
+	l.mtx.Lock()
+	methods := make(map[string]*methodLimiter)
+	var names []string
+	for name, m := range l.methods {
+		methods[name] = m
+		names = append(names, name)
+	}
+	l.mtx.Unlock()
+	sort.Strings(names)
+
+	lines := []string{
+		"# TYPE plasma_rpc_requests_total counter",
+	}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("plasma_rpc_requests_total{method=%q} %d", name, atomic.LoadUint64(&methods[name].requests)))
+	}
+	lines = append(lines, "# TYPE plasma_rpc_rejected_total counter")
+	for _, name := range names {
+		m := methods[name]
+		lines = append(lines,
+			fmt.Sprintf("plasma_rpc_rejected_total{method=%q,reason=%q} %d", name, rejectRateLimited, atomic.LoadUint64(&m.rateLimited)),
+			fmt.Sprintf("plasma_rpc_rejected_total{method=%q,reason=%q} %d", name, rejectTooLarge, atomic.LoadUint64(&m.tooLarge)),
+			fmt.Sprintf("plasma_rpc_rejected_total{method=%q,reason=%q} %d", name, rejectOverloaded, atomic.LoadUint64(&m.overloaded)),
+		)
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// methodName strips the service from a full method name like
+// "/pb.Root/Send".
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
+
+// clientIP returns the address of the peer that sent the request in ctx,
+// without its port, or an empty string if it is unknown.
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// requestAddresses returns the distinct addresses a request is about.
+func requestAddresses(req interface{}) []common.Address {
+	var raw [][]byte
+	switch r := req.(type) {
+	case *pb.GetBalanceRequest:
+		raw = append(raw, r.GetAddress())
+	case *pb.GetOutputsRequest:
+		raw = append(raw, r.GetAddress())
+	}
+
+	seen := make(map[common.Address]bool)
+	var addrs []common.Address
+	for _, b := range raw {
+		addr := common.BytesToAddress(b)
+		if len(b) == 0 || addr == (common.Address{}) || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// bucketSet is a token bucket per key, each holding up to burst tokens and
+// refilling at rate tokens per second.
+type bucketSet struct {
+	mtx     sync.Mutex
+	rate    float64
+	burst   float64
+	max     int
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newBucketSet returns nil, which allows everything, if rate is not
+// positive.
+func newBucketSet(rate float64, burst int) *bucketSet {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucketSet{
+		rate:    rate,
+		burst:   float64(burst),
+		max:     maxBuckets,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token from key's bucket, and reports whether there was one.
+func (s *bucketSet) allow(key string, now time.Time) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		if len(s.buckets) >= s.max {
+			s.evict(now)
+		}
+		b = &tokenBucket{
+			tokens: s.burst,
+			last:   now,
+		}
+		s.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * s.rate
+	if b.tokens > s.burst {
+		b.tokens = s.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// evict forgets buckets that have refilled completely, since a new bucket
+// would be the same. If fewer than a tenth of them have, it also forgets the
+// fullest of the rest until a tenth of the room is free, so the map can't
+// grow without bound, and the clients closest to their limits are the last
+// ones to get a fresh bucket.
+func (s *bucketSet) evict(now time.Time) {
+	type bucketTokens struct {
+		key    string
+		tokens float64
+	}
+	var rest []bucketTokens
+	for key, b := range s.buckets {
+		tokens := b.tokens + now.Sub(b.last).Seconds()*s.rate
+		if tokens >= s.burst {
+			delete(s.buckets, key)
+			continue
+		}
+		rest = append(rest, bucketTokens{key, tokens})
+	}
+
+	target := s.max - s.max/10
+	if target >= s.max {
+		target = s.max - 1
+	}
+	if len(rest) <= target {
+		return
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		return rest[i].tokens > rest[j].tokens
+	})
+	for _, b := range rest[:len(rest)-target] {
+		delete(s.buckets, b.key)
+	}
+}
@@ -0,0 +1,222 @@
+package root
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/kyokan/plasma/config"
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestBucketSet(t *testing.T) {
+	require.Nil(t, newBucketSet(0, 10))
+
+	// two tokens, refilling at one every two seconds
+	s := newBucketSet(0.5, 2)
+	now := time.Now()
+	require.True(t, s.allow("a", now))
+	require.True(t, s.allow("a", now))
+	require.False(t, s.allow("a", now))
+	require.True(t, s.allow("b", now))
+
+	require.False(t, s.allow("a", now.Add(time.Second)))
+	require.True(t, s.allow("a", now.Add(3*time.Second)))
+	require.False(t, s.allow("a", now.Add(3*time.Second)))
+
+	// buckets never hold more than burst tokens
+	later := now.Add(time.Hour)
+	require.True(t, s.allow("a", later))
+	require.True(t, s.allow("a", later))
+	require.False(t, s.allow("a", later))
+}
+
+func TestBucketSetIsBounded(t *testing.T) {
+	s := newBucketSet(1, 3)
+	s.max = 10
+	now := time.Now()
+
+	// drain one of mallory's buckets, and take a token from everyone else's
+	for i := 0; i < 3; i++ {
+		require.True(t, s.allow("mallory", now))
+	}
+	for i := 0; i < 100; i++ {
+		s.allow(fmt.Sprintf("client %d", i), now)
+		require.True(t, len(s.buckets) <= s.max, "%d buckets", len(s.buckets))
+	}
+	require.False(t, s.allow("mallory", now), "the drained bucket must not be evicted")
+
+	// once every bucket has refilled, they are all dropped
+	s.allow("new", now.Add(time.Minute))
+	require.Len(t, s.buckets, 1)
+}
+
+func TestClientIP(t *testing.T) {
+	require.Equal(t, "", clientIP(context.Background()))
+
+	tcp := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 6545}})
+	require.Equal(t, "10.0.0.7", clientIP(tcp))
+	v6 := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("::1"), Port: 6545}})
+	require.Equal(t, "::1", clientIP(v6))
+	unix := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.UnixAddr{Name: "plasma.sock", Net: "unix"}})
+	require.Equal(t, "plasma.sock", clientIP(unix))
+}
+
+func TestRequestAddresses(t *testing.T) {
+	alice := common.HexToAddress("0x627306090abab3a6e1400e9345bc60c78a8bef57")
+	bob := common.HexToAddress("0xf17f52151ebef6c7334fad080c5704d77216b732")
+
+	tests := []struct {
+		name     string
+		req      interface{}
+		expected []common.Address
+	}{
+		{"balance", &pb.GetBalanceRequest{Address: alice.Bytes()}, []common.Address{alice}},
+		{"outputs", &pb.GetOutputsRequest{Address: bob.Bytes()}, []common.Address{bob}},
+		{"no address", &pb.GetBalanceRequest{}, nil},
+		{"zero address", &pb.GetOutputsRequest{Address: common.Address{}.Bytes()}, nil},
+		{"send", &pb.SendRequest{Confirmed: &pb.ConfirmedTransaction{Transaction: &pb.Transaction{
+			Input0: &pb.Input{Owner: alice.Bytes()},
+		}}}, nil},
+		{"other methods", &pb.GetBlockRequest{Number: 1}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, requestAddresses(tt.req))
+		})
+	}
+}
+
+// testLimits allows two GetBalance calls per IP and one per address, Send
+// requests of up to 64 bytes with a per address limit that must not apply and one GetBlock at a time, and leaves other
+// methods unlimited.
+func testLimits() *config.RPCLimits {
+	limits := &config.RPCLimits{}
+	limits.Override(map[string]config.MethodLimits{
+		"GetBalance": {IPRate: 0.001, IPBurst: 2, AddressRate: 0.001, AddressBurst: 1},
+		"Send":       {AddressRate: 0.001, AddressBurst: 1, MaxRequestSize: 64},
+		"getblock":   {MaxConcurrent: 1},
+	})
+	return limits
+}
+
+func intercept(l *RPCLimiter, ip string, method string, req interface{}) error {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 6545}})
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.Root/" + method}
+	_, err := l.Intercept(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	return err
+}
+
+func TestRPCLimiterIntercept(t *testing.T) {
+	alice := common.HexToAddress("0x627306090abab3a6e1400e9345bc60c78a8bef57").Bytes()
+	bob := common.HexToAddress("0xf17f52151ebef6c7334fad080c5704d77216b732").Bytes()
+
+	t.Run("per IP", func(t *testing.T) {
+		l := NewRPCLimiter(testLimits())
+		require.NoError(t, intercept(l, "10.0.0.1", "GetBalance", &pb.GetBalanceRequest{}))
+		require.NoError(t, intercept(l, "10.0.0.1", "GetBalance", &pb.GetBalanceRequest{}))
+		err := intercept(l, "10.0.0.1", "GetBalance", &pb.GetBalanceRequest{})
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.NoError(t, intercept(l, "10.0.0.2", "GetBalance", &pb.GetBalanceRequest{}))
+		// limits are per method
+		require.NoError(t, intercept(l, "10.0.0.1", "BlockHeight", &pb.EmptyRequest{}))
+	})
+
+	t.Run("per address", func(t *testing.T) {
+		l := NewRPCLimiter(testLimits())
+		require.NoError(t, intercept(l, "10.0.0.1", "GetBalance", &pb.GetBalanceRequest{Address: alice}))
+		err := intercept(l, "10.0.0.2", "GetBalance", &pb.GetBalanceRequest{Address: alice})
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.Contains(t, status.Convert(err).Message(), common.BytesToAddress(alice).Hex())
+		require.NoError(t, intercept(l, "10.0.0.2", "GetBalance", &pb.GetBalanceRequest{Address: bob}))
+
+		// transactions naming alice as the owner of their inputs can't use
+		// up her limit before their signatures are checked
+		send := &pb.SendRequest{Confirmed: &pb.ConfirmedTransaction{Transaction: &pb.Transaction{
+			Input0: &pb.Input{Owner: alice},
+		}}}
+		require.NoError(t, intercept(l, "10.0.0.3", "Send", send))
+		require.NoError(t, intercept(l, "10.0.0.4", "Send", send))
+	})
+
+	t.Run("request size", func(t *testing.T) {
+		l := NewRPCLimiter(testLimits())
+		small := &pb.SendRequest{Confirmed: &pb.ConfirmedTransaction{Signatures: [][]byte{make([]byte, 32)}}}
+		large := &pb.SendRequest{Confirmed: &pb.ConfirmedTransaction{Signatures: [][]byte{make([]byte, 65)}}}
+		require.NoError(t, intercept(l, "10.0.0.1", "Send", small))
+		require.Equal(t, codes.ResourceExhausted, status.Code(intercept(l, "10.0.0.1", "Send", large)))
+		// other methods have no size limit, so the server reads any size
+		require.Zero(t, l.MaxMessageSize())
+	})
+
+	t.Run("concurrency", func(t *testing.T) {
+		l := NewRPCLimiter(testLimits())
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1")}})
+		info := &grpc.UnaryServerInfo{FullMethod: "/pb.Root/GetBlock"}
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			_, err := l.Intercept(ctx, &pb.GetBlockRequest{}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				close(entered)
+				<-release
+				return nil, nil
+			})
+			done <- err
+		}()
+		<-entered
+
+		err := intercept(l, "10.0.0.2", "GetBlock", &pb.GetBlockRequest{})
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		close(release)
+		require.NoError(t, <-done)
+		require.NoError(t, intercept(l, "10.0.0.2", "GetBlock", &pb.GetBlockRequest{}))
+	})
+}
+
+func TestRPCLimiterDefaults(t *testing.T) {
+	l := NewRPCLimiter(nil)
+	defaults := config.DefaultRPCLimits()
+	require.Equal(t, defaults.Default.MaxRequestSize, l.MaxMessageSize())
+
+	m := l.method("GetOutputs")
+	require.Equal(t, defaults.For("getoutputs"), m.limits)
+	require.NotNil(t, m.ips)
+	require.NotNil(t, m.addresses)
+	require.Equal(t, defaults.Methods["getoutputs"].MaxConcurrent, cap(m.slots))
+	require.True(t, m == l.method("GetOutputs"))
+
+	// methods without their own limits only have the default per IP limit
+	m = l.method("BlockHeight")
+	require.NotNil(t, m.ips)
+	require.Nil(t, m.addresses)
+	require.Nil(t, m.slots)
+}
+
+func TestRPCLimiterMetrics(t *testing.T) {
+	l := NewRPCLimiter(testLimits())
+	for i := 0; i < 3; i++ {
+		intercept(l, "10.0.0.1", "GetBalance", &pb.GetBalanceRequest{})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, l.WriteMetrics(&buf))
+	metrics := buf.String()
+	require.True(t, strings.HasPrefix(metrics, "# TYPE plasma_rpc_requests_total counter\n"))
+	require.Contains(t, metrics, `plasma_rpc_requests_total{method="GetBalance"} 3`)
+	require.Contains(t, metrics, `plasma_rpc_rejected_total{method="GetBalance",reason="rate_limited"} 1`)
+	require.Contains(t, metrics, `plasma_rpc_rejected_total{method="GetBalance",reason="too_large"} 0`)
+}
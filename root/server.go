@@ -1,7 +1,10 @@
 package root
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/kyokan/plasma/chain"
@@ -9,10 +12,15 @@ import (
 	"github.com/kyokan/plasma/rpc"
 	"github.com/kyokan/plasma/rpc/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log"
 	"net"
 	"github.com/kyokan/plasma/node"
-	"github.com/pkg/errors"
+	"github.com/kyokan/plasma/eth"
+	"github.com/kyokan/plasma/config"
+	"github.com/kyokan/plasma/merkle"
+	"github.com/kyokan/plasma/util"
 	)
 
 type Server struct {
@@ -20,25 +28,50 @@ type Server struct {
 	ctx       context.Context
 	mPool     *node.Mempool
 	confirmer *node.TransactionConfirmer
+	client    eth.Client
+	health    *node.HealthChecker
+	limiter   *RPCLimiter
 }
 
-func NewServer(ctx context.Context, storage db.PlasmaStorage, mPool *node.Mempool, confirmer *node.TransactionConfirmer) (*Server) {
+func NewServer(ctx context.Context, storage db.PlasmaStorage, mPool *node.Mempool, confirmer *node.TransactionConfirmer, client eth.Client, health *node.HealthChecker, limiter *RPCLimiter) (*Server) {
 	return &Server{
 		storage:   storage,
 		ctx:       ctx,
 		mPool:     mPool,
 		confirmer: confirmer,
+		client:    client,
+		health:    health,
+		limiter:   limiter,
 	}
 }
 
-func (r *Server) Start(rpcPort int) error {
+// Start serves RPCs on rpcPort, over TLS if tlsConfig is enabled. It returns
+// once the server is listening.
+func (r *Server) Start(rpcPort int, tlsConfig *TLSConfig) error {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(chainUnaryInterceptors(authenticateOperators, r.limiter.Intercept, translateErrors)),
+		grpc.StreamInterceptor(authenticateOperatorStreams),
+	}
+	if size := r.limiter.MaxMessageSize(); size > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(size))
+	}
+	if tlsConfig.Enabled() {
+		creds, err := serverCredentials(tlsConfig)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	} else {
+		log.Println("No TLS certificate set, serving RPCs in plaintext.")
+	}
+
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", rpcPort))
 	if err != nil {
 		log.Println("error", err)
 		return err
 	}
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(opts...)
 	pb.RegisterRootServer(s, r)
 
 	go func() {
@@ -57,6 +90,20 @@ func (r *Server) Start(rpcPort int) error {
 	return nil
 }
 
+// chainUnaryInterceptors runs interceptors in order before the handler.
+func chainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
 func (r *Server) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
 	addr := common.BytesToAddress(req.Address)
 	bal, err := r.storage.Balance(&addr)
@@ -71,20 +118,28 @@ func (r *Server) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb
 
 func (r *Server) GetOutputs(ctx context.Context, req *pb.GetOutputsRequest) (*pb.GetOutputsResponse, error) {
 	addr := common.BytesToAddress(req.Address)
-	var txs []chain.ConfirmedTransaction
+	var outputs []db.OwnedOutput
 	var err error
 	if req.Spendable {
-		txs, err = r.storage.SpendableTxs(&addr)
+		outputs, err = r.storage.SpendableOutputs(&addr)
 	} else {
-		txs, err = r.storage.UTXOs(&addr)
+		outputs, err = r.storage.Outputs(&addr)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	txs := make([]chain.ConfirmedTransaction, len(outputs))
+	indexes := make([]uint32, len(outputs))
+	for i, owned := range outputs {
+		txs[i] = owned.Transaction
+		indexes[i] = uint32(owned.OutIdx)
+	}
+
 	return &pb.GetOutputsResponse{
 		ConfirmedTransactions: rpc.SerializeConfirmedTxs(txs),
+		OutputIndexes:         indexes,
 	}, nil
 }
 
@@ -123,7 +178,7 @@ func (r *Server) GetBlock(ctx context.Context, req *pb.GetBlockRequest) (*pb.Get
 
 func (r *Server) Send(ctx context.Context, req *pb.SendRequest) (*pb.SendResponse, error) {
 	if req == nil {
-		return nil, errors.New("no request provided")
+		return nil, node.NewError(node.ReasonInvalidTransaction, "no request provided")
 	}
 
 	confirmed := rpc.DeserializeConfirmedTx(req.Confirmed)
@@ -142,15 +197,19 @@ func (r *Server) Send(ctx context.Context, req *pb.SendRequest) (*pb.SendRespons
 }
 
 func (r *Server) Confirm(ctx context.Context, req *pb.ConfirmRequest) (*pb.ConfirmedTransaction, error) {
-	var sig0 chain.Signature
-	copy(sig0[:], req.AuthSig0)
-	var sig1 chain.Signature
-	copy(sig1[:], req.AuthSig1)
+	var sigs []chain.Signature
+	if len(req.AuthSigs) > 0 {
+		sigs = make([]chain.Signature, len(req.AuthSigs))
+		for i, sig := range req.AuthSigs {
+			copy(sigs[i][:], sig)
+		}
+	} else {
+		sigs = make([]chain.Signature, 2)
+		copy(sigs[0][:], req.AuthSig0)
+		copy(sigs[1][:], req.AuthSig1)
+	}
 
-	tx, err := r.confirmer.Confirm(req.BlockNumber, req.TransactionIndex, [2]chain.Signature{
-		sig0,
-		sig1,
-	})
+	tx, err := r.confirmer.Confirm(req.BlockNumber, req.TransactionIndex, sigs)
 	if err != nil {
 		return nil, err
 	}
@@ -158,16 +217,57 @@ func (r *Server) Confirm(ctx context.Context, req *pb.ConfirmRequest) (*pb.Confi
 	return rpc.SerializeConfirmedTx(tx), nil
 }
 
+func (r *Server) GetConfirmationsChallenge(ctx context.Context, req *pb.GetConfirmationsChallengeRequest) (*pb.GetConfirmationsChallengeResponse, error) {
+	if req.OutputIndex > 255 {
+		return nil, node.NewError(node.ReasonNotFound, "output not found")
+	}
+	nonce, expires, err := r.confirmer.Challenge(clientIP(ctx), req.BlockNumber, req.TransactionIndex, uint8(req.OutputIndex))
+	if err != nil {
+		return nil, err
+	}
+
+	domain := r.confirmer.Domain()
+	return &pb.GetConfirmationsChallengeResponse{
+		Nonce:           nonce,
+		ExpiresAt:       uint64(expires.Unix()),
+		ContractAddress: domain.VerifyingContract.Bytes(),
+		ChainId:         rpc.SerializeBig(domain.ChainID),
+	}, nil
+}
+
 func (r *Server) GetConfirmations(ctx context.Context, req *pb.GetConfirmationsRequest) (*pb.GetConfirmationsResponse, error) {
+	if req.OutputIndex > 255 {
+		return nil, node.NewError(node.ReasonNotFound, "output not found")
+	}
 	sigs, err := r.confirmer.GetConfirmations(req.Sig, req.Nonce, req.BlockNumber, req.TransactionIndex, uint8(req.OutputIndex))
 	if err != nil {
 		return nil, err
 	}
+	return confirmationsResponse(sigs), nil
+}
 
-	return &pb.GetConfirmationsResponse{
-		AuthSig0: sigs[0][:],
-		AuthSig1: sigs[1][:],
-	}, nil
+func (r *Server) GetOwnConfirmations(ctx context.Context, req *pb.GetOwnConfirmationsRequest) (*pb.GetConfirmationsResponse, error) {
+	if req.InputIndex > 255 {
+		return nil, node.NewError(node.ReasonNotFound, "input not found")
+	}
+	sigs, err := r.confirmer.GetOwnConfirmations(req.Sig, req.ExpiresAt, req.BlockNumber, req.TransactionIndex, uint8(req.InputIndex))
+	if err != nil {
+		return nil, err
+	}
+	return confirmationsResponse(sigs), nil
+}
+
+func confirmationsResponse(sigs []chain.Signature) *pb.GetConfirmationsResponse {
+	res := &pb.GetConfirmationsResponse{}
+	for i := range sigs {
+		if i == 0 {
+			res.AuthSig0 = sigs[i][:]
+		} else if i == 1 {
+			res.AuthSig1 = sigs[i][:]
+		}
+		res.AuthSigs = append(res.AuthSigs, sigs[i][:])
+	}
+	return res
 }
 
 func (r *Server) BlockHeight(context.Context, *pb.EmptyRequest) (*pb.BlockHeightResponse, error) {
@@ -180,3 +280,105 @@ func (r *Server) BlockHeight(context.Context, *pb.EmptyRequest) (*pb.BlockHeight
 		Height: latest.Header.Number,
 	}, nil
 }
+
+func (r *Server) GetNodeInfo(context.Context, *pb.EmptyRequest) (*pb.GetNodeInfoResponse, error) {
+	status, err := r.health.SyncStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	contractAddr := r.client.ContractAddress()
+	operatorAddr := r.client.UserAddress()
+	return &pb.GetNodeInfoResponse{
+		ContractAddress: contractAddr.Bytes(),
+		OperatorAddress: operatorAddr.Bytes(),
+		Version:         config.Version,
+		ChainId:         rpc.SerializeBig(r.confirmer.Domain().ChainID),
+		SyncStatus: &pb.SyncStatus{
+			EthereumHeight:     status.EthereumHeight,
+			LastDepositPoll:    status.LastDepositPoll,
+			LastTxExitPoll:     status.LastTxExitPoll,
+			LatestBlock:        status.LatestBlock,
+			LastSubmittedBlock: status.LastSubmittedBlock,
+			Ready:              status.Ready,
+			Reason:             status.Reason,
+		},
+	}, nil
+}
+
+func (r *Server) GetInclusionProof(ctx context.Context, req *pb.GetInclusionProofRequest) (*pb.GetInclusionProofResponse, error) {
+	txs, err := r.storage.FindTransactionsByBlockNum(req.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if int(req.TransactionIndex) >= len(txs) {
+		return nil, node.NewError(node.ReasonNotFound, "transaction not found")
+	}
+
+	hashes := make([]util.Hash, len(txs))
+	for i := range txs {
+		hashes[i] = txs[i].RLPHash(util.Sha256)
+	}
+	root, proof, err := merkle.RootAndProof(hashes, int(req.TransactionIndex))
+	if err != nil {
+		return nil, err
+	}
+	block, err := r.storage.BlockAtHeight(req.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(root, block.Header.MerkleRoot) {
+		return nil, errors.New(fmt.Sprintf("transactions of block %d do not match its merkle root", req.BlockNumber))
+	}
+
+	return &pb.GetInclusionProofResponse{
+		Transaction: txs[req.TransactionIndex].RLP(),
+		Proof:       proof,
+		Root:        root,
+	}, nil
+}
+
+func (r *Server) EstimateFee(ctx context.Context, req *pb.EmptyRequest) (*pb.EstimateFeeResponse, error) {
+	estimate, err := node.EstimateFee(r.storage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.EstimateFeeResponse{
+		Fee:                 rpc.SerializeBig(estimate.Fee),
+		SampledBlocks:       estimate.SampledBlocks,
+		SampledTransactions: estimate.SampledTransactions,
+	}, nil
+}
+
+// snapshotChunkSize is how much of a snapshot each ExportSnapshot message
+// carries.
+const snapshotChunkSize = 64 * 1024
+
+// ExportSnapshot streams a snapshot of the database, so that a replica can be
+// bootstrapped while the node keeps running. It is an operator method.
+func (r *Server) ExportSnapshot(req *pb.EmptyRequest, stream pb.Root_ExportSnapshotServer) error {
+	snapshots, ok := r.storage.(db.SnapshotStorage)
+	if !ok {
+		return status.Error(codes.Unimplemented, "the database backend does not support snapshots")
+	}
+
+	w := bufio.NewWriterSize(&snapshotStreamWriter{stream: stream}, snapshotChunkSize)
+	if err := snapshots.ExportSnapshot(w, nil); err != nil {
+		log.Println("failed to export snapshot", err)
+		return status.Error(codes.Internal, "failed to export snapshot")
+	}
+	return w.Flush()
+}
+
+// snapshotStreamWriter sends everything written to it as SnapshotChunks.
+type snapshotStreamWriter struct {
+	stream pb.Root_ExportSnapshotServer
+}
+
+func (w *snapshotStreamWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&pb.SnapshotChunk{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
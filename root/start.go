@@ -4,30 +4,53 @@ import (
 	"github.com/kyokan/plasma/eth"
 	"github.com/kyokan/plasma/db"
 	"github.com/kyokan/plasma/config"
-	"crypto/ecdsa"
 	"github.com/kyokan/plasma/node"
 	"context"
 	"os"
 	"os/signal"
 	"path"
+	"log"
 )
 
-func Start(config *config.GlobalConfig, privateKey *ecdsa.PrivateKey) error {
+func Start(config *config.GlobalConfig, signer eth.Signer) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	plasma, err := eth.NewClient(config.NodeURL, config.ContractAddr, privateKey)
+	policy := node.BlockPolicy{
+		MinBlockInterval:   config.MinBlockInterval,
+		MaxBlockInterval:   config.MaxBlockInterval,
+		MinTxCount:         config.MinBlockTxs,
+		MaxTxCount:         config.MaxBlockTxs,
+		EmptyBlockInterval: config.EmptyBlockInterval,
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	plasma, err := eth.NewClient(config.NodeURL, config.ContractAddr, signer)
 	if err != nil {
 		return err
 	}
 
-	ldb, storage, err := db.CreateStorage(path.Join(config.DBPath, "root"))
+	closer, storage, err := db.CreateStorageWithBackend(config.DBBackend, path.Join(config.DBPath, "root"))
 	if err != nil {
 		return err
 	}
-	defer ldb.Close()
+	defer closer.Close()
+
+	chainID, err := plasma.ChainID()
+	if err != nil {
+		return err
+	}
+	verifier := eth.NewSignatureVerifier(eth.NewPlasmaDomain(chainID, plasma.ContractAddress()), config.LegacySignatures)
+	if config.LegacySignatures {
+		log.Println("Accepting legacy signatures as well as typed data signatures.")
+	}
 
-	mpool := node.NewMempool(storage)
+	if config.MultiTransactions {
+		log.Println("Accepting multi-input transactions. Exits spending their outputs can only be challenged by a contract that decodes them.")
+	}
+	mpool := node.NewMempool(storage, verifier, config.MultiTransactions)
 	err = mpool.Start()
 	if err != nil {
 		return err
@@ -38,17 +61,52 @@ func Start(config *config.GlobalConfig, privateKey *ecdsa.PrivateKey) error {
 	    return err
 	}
 
-	confirmer := node.NewTransactionConfirmer(storage)
+	confirmer := node.NewTransactionConfirmer(storage, verifier)
 	submitter := node.NewBlockSubmitter(plasma, storage)
 	if err := submitter.Start(); err != nil {
 	    return err
 	}
 
-	p := node.NewPlasmaNode(storage, mpool, plasma, submitter)
+	prunable, ok := storage.(db.PrunableStorage)
+	if config.Prune && !ok {
+		log.Println("The database backend does not support pruning, keeping all transactions.")
+	}
+	if ok {
+		archive, err := db.OpenStorageArchive(prunable, path.Join(config.DBPath, "root", db.ArchiveName), config.Prune)
+		if err != nil {
+			return err
+		}
+		if archive != nil {
+			defer archive.Close()
+		}
+	}
+	if config.Prune && ok {
+		pruner, err := node.NewPruner(storage, prunable, config.PruneRetention)
+		if err != nil {
+			return err
+		}
+		if err := pruner.Start(); err != nil {
+			return err
+		}
+	}
+
+	p := node.NewPlasmaNode(storage, mpool, plasma, submitter, policy)
 	go p.Start()
 
-	server := NewServer(ctx, storage, mpool, confirmer)
-	go server.Start(config.RPCPort)
+	health := node.NewHealthChecker(plasma, storage, config.MaxChainsawLag, config.MaxSubmissionDelay)
+	limiter := NewRPCLimiter(config.RPCLimits)
+	healthServer := NewHealthServer(ctx, health, limiter)
+	go healthServer.Start(config.RESTPort)
+
+	server := NewServer(ctx, storage, mpool, confirmer, plasma, health, limiter)
+	tlsConfig := &TLSConfig{
+		CertFile:     config.TLSCert,
+		KeyFile:      config.TLSKey,
+		ClientCAFile: config.TLSClientCA,
+	}
+	if err := server.Start(config.RPCPort, tlsConfig); err != nil {
+		return err
+	}
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
@@ -0,0 +1,191 @@
+package root
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kyokan/plasma/rpc/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// testCert is a certificate and its key, signed by a CA or self-signed.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, name string, ca *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	parent, parentKey := template, key
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		parent, parentKey = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// write saves the certificate and key as PEM files in dir.
+func (c *testCert) write(t *testing.T, dir string, name string) (string, string) {
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func (c *testCert) tlsCert() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// operatorServer answers GetBalance, BlockHeight and ExportSnapshot.
+type operatorServer struct {
+	pb.RootServer
+}
+
+func (s *operatorServer) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+	return &pb.GetBalanceResponse{}, nil
+}
+
+func (s *operatorServer) BlockHeight(ctx context.Context, req *pb.EmptyRequest) (*pb.BlockHeightResponse, error) {
+	return &pb.BlockHeightResponse{Height: 7}, nil
+}
+
+func (s *operatorServer) ExportSnapshot(req *pb.EmptyRequest, stream pb.Root_ExportSnapshotServer) error {
+	return stream.Send(&pb.SnapshotChunk{Data: []byte("snapshot")})
+}
+
+func TestOperatorMethodsRequireClientCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plasma-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	serverCA := newTestCert(t, "server ca", nil)
+	clientCA := newTestCert(t, "client ca", nil)
+	otherCA := newTestCert(t, "other ca", nil)
+	certFile, keyFile := newTestCert(t, "plasmad", serverCA).write(t, dir, "server")
+	clientCAFile, _ := clientCA.write(t, dir, "client-ca")
+	operator := newTestCert(t, "operator", clientCA)
+	stranger := newTestCert(t, "stranger", otherCA)
+
+	creds, err := serverCredentials(&TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: clientCAFile})
+	require.NoError(t, err)
+	_, err = serverCredentials(&TLSConfig{CertFile: certFile})
+	require.Error(t, err)
+
+	const operatorMethod = "/pb.Root/GetBalance"
+	operatorMethods[operatorMethod] = true
+	defer delete(operatorMethods, operatorMethod)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer(grpc.Creds(creds), grpc.UnaryInterceptor(authenticateOperators), grpc.StreamInterceptor(authenticateOperatorStreams))
+	pb.RegisterRootServer(s, &operatorServer{})
+	go s.Serve(lis)
+	defer s.Stop()
+
+	serverRoots := x509.NewCertPool()
+	serverRoots.AddCert(serverCA.cert)
+	dial := func(client *testCert) *grpc.ClientConn {
+		cfg := &tls.Config{RootCAs: serverRoots}
+		if client != nil {
+			// always present the certificate, even if the server does not
+			// list its CA as acceptable
+			cert := client.tlsCert()
+			cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return &cert, nil
+			}
+		}
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+		require.NoError(t, err)
+		return conn
+	}
+
+	tests := []struct {
+		name     string
+		client   *testCert
+		operator codes.Code
+		public   codes.Code
+	}{
+		{"no certificate", nil, codes.Unauthenticated, codes.OK},
+		{"operator certificate", operator, codes.OK, codes.OK},
+		// the handshake fails, so not even public methods are served
+		{"certificate from another CA", stranger, codes.Unavailable, codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := dial(tt.client)
+			defer conn.Close()
+			client := pb.NewRootClient(conn)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err := client.GetBalance(ctx, &pb.GetBalanceRequest{})
+			require.Equal(t, tt.operator, status.Code(err), "%v", err)
+			_, err = client.BlockHeight(ctx, &pb.EmptyRequest{})
+			require.Equal(t, tt.public, status.Code(err), "%v", err)
+
+			// snapshots are always operator only
+			stream, err := client.ExportSnapshot(ctx, &pb.EmptyRequest{})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			require.Equal(t, tt.operator, status.Code(err), "%v", err)
+		})
+	}
+}
+
+func TestIsAuthenticatedClient(t *testing.T) {
+	require.False(t, isAuthenticatedClient(context.Background()))
+
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 6545}
+	plaintext := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	require.False(t, isAuthenticatedClient(plaintext))
+
+	unverified := peer.NewContext(context.Background(), &peer.Peer{Addr: addr, AuthInfo: credentials.TLSInfo{}})
+	require.False(t, isAuthenticatedClient(unverified))
+
+	var state tls.ConnectionState
+	state.VerifiedChains = [][]*x509.Certificate{{newTestCert(t, "operator", nil).cert}}
+	verified := peer.NewContext(context.Background(), &peer.Peer{Addr: addr, AuthInfo: credentials.TLSInfo{State: state}})
+	require.True(t, isAuthenticatedClient(verified))
+}
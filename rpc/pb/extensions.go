@@ -60,16 +60,20 @@ func (m BlockHeader) MarshalJSON() ([]byte, error) {
 
 // TODO: Please use the chain.Transaction as it already has the JSON annotations
 type rawTransaction struct {
-	Input0   *Input  `json:"input0"`
-	Sig0     *string `json:"sig0"`
-	Input1   *Input  `json:"input1"`
-	Sig1     *string `json:"sig1"`
-	Output0  *Output `json:"output0"`
-	Output1  *Output `json:"output1"`
-	Fee      *BigInt `json:"fee"`
-	BlockNum uint64  `json:"blockNum"`
-	TxIdx    uint32  `json:"txIdx"`
-	RootSig  string  `json:"rootSig"`
+	Input0   *Input    `json:"input0"`
+	Sig0     *string   `json:"sig0"`
+	Input1   *Input    `json:"input1"`
+	Sig1     *string   `json:"sig1"`
+	Output0  *Output   `json:"output0"`
+	Output1  *Output   `json:"output1"`
+	Fee      *BigInt   `json:"fee"`
+	BlockNum uint64    `json:"blockNum"`
+	TxIdx    uint32    `json:"txIdx"`
+	RootSig  string    `json:"rootSig"`
+	Version  uint32    `json:"version,omitempty"`
+	Inputs   []*Input  `json:"inputs,omitempty"`
+	Sigs     []string  `json:"sigs,omitempty"`
+	Outputs  []*Output `json:"outputs,omitempty"`
 }
 
 func (m Transaction) MarshalJSON() ([]byte, error) {
@@ -83,6 +87,12 @@ func (m Transaction) MarshalJSON() ([]byte, error) {
 		Fee:      m.Fee,
 		BlockNum: m.BlockNum,
 		TxIdx:    m.TxIdx,
+		Version:  m.Version,
+		Inputs:   m.Inputs,
+		Outputs:  m.Outputs,
+	}
+	for _, sig := range m.Sigs {
+		raw.Sigs = append(raw.Sigs, hexutil.Encode(sig))
 	}
 	return json.Marshal(raw)
 }
@@ -33,7 +33,7 @@ func (m *EmptyRequest) Reset()         { *m = EmptyRequest{} }
 func (m *EmptyRequest) String() string { return proto.CompactTextString(m) }
 func (*EmptyRequest) ProtoMessage()    {}
 func (*EmptyRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{0}
+	return fileDescriptor_root_f007dfff6d39e493, []int{0}
 }
 func (m *EmptyRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_EmptyRequest.Unmarshal(m, b)
@@ -64,7 +64,7 @@ func (m *BigInt) Reset()         { *m = BigInt{} }
 func (m *BigInt) String() string { return proto.CompactTextString(m) }
 func (*BigInt) ProtoMessage()    {}
 func (*BigInt) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{1}
+	return fileDescriptor_root_f007dfff6d39e493, []int{1}
 }
 func (m *BigInt) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BigInt.Unmarshal(m, b)
@@ -106,7 +106,7 @@ func (m *Input) Reset()         { *m = Input{} }
 func (m *Input) String() string { return proto.CompactTextString(m) }
 func (*Input) ProtoMessage()    {}
 func (*Input) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{2}
+	return fileDescriptor_root_f007dfff6d39e493, []int{2}
 }
 func (m *Input) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Input.Unmarshal(m, b)
@@ -174,7 +174,7 @@ func (m *Output) Reset()         { *m = Output{} }
 func (m *Output) String() string { return proto.CompactTextString(m) }
 func (*Output) ProtoMessage()    {}
 func (*Output) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{3}
+	return fileDescriptor_root_f007dfff6d39e493, []int{3}
 }
 func (m *Output) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Output.Unmarshal(m, b)
@@ -229,7 +229,7 @@ func (m *BlockHeader) Reset()         { *m = BlockHeader{} }
 func (m *BlockHeader) String() string { return proto.CompactTextString(m) }
 func (*BlockHeader) ProtoMessage()    {}
 func (*BlockHeader) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{4}
+	return fileDescriptor_root_f007dfff6d39e493, []int{4}
 }
 func (m *BlockHeader) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BlockHeader.Unmarshal(m, b)
@@ -289,7 +289,7 @@ func (m *Block) Reset()         { *m = Block{} }
 func (m *Block) String() string { return proto.CompactTextString(m) }
 func (*Block) ProtoMessage()    {}
 func (*Block) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{5}
+	return fileDescriptor_root_f007dfff6d39e493, []int{5}
 }
 func (m *Block) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Block.Unmarshal(m, b)
@@ -324,25 +324,29 @@ func (m *Block) GetHash() []byte {
 }
 
 type Transaction struct {
-	Input0               *Input   `protobuf:"bytes,1,opt,name=input0,proto3" json:"input0,omitempty"`
-	Sig0                 []byte   `protobuf:"bytes,2,opt,name=sig0,proto3" json:"sig0,omitempty"`
-	Input1               *Input   `protobuf:"bytes,3,opt,name=input1,proto3" json:"input1,omitempty"`
-	Sig1                 []byte   `protobuf:"bytes,4,opt,name=sig1,proto3" json:"sig1,omitempty"`
-	Output0              *Output  `protobuf:"bytes,5,opt,name=output0,proto3" json:"output0,omitempty"`
-	Output1              *Output  `protobuf:"bytes,6,opt,name=output1,proto3" json:"output1,omitempty"`
-	Fee                  *BigInt  `protobuf:"bytes,7,opt,name=fee,proto3" json:"fee,omitempty"`
-	BlockNum             uint64   `protobuf:"varint,8,opt,name=blockNum,proto3" json:"blockNum,omitempty"`
-	TxIdx                uint32   `protobuf:"varint,9,opt,name=txIdx,proto3" json:"txIdx,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Input0               *Input    `protobuf:"bytes,1,opt,name=input0,proto3" json:"input0,omitempty"`
+	Sig0                 []byte    `protobuf:"bytes,2,opt,name=sig0,proto3" json:"sig0,omitempty"`
+	Input1               *Input    `protobuf:"bytes,3,opt,name=input1,proto3" json:"input1,omitempty"`
+	Sig1                 []byte    `protobuf:"bytes,4,opt,name=sig1,proto3" json:"sig1,omitempty"`
+	Output0              *Output   `protobuf:"bytes,5,opt,name=output0,proto3" json:"output0,omitempty"`
+	Output1              *Output   `protobuf:"bytes,6,opt,name=output1,proto3" json:"output1,omitempty"`
+	Fee                  *BigInt   `protobuf:"bytes,7,opt,name=fee,proto3" json:"fee,omitempty"`
+	BlockNum             uint64    `protobuf:"varint,8,opt,name=blockNum,proto3" json:"blockNum,omitempty"`
+	TxIdx                uint32    `protobuf:"varint,9,opt,name=txIdx,proto3" json:"txIdx,omitempty"`
+	Version              uint32    `protobuf:"varint,10,opt,name=version,proto3" json:"version,omitempty"`
+	Inputs               []*Input  `protobuf:"bytes,11,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Sigs                 [][]byte  `protobuf:"bytes,12,rep,name=sigs,proto3" json:"sigs,omitempty"`
+	Outputs              []*Output `protobuf:"bytes,13,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
 }
 
 func (m *Transaction) Reset()         { *m = Transaction{} }
 func (m *Transaction) String() string { return proto.CompactTextString(m) }
 func (*Transaction) ProtoMessage()    {}
 func (*Transaction) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{6}
+	return fileDescriptor_root_f007dfff6d39e493, []int{6}
 }
 func (m *Transaction) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Transaction.Unmarshal(m, b)
@@ -425,6 +429,34 @@ func (m *Transaction) GetTxIdx() uint32 {
 	return 0
 }
 
+func (m *Transaction) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Transaction) GetInputs() []*Input {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (m *Transaction) GetSigs() [][]byte {
+	if m != nil {
+		return m.Sigs
+	}
+	return nil
+}
+
+func (m *Transaction) GetOutputs() []*Output {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
 type ConfirmedTransaction struct {
 	Transaction          *Transaction `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
 	Signatures           [][]byte     `protobuf:"bytes,2,rep,name=signatures,proto3" json:"signatures,omitempty"`
@@ -437,7 +469,7 @@ func (m *ConfirmedTransaction) Reset()         { *m = ConfirmedTransaction{} }
 func (m *ConfirmedTransaction) String() string { return proto.CompactTextString(m) }
 func (*ConfirmedTransaction) ProtoMessage()    {}
 func (*ConfirmedTransaction) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{7}
+	return fileDescriptor_root_f007dfff6d39e493, []int{7}
 }
 func (m *ConfirmedTransaction) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfirmedTransaction.Unmarshal(m, b)
@@ -482,7 +514,7 @@ func (m *GetBalanceRequest) Reset()         { *m = GetBalanceRequest{} }
 func (m *GetBalanceRequest) String() string { return proto.CompactTextString(m) }
 func (*GetBalanceRequest) ProtoMessage()    {}
 func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{8}
+	return fileDescriptor_root_f007dfff6d39e493, []int{8}
 }
 func (m *GetBalanceRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetBalanceRequest.Unmarshal(m, b)
@@ -520,7 +552,7 @@ func (m *GetBalanceResponse) Reset()         { *m = GetBalanceResponse{} }
 func (m *GetBalanceResponse) String() string { return proto.CompactTextString(m) }
 func (*GetBalanceResponse) ProtoMessage()    {}
 func (*GetBalanceResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{9}
+	return fileDescriptor_root_f007dfff6d39e493, []int{9}
 }
 func (m *GetBalanceResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetBalanceResponse.Unmarshal(m, b)
@@ -559,7 +591,7 @@ func (m *GetOutputsRequest) Reset()         { *m = GetOutputsRequest{} }
 func (m *GetOutputsRequest) String() string { return proto.CompactTextString(m) }
 func (*GetOutputsRequest) ProtoMessage()    {}
 func (*GetOutputsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{10}
+	return fileDescriptor_root_f007dfff6d39e493, []int{10}
 }
 func (m *GetOutputsRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetOutputsRequest.Unmarshal(m, b)
@@ -595,6 +627,7 @@ func (m *GetOutputsRequest) GetSpendable() bool {
 
 type GetOutputsResponse struct {
 	ConfirmedTransactions []*ConfirmedTransaction `protobuf:"bytes,1,rep,name=confirmedTransactions,proto3" json:"confirmedTransactions,omitempty"`
+	OutputIndexes         []uint32                `protobuf:"varint,2,rep,name=outputIndexes,proto3" json:"outputIndexes,omitempty"`
 	XXX_NoUnkeyedLiteral  struct{}                `json:"-"`
 	XXX_unrecognized      []byte                  `json:"-"`
 	XXX_sizecache         int32                   `json:"-"`
@@ -604,7 +637,7 @@ func (m *GetOutputsResponse) Reset()         { *m = GetOutputsResponse{} }
 func (m *GetOutputsResponse) String() string { return proto.CompactTextString(m) }
 func (*GetOutputsResponse) ProtoMessage()    {}
 func (*GetOutputsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{11}
+	return fileDescriptor_root_f007dfff6d39e493, []int{11}
 }
 func (m *GetOutputsResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetOutputsResponse.Unmarshal(m, b)
@@ -631,6 +664,13 @@ func (m *GetOutputsResponse) GetConfirmedTransactions() []*ConfirmedTransaction
 	return nil
 }
 
+func (m *GetOutputsResponse) GetOutputIndexes() []uint32 {
+	if m != nil {
+		return m.OutputIndexes
+	}
+	return nil
+}
+
 type GetBlockRequest struct {
 	Number               uint64   `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -642,7 +682,7 @@ func (m *GetBlockRequest) Reset()         { *m = GetBlockRequest{} }
 func (m *GetBlockRequest) String() string { return proto.CompactTextString(m) }
 func (*GetBlockRequest) ProtoMessage()    {}
 func (*GetBlockRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{12}
+	return fileDescriptor_root_f007dfff6d39e493, []int{12}
 }
 func (m *GetBlockRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetBlockRequest.Unmarshal(m, b)
@@ -682,7 +722,7 @@ func (m *GetBlockResponse) Reset()         { *m = GetBlockResponse{} }
 func (m *GetBlockResponse) String() string { return proto.CompactTextString(m) }
 func (*GetBlockResponse) ProtoMessage()    {}
 func (*GetBlockResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{13}
+	return fileDescriptor_root_f007dfff6d39e493, []int{13}
 }
 func (m *GetBlockResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetBlockResponse.Unmarshal(m, b)
@@ -734,7 +774,7 @@ func (m *GetBlockResponse_BlockMeta) Reset()         { *m = GetBlockResponse_Blo
 func (m *GetBlockResponse_BlockMeta) String() string { return proto.CompactTextString(m) }
 func (*GetBlockResponse_BlockMeta) ProtoMessage()    {}
 func (*GetBlockResponse_BlockMeta) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{13, 0}
+	return fileDescriptor_root_f007dfff6d39e493, []int{13, 0}
 }
 func (m *GetBlockResponse_BlockMeta) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetBlockResponse_BlockMeta.Unmarshal(m, b)
@@ -772,7 +812,7 @@ func (m *SendRequest) Reset()         { *m = SendRequest{} }
 func (m *SendRequest) String() string { return proto.CompactTextString(m) }
 func (*SendRequest) ProtoMessage()    {}
 func (*SendRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{14}
+	return fileDescriptor_root_f007dfff6d39e493, []int{14}
 }
 func (m *SendRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendRequest.Unmarshal(m, b)
@@ -811,7 +851,7 @@ func (m *SendResponse) Reset()         { *m = SendResponse{} }
 func (m *SendResponse) String() string { return proto.CompactTextString(m) }
 func (*SendResponse) ProtoMessage()    {}
 func (*SendResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{15}
+	return fileDescriptor_root_f007dfff6d39e493, []int{15}
 }
 func (m *SendResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendResponse.Unmarshal(m, b)
@@ -858,7 +898,7 @@ func (m *TransactionInclusion) Reset()         { *m = TransactionInclusion{} }
 func (m *TransactionInclusion) String() string { return proto.CompactTextString(m) }
 func (*TransactionInclusion) ProtoMessage()    {}
 func (*TransactionInclusion) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{16}
+	return fileDescriptor_root_f007dfff6d39e493, []int{16}
 }
 func (m *TransactionInclusion) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TransactionInclusion.Unmarshal(m, b)
@@ -904,6 +944,7 @@ type ConfirmRequest struct {
 	TransactionIndex     uint32   `protobuf:"varint,2,opt,name=transactionIndex,proto3" json:"transactionIndex,omitempty"`
 	AuthSig0             []byte   `protobuf:"bytes,3,opt,name=authSig0,proto3" json:"authSig0,omitempty"`
 	AuthSig1             []byte   `protobuf:"bytes,4,opt,name=authSig1,proto3" json:"authSig1,omitempty"`
+	AuthSigs             [][]byte `protobuf:"bytes,5,rep,name=authSigs,proto3" json:"authSigs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -913,7 +954,7 @@ func (m *ConfirmRequest) Reset()         { *m = ConfirmRequest{} }
 func (m *ConfirmRequest) String() string { return proto.CompactTextString(m) }
 func (*ConfirmRequest) ProtoMessage()    {}
 func (*ConfirmRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{17}
+	return fileDescriptor_root_f007dfff6d39e493, []int{17}
 }
 func (m *ConfirmRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfirmRequest.Unmarshal(m, b)
@@ -961,12 +1002,19 @@ func (m *ConfirmRequest) GetAuthSig1() []byte {
 	return nil
 }
 
+func (m *ConfirmRequest) GetAuthSigs() [][]byte {
+	if m != nil {
+		return m.AuthSigs
+	}
+	return nil
+}
+
 type GetConfirmationsRequest struct {
 	Sig                  []byte   `protobuf:"bytes,1,opt,name=sig,proto3" json:"sig,omitempty"`
-	Nonce                uint64   `protobuf:"varint,2,opt,name=nonce,proto3" json:"nonce,omitempty"`
 	BlockNumber          uint64   `protobuf:"varint,3,opt,name=blockNumber,proto3" json:"blockNumber,omitempty"`
 	TransactionIndex     uint32   `protobuf:"varint,4,opt,name=transactionIndex,proto3" json:"transactionIndex,omitempty"`
 	OutputIndex          uint32   `protobuf:"varint,5,opt,name=outputIndex,proto3" json:"outputIndex,omitempty"`
+	Nonce                []byte   `protobuf:"bytes,6,opt,name=nonce,proto3" json:"nonce,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -976,7 +1024,7 @@ func (m *GetConfirmationsRequest) Reset()         { *m = GetConfirmationsRequest
 func (m *GetConfirmationsRequest) String() string { return proto.CompactTextString(m) }
 func (*GetConfirmationsRequest) ProtoMessage()    {}
 func (*GetConfirmationsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{18}
+	return fileDescriptor_root_f007dfff6d39e493, []int{18}
 }
 func (m *GetConfirmationsRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetConfirmationsRequest.Unmarshal(m, b)
@@ -1003,37 +1051,154 @@ func (m *GetConfirmationsRequest) GetSig() []byte {
 	return nil
 }
 
-func (m *GetConfirmationsRequest) GetNonce() uint64 {
+func (m *GetConfirmationsRequest) GetBlockNumber() uint64 {
 	if m != nil {
-		return m.Nonce
+		return m.BlockNumber
 	}
 	return 0
 }
 
-func (m *GetConfirmationsRequest) GetBlockNumber() uint64 {
+func (m *GetConfirmationsRequest) GetTransactionIndex() uint32 {
+	if m != nil {
+		return m.TransactionIndex
+	}
+	return 0
+}
+
+func (m *GetConfirmationsRequest) GetOutputIndex() uint32 {
+	if m != nil {
+		return m.OutputIndex
+	}
+	return 0
+}
+
+func (m *GetConfirmationsRequest) GetNonce() []byte {
+	if m != nil {
+		return m.Nonce
+	}
+	return nil
+}
+
+type GetConfirmationsChallengeRequest struct {
+	BlockNumber          uint64   `protobuf:"varint,1,opt,name=blockNumber,proto3" json:"blockNumber,omitempty"`
+	TransactionIndex     uint32   `protobuf:"varint,2,opt,name=transactionIndex,proto3" json:"transactionIndex,omitempty"`
+	OutputIndex          uint32   `protobuf:"varint,3,opt,name=outputIndex,proto3" json:"outputIndex,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetConfirmationsChallengeRequest) Reset()         { *m = GetConfirmationsChallengeRequest{} }
+func (m *GetConfirmationsChallengeRequest) String() string { return proto.CompactTextString(m) }
+func (*GetConfirmationsChallengeRequest) ProtoMessage()    {}
+func (*GetConfirmationsChallengeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_root_f007dfff6d39e493, []int{19}
+}
+func (m *GetConfirmationsChallengeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetConfirmationsChallengeRequest.Unmarshal(m, b)
+}
+func (m *GetConfirmationsChallengeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetConfirmationsChallengeRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetConfirmationsChallengeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetConfirmationsChallengeRequest.Merge(dst, src)
+}
+func (m *GetConfirmationsChallengeRequest) XXX_Size() int {
+	return xxx_messageInfo_GetConfirmationsChallengeRequest.Size(m)
+}
+func (m *GetConfirmationsChallengeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetConfirmationsChallengeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetConfirmationsChallengeRequest proto.InternalMessageInfo
+
+func (m *GetConfirmationsChallengeRequest) GetBlockNumber() uint64 {
 	if m != nil {
 		return m.BlockNumber
 	}
 	return 0
 }
 
-func (m *GetConfirmationsRequest) GetTransactionIndex() uint32 {
+func (m *GetConfirmationsChallengeRequest) GetTransactionIndex() uint32 {
 	if m != nil {
 		return m.TransactionIndex
 	}
 	return 0
 }
 
-func (m *GetConfirmationsRequest) GetOutputIndex() uint32 {
+func (m *GetConfirmationsChallengeRequest) GetOutputIndex() uint32 {
 	if m != nil {
 		return m.OutputIndex
 	}
 	return 0
 }
 
+type GetConfirmationsChallengeResponse struct {
+	Nonce                []byte   `protobuf:"bytes,1,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	ExpiresAt            uint64   `protobuf:"varint,2,opt,name=expiresAt,proto3" json:"expiresAt,omitempty"`
+	ContractAddress      []byte   `protobuf:"bytes,3,opt,name=contractAddress,proto3" json:"contractAddress,omitempty"`
+	ChainId              *BigInt  `protobuf:"bytes,4,opt,name=chainId,proto3" json:"chainId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetConfirmationsChallengeResponse) Reset()         { *m = GetConfirmationsChallengeResponse{} }
+func (m *GetConfirmationsChallengeResponse) String() string { return proto.CompactTextString(m) }
+func (*GetConfirmationsChallengeResponse) ProtoMessage()    {}
+func (*GetConfirmationsChallengeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_root_f007dfff6d39e493, []int{20}
+}
+func (m *GetConfirmationsChallengeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetConfirmationsChallengeResponse.Unmarshal(m, b)
+}
+func (m *GetConfirmationsChallengeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetConfirmationsChallengeResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetConfirmationsChallengeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetConfirmationsChallengeResponse.Merge(dst, src)
+}
+func (m *GetConfirmationsChallengeResponse) XXX_Size() int {
+	return xxx_messageInfo_GetConfirmationsChallengeResponse.Size(m)
+}
+func (m *GetConfirmationsChallengeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetConfirmationsChallengeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetConfirmationsChallengeResponse proto.InternalMessageInfo
+
+func (m *GetConfirmationsChallengeResponse) GetNonce() []byte {
+	if m != nil {
+		return m.Nonce
+	}
+	return nil
+}
+
+func (m *GetConfirmationsChallengeResponse) GetExpiresAt() uint64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *GetConfirmationsChallengeResponse) GetContractAddress() []byte {
+	if m != nil {
+		return m.ContractAddress
+	}
+	return nil
+}
+
+func (m *GetConfirmationsChallengeResponse) GetChainId() *BigInt {
+	if m != nil {
+		return m.ChainId
+	}
+	return nil
+}
+
 type GetConfirmationsResponse struct {
 	AuthSig0             []byte   `protobuf:"bytes,1,opt,name=authSig0,proto3" json:"authSig0,omitempty"`
 	AuthSig1             []byte   `protobuf:"bytes,2,opt,name=authSig1,proto3" json:"authSig1,omitempty"`
+	AuthSigs             [][]byte `protobuf:"bytes,3,rep,name=authSigs,proto3" json:"authSigs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -1043,7 +1208,7 @@ func (m *GetConfirmationsResponse) Reset()         { *m = GetConfirmationsRespon
 func (m *GetConfirmationsResponse) String() string { return proto.CompactTextString(m) }
 func (*GetConfirmationsResponse) ProtoMessage()    {}
 func (*GetConfirmationsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{19}
+	return fileDescriptor_root_f007dfff6d39e493, []int{21}
 }
 func (m *GetConfirmationsResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetConfirmationsResponse.Unmarshal(m, b)
@@ -1077,6 +1242,83 @@ func (m *GetConfirmationsResponse) GetAuthSig1() []byte {
 	return nil
 }
 
+func (m *GetConfirmationsResponse) GetAuthSigs() [][]byte {
+	if m != nil {
+		return m.AuthSigs
+	}
+	return nil
+}
+
+type GetOwnConfirmationsRequest struct {
+	Sig                  []byte   `protobuf:"bytes,1,opt,name=sig,proto3" json:"sig,omitempty"`
+	BlockNumber          uint64   `protobuf:"varint,2,opt,name=blockNumber,proto3" json:"blockNumber,omitempty"`
+	TransactionIndex     uint32   `protobuf:"varint,3,opt,name=transactionIndex,proto3" json:"transactionIndex,omitempty"`
+	ExpiresAt            uint64   `protobuf:"varint,4,opt,name=expiresAt,proto3" json:"expiresAt,omitempty"`
+	InputIndex           uint32   `protobuf:"varint,5,opt,name=inputIndex,proto3" json:"inputIndex,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetOwnConfirmationsRequest) Reset()         { *m = GetOwnConfirmationsRequest{} }
+func (m *GetOwnConfirmationsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetOwnConfirmationsRequest) ProtoMessage()    {}
+func (*GetOwnConfirmationsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_root_f007dfff6d39e493, []int{22}
+}
+func (m *GetOwnConfirmationsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetOwnConfirmationsRequest.Unmarshal(m, b)
+}
+func (m *GetOwnConfirmationsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetOwnConfirmationsRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetOwnConfirmationsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetOwnConfirmationsRequest.Merge(dst, src)
+}
+func (m *GetOwnConfirmationsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetOwnConfirmationsRequest.Size(m)
+}
+func (m *GetOwnConfirmationsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetOwnConfirmationsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetOwnConfirmationsRequest proto.InternalMessageInfo
+
+func (m *GetOwnConfirmationsRequest) GetSig() []byte {
+	if m != nil {
+		return m.Sig
+	}
+	return nil
+}
+
+func (m *GetOwnConfirmationsRequest) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *GetOwnConfirmationsRequest) GetTransactionIndex() uint32 {
+	if m != nil {
+		return m.TransactionIndex
+	}
+	return 0
+}
+
+func (m *GetOwnConfirmationsRequest) GetExpiresAt() uint64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *GetOwnConfirmationsRequest) GetInputIndex() uint32 {
+	if m != nil {
+		return m.InputIndex
+	}
+	return 0
+}
+
 type BlockHeightResponse struct {
 	Height               uint64   `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -1088,7 +1330,7 @@ func (m *BlockHeightResponse) Reset()         { *m = BlockHeightResponse{} }
 func (m *BlockHeightResponse) String() string { return proto.CompactTextString(m) }
 func (*BlockHeightResponse) ProtoMessage()    {}
 func (*BlockHeightResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_root_aef425b774122527, []int{20}
+	return fileDescriptor_root_f007dfff6d39e493, []int{23}
 }
 func (m *BlockHeightResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BlockHeightResponse.Unmarshal(m, b)
@@ -1115,6 +1357,408 @@ func (m *BlockHeightResponse) GetHeight() uint64 {
 	return 0
 }
 
+type SyncStatus struct {
+	EthereumHeight       uint64   `protobuf:"varint,1,opt,name=ethereumHeight,proto3" json:"ethereumHeight,omitempty"`
+	LastDepositPoll      uint64   `protobuf:"varint,2,opt,name=lastDepositPoll,proto3" json:"lastDepositPoll,omitempty"`
+	LastTxExitPoll       uint64   `protobuf:"varint,3,opt,name=lastTxExitPoll,proto3" json:"lastTxExitPoll,omitempty"`
+	LatestBlock          uint64   `protobuf:"varint,4,opt,name=latestBlock,proto3" json:"latestBlock,omitempty"`
+	LastSubmittedBlock   uint64   `protobuf:"varint,5,opt,name=lastSubmittedBlock,proto3" json:"lastSubmittedBlock,omitempty"`
+	Ready                bool     `protobuf:"varint,6,opt,name=ready,proto3" json:"ready,omitempty"`
+	Reason               string   `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SyncStatus) Reset()         { *m = SyncStatus{} }
+func (m *SyncStatus) String() string { return proto.CompactTextString(m) }
+func (*SyncStatus) ProtoMessage()    {}
+func (*SyncStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_root_f007dfff6d39e493, []int{24}
+}
+func (m *SyncStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SyncStatus.Unmarshal(m, b)
+}
+func (m *SyncStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SyncStatus.Marshal(b, m, deterministic)
+}
+func (dst *SyncStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SyncStatus.Merge(dst, src)
+}
+func (m *SyncStatus) XXX_Size() int {
+	return xxx_messageInfo_SyncStatus.Size(m)
+}
+func (m *SyncStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_SyncStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SyncStatus proto.InternalMessageInfo
+
+func (m *SyncStatus) GetEthereumHeight() uint64 {
+	if m != nil {
+		return m.EthereumHeight
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetLastDepositPoll() uint64 {
+	if m != nil {
+		return m.LastDepositPoll
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetLastTxExitPoll() uint64 {
+	if m != nil {
+		return m.LastTxExitPoll
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetLatestBlock() uint64 {
+	if m != nil {
+		return m.LatestBlock
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetLastSubmittedBlock() uint64 {
+	if m != nil {
+		return m.LastSubmittedBlock
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetReady() bool {
+	if m != nil {
+		return m.Ready
+	}
+	return false
+}
+
+func (m *SyncStatus) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type GetNodeInfoResponse struct {
+	ContractAddress      []byte      `protobuf:"bytes,1,opt,name=contractAddress,proto3" json:"contractAddress,omitempty"`
+	OperatorAddress      []byte      `protobuf:"bytes,2,opt,name=operatorAddress,proto3" json:"operatorAddress,omitempty"`
+	Version              string      `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	SyncStatus           *SyncStatus `protobuf:"bytes,4,opt,name=syncStatus,proto3" json:"syncStatus,omitempty"`
+	ChainId              *BigInt     `protobuf:"bytes,5,opt,name=chainId,proto3" json:"chainId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GetNodeInfoResponse) Reset()         { *m = GetNodeInfoResponse{} }
+func (m *GetNodeInfoResponse) String() string { return proto.CompactTextString(m) }
+func (*GetNodeInfoResponse) ProtoMessage()    {}
+func (*GetNodeInfoResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_root_f007dfff6d39e493, []int{25}
+}
+func (m *GetNodeInfoResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetNodeInfoResponse.Unmarshal(m, b)
+}
+func (m *GetNodeInfoResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetNodeInfoResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetNodeInfoResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetNodeInfoResponse.Merge(dst, src)
+}
+func (m *GetNodeInfoResponse) XXX_Size() int {
+	return xxx_messageInfo_GetNodeInfoResponse.Size(m)
+}
+func (m *GetNodeInfoResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetNodeInfoResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetNodeInfoResponse proto.InternalMessageInfo
+
+func (m *GetNodeInfoResponse) GetContractAddress() []byte {
+	if m != nil {
+		return m.ContractAddress
+	}
+	return nil
+}
+
+func (m *GetNodeInfoResponse) GetOperatorAddress() []byte {
+	if m != nil {
+		return m.OperatorAddress
+	}
+	return nil
+}
+
+func (m *GetNodeInfoResponse) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *GetNodeInfoResponse) GetSyncStatus() *SyncStatus {
+	if m != nil {
+		return m.SyncStatus
+	}
+	return nil
+}
+
+func (m *GetNodeInfoResponse) GetChainId() *BigInt {
+	if m != nil {
+		return m.ChainId
+	}
+	return nil
+}
+
+type GetInclusionProofRequest struct {
+	BlockNumber          uint64   `protobuf:"varint,1,opt,name=blockNumber,proto3" json:"blockNumber,omitempty"`
+	TransactionIndex     uint32   `protobuf:"varint,2,opt,name=transactionIndex,proto3" json:"transactionIndex,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetInclusionProofRequest) Reset()         { *m = GetInclusionProofRequest{} }
+func (m *GetInclusionProofRequest) String() string { return proto.CompactTextString(m) }
+func (*GetInclusionProofRequest) ProtoMessage()    {}
+func (*GetInclusionProofRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_root_f007dfff6d39e493, []int{26}
+}
+func (m *GetInclusionProofRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetInclusionProofRequest.Unmarshal(m, b)
+}
+func (m *GetInclusionProofRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetInclusionProofRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetInclusionProofRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetInclusionProofRequest.Merge(dst, src)
+}
+func (m *GetInclusionProofRequest) XXX_Size() int {
+	return xxx_messageInfo_GetInclusionProofRequest.Size(m)
+}
+func (m *GetInclusionProofRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetInclusionProofRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetInclusionProofRequest proto.InternalMessageInfo
+
+func (m *GetInclusionProofRequest) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *GetInclusionProofRequest) GetTransactionIndex() uint32 {
+	if m != nil {
+		return m.TransactionIndex
+	}
+	return 0
+}
+
+type GetInclusionProofResponse struct {
+	Transaction          []byte   `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Proof                []byte   `protobuf:"bytes,2,opt,name=proof,proto3" json:"proof,omitempty"`
+	Root                 []byte   `protobuf:"bytes,3,opt,name=root,proto3" json:"root,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetInclusionProofResponse) Reset()         { *m = GetInclusionProofResponse{} }
+func (m *GetInclusionProofResponse) String() string { return proto.CompactTextString(m) }
+func (*GetInclusionProofResponse) ProtoMessage()    {}
+func (*GetInclusionProofResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_root_f007dfff6d39e493, []int{27}
+}
+func (m *GetInclusionProofResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetInclusionProofResponse.Unmarshal(m, b)
+}
+func (m *GetInclusionProofResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetInclusionProofResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetInclusionProofResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetInclusionProofResponse.Merge(dst, src)
+}
+func (m *GetInclusionProofResponse) XXX_Size() int {
+	return xxx_messageInfo_GetInclusionProofResponse.Size(m)
+}
+func (m *GetInclusionProofResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetInclusionProofResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetInclusionProofResponse proto.InternalMessageInfo
+
+func (m *GetInclusionProofResponse) GetTransaction() []byte {
+	if m != nil {
+		return m.Transaction
+	}
+	return nil
+}
+
+func (m *GetInclusionProofResponse) GetProof() []byte {
+	if m != nil {
+		return m.Proof
+	}
+	return nil
+}
+
+func (m *GetInclusionProofResponse) GetRoot() []byte {
+	if m != nil {
+		return m.Root
+	}
+	return nil
+}
+
+type EstimateFeeResponse struct {
+	Fee                  *BigInt  `protobuf:"bytes,1,opt,name=fee,proto3" json:"fee,omitempty"`
+	SampledBlocks        uint64   `protobuf:"varint,2,opt,name=sampledBlocks,proto3" json:"sampledBlocks,omitempty"`
+	SampledTransactions  uint64   `protobuf:"varint,3,opt,name=sampledTransactions,proto3" json:"sampledTransactions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EstimateFeeResponse) Reset()         { *m = EstimateFeeResponse{} }
+func (m *EstimateFeeResponse) String() string { return proto.CompactTextString(m) }
+func (*EstimateFeeResponse) ProtoMessage()    {}
+func (*EstimateFeeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_root_f007dfff6d39e493, []int{28}
+}
+func (m *EstimateFeeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EstimateFeeResponse.Unmarshal(m, b)
+}
+func (m *EstimateFeeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EstimateFeeResponse.Marshal(b, m, deterministic)
+}
+func (dst *EstimateFeeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EstimateFeeResponse.Merge(dst, src)
+}
+func (m *EstimateFeeResponse) XXX_Size() int {
+	return xxx_messageInfo_EstimateFeeResponse.Size(m)
+}
+func (m *EstimateFeeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_EstimateFeeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EstimateFeeResponse proto.InternalMessageInfo
+
+func (m *EstimateFeeResponse) GetFee() *BigInt {
+	if m != nil {
+		return m.Fee
+	}
+	return nil
+}
+
+func (m *EstimateFeeResponse) GetSampledBlocks() uint64 {
+	if m != nil {
+		return m.SampledBlocks
+	}
+	return 0
+}
+
+func (m *EstimateFeeResponse) GetSampledTransactions() uint64 {
+	if m != nil {
+		return m.SampledTransactions
+	}
+	return 0
+}
+
+type ErrorDetail struct {
+	Reason               string   `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	HasInput             bool     `protobuf:"varint,2,opt,name=hasInput,proto3" json:"hasInput,omitempty"`
+	InputIndex           uint32   `protobuf:"varint,3,opt,name=inputIndex,proto3" json:"inputIndex,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ErrorDetail) Reset()         { *m = ErrorDetail{} }
+func (m *ErrorDetail) String() string { return proto.CompactTextString(m) }
+func (*ErrorDetail) ProtoMessage()    {}
+func (*ErrorDetail) Descriptor() ([]byte, []int) {
+	return fileDescriptor_root_f007dfff6d39e493, []int{29}
+}
+func (m *ErrorDetail) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ErrorDetail.Unmarshal(m, b)
+}
+func (m *ErrorDetail) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ErrorDetail.Marshal(b, m, deterministic)
+}
+func (dst *ErrorDetail) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ErrorDetail.Merge(dst, src)
+}
+func (m *ErrorDetail) XXX_Size() int {
+	return xxx_messageInfo_ErrorDetail.Size(m)
+}
+func (m *ErrorDetail) XXX_DiscardUnknown() {
+	xxx_messageInfo_ErrorDetail.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ErrorDetail proto.InternalMessageInfo
+
+func (m *ErrorDetail) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *ErrorDetail) GetHasInput() bool {
+	if m != nil {
+		return m.HasInput
+	}
+	return false
+}
+
+func (m *ErrorDetail) GetInputIndex() uint32 {
+	if m != nil {
+		return m.InputIndex
+	}
+	return 0
+}
+
+type SnapshotChunk struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SnapshotChunk) Reset()         { *m = SnapshotChunk{} }
+func (m *SnapshotChunk) String() string { return proto.CompactTextString(m) }
+func (*SnapshotChunk) ProtoMessage()    {}
+func (*SnapshotChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_root_f007dfff6d39e493, []int{30}
+}
+func (m *SnapshotChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SnapshotChunk.Unmarshal(m, b)
+}
+func (m *SnapshotChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SnapshotChunk.Marshal(b, m, deterministic)
+}
+func (dst *SnapshotChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotChunk.Merge(dst, src)
+}
+func (m *SnapshotChunk) XXX_Size() int {
+	return xxx_messageInfo_SnapshotChunk.Size(m)
+}
+func (m *SnapshotChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotChunk proto.InternalMessageInfo
+
+func (m *SnapshotChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*EmptyRequest)(nil), "pb.EmptyRequest")
 	proto.RegisterType((*BigInt)(nil), "pb.BigInt")
@@ -1136,8 +1780,18 @@ func init() {
 	proto.RegisterType((*TransactionInclusion)(nil), "pb.TransactionInclusion")
 	proto.RegisterType((*ConfirmRequest)(nil), "pb.ConfirmRequest")
 	proto.RegisterType((*GetConfirmationsRequest)(nil), "pb.GetConfirmationsRequest")
+	proto.RegisterType((*GetConfirmationsChallengeRequest)(nil), "pb.GetConfirmationsChallengeRequest")
+	proto.RegisterType((*GetConfirmationsChallengeResponse)(nil), "pb.GetConfirmationsChallengeResponse")
 	proto.RegisterType((*GetConfirmationsResponse)(nil), "pb.GetConfirmationsResponse")
+	proto.RegisterType((*GetOwnConfirmationsRequest)(nil), "pb.GetOwnConfirmationsRequest")
 	proto.RegisterType((*BlockHeightResponse)(nil), "pb.BlockHeightResponse")
+	proto.RegisterType((*SyncStatus)(nil), "pb.SyncStatus")
+	proto.RegisterType((*GetNodeInfoResponse)(nil), "pb.GetNodeInfoResponse")
+	proto.RegisterType((*GetInclusionProofRequest)(nil), "pb.GetInclusionProofRequest")
+	proto.RegisterType((*GetInclusionProofResponse)(nil), "pb.GetInclusionProofResponse")
+	proto.RegisterType((*EstimateFeeResponse)(nil), "pb.EstimateFeeResponse")
+	proto.RegisterType((*ErrorDetail)(nil), "pb.ErrorDetail")
+	proto.RegisterType((*SnapshotChunk)(nil), "pb.SnapshotChunk")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1157,8 +1811,14 @@ type RootClient interface {
 	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*GetBlockResponse, error)
 	Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*SendResponse, error)
 	Confirm(ctx context.Context, in *ConfirmRequest, opts ...grpc.CallOption) (*ConfirmedTransaction, error)
+	GetConfirmationsChallenge(ctx context.Context, in *GetConfirmationsChallengeRequest, opts ...grpc.CallOption) (*GetConfirmationsChallengeResponse, error)
 	GetConfirmations(ctx context.Context, in *GetConfirmationsRequest, opts ...grpc.CallOption) (*GetConfirmationsResponse, error)
 	BlockHeight(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*BlockHeightResponse, error)
+	GetNodeInfo(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*GetNodeInfoResponse, error)
+	GetInclusionProof(ctx context.Context, in *GetInclusionProofRequest, opts ...grpc.CallOption) (*GetInclusionProofResponse, error)
+	EstimateFee(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*EstimateFeeResponse, error)
+	GetOwnConfirmations(ctx context.Context, in *GetOwnConfirmationsRequest, opts ...grpc.CallOption) (*GetConfirmationsResponse, error)
+	ExportSnapshot(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (Root_ExportSnapshotClient, error)
 }
 
 type rootClient struct {
@@ -1214,6 +1874,15 @@ func (c *rootClient) Confirm(ctx context.Context, in *ConfirmRequest, opts ...gr
 	return out, nil
 }
 
+func (c *rootClient) GetConfirmationsChallenge(ctx context.Context, in *GetConfirmationsChallengeRequest, opts ...grpc.CallOption) (*GetConfirmationsChallengeResponse, error) {
+	out := new(GetConfirmationsChallengeResponse)
+	err := c.cc.Invoke(ctx, "/pb.Root/GetConfirmationsChallenge", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *rootClient) GetConfirmations(ctx context.Context, in *GetConfirmationsRequest, opts ...grpc.CallOption) (*GetConfirmationsResponse, error) {
 	out := new(GetConfirmationsResponse)
 	err := c.cc.Invoke(ctx, "/pb.Root/GetConfirmations", in, out, opts...)
@@ -1232,6 +1901,74 @@ func (c *rootClient) BlockHeight(ctx context.Context, in *EmptyRequest, opts ...
 	return out, nil
 }
 
+func (c *rootClient) GetNodeInfo(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*GetNodeInfoResponse, error) {
+	out := new(GetNodeInfoResponse)
+	err := c.cc.Invoke(ctx, "/pb.Root/GetNodeInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rootClient) GetInclusionProof(ctx context.Context, in *GetInclusionProofRequest, opts ...grpc.CallOption) (*GetInclusionProofResponse, error) {
+	out := new(GetInclusionProofResponse)
+	err := c.cc.Invoke(ctx, "/pb.Root/GetInclusionProof", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rootClient) EstimateFee(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*EstimateFeeResponse, error) {
+	out := new(EstimateFeeResponse)
+	err := c.cc.Invoke(ctx, "/pb.Root/EstimateFee", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rootClient) GetOwnConfirmations(ctx context.Context, in *GetOwnConfirmationsRequest, opts ...grpc.CallOption) (*GetConfirmationsResponse, error) {
+	out := new(GetConfirmationsResponse)
+	err := c.cc.Invoke(ctx, "/pb.Root/GetOwnConfirmations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rootClient) ExportSnapshot(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (Root_ExportSnapshotClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Root_serviceDesc.Streams[0], "/pb.Root/ExportSnapshot", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rootExportSnapshotClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Root_ExportSnapshotClient interface {
+	Recv() (*SnapshotChunk, error)
+	grpc.ClientStream
+}
+
+type rootExportSnapshotClient struct {
+	grpc.ClientStream
+}
+
+func (x *rootExportSnapshotClient) Recv() (*SnapshotChunk, error) {
+	m := new(SnapshotChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // RootServer is the server API for Root service.
 type RootServer interface {
 	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error)
@@ -1239,8 +1976,14 @@ type RootServer interface {
 	GetBlock(context.Context, *GetBlockRequest) (*GetBlockResponse, error)
 	Send(context.Context, *SendRequest) (*SendResponse, error)
 	Confirm(context.Context, *ConfirmRequest) (*ConfirmedTransaction, error)
+	GetConfirmationsChallenge(context.Context, *GetConfirmationsChallengeRequest) (*GetConfirmationsChallengeResponse, error)
 	GetConfirmations(context.Context, *GetConfirmationsRequest) (*GetConfirmationsResponse, error)
 	BlockHeight(context.Context, *EmptyRequest) (*BlockHeightResponse, error)
+	GetNodeInfo(context.Context, *EmptyRequest) (*GetNodeInfoResponse, error)
+	GetInclusionProof(context.Context, *GetInclusionProofRequest) (*GetInclusionProofResponse, error)
+	EstimateFee(context.Context, *EmptyRequest) (*EstimateFeeResponse, error)
+	GetOwnConfirmations(context.Context, *GetOwnConfirmationsRequest) (*GetConfirmationsResponse, error)
+	ExportSnapshot(*EmptyRequest, Root_ExportSnapshotServer) error
 }
 
 func RegisterRootServer(s *grpc.Server, srv RootServer) {
@@ -1337,6 +2080,24 @@ func _Root_Confirm_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Root_GetConfirmationsChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfirmationsChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RootServer).GetConfirmationsChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Root/GetConfirmationsChallenge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RootServer).GetConfirmationsChallenge(ctx, req.(*GetConfirmationsChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Root_GetConfirmations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetConfirmationsRequest)
 	if err := dec(in); err != nil {
@@ -1373,6 +2134,99 @@ func _Root_BlockHeight_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Root_GetNodeInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RootServer).GetNodeInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Root/GetNodeInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RootServer).GetNodeInfo(ctx, req.(*EmptyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Root_GetInclusionProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInclusionProofRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RootServer).GetInclusionProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Root/GetInclusionProof",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RootServer).GetInclusionProof(ctx, req.(*GetInclusionProofRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Root_EstimateFee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RootServer).EstimateFee(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Root/EstimateFee",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RootServer).EstimateFee(ctx, req.(*EmptyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Root_GetOwnConfirmations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOwnConfirmationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RootServer).GetOwnConfirmations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Root/GetOwnConfirmations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RootServer).GetOwnConfirmations(ctx, req.(*GetOwnConfirmationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Root_ExportSnapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EmptyRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RootServer).ExportSnapshot(m, &rootExportSnapshotServer{stream})
+}
+
+type Root_ExportSnapshotServer interface {
+	Send(*SnapshotChunk) error
+	grpc.ServerStream
+}
+
+type rootExportSnapshotServer struct {
+	grpc.ServerStream
+}
+
+func (x *rootExportSnapshotServer) Send(m *SnapshotChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Root_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.Root",
 	HandlerType: (*RootServer)(nil),
@@ -1397,6 +2251,10 @@ var _Root_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Confirm",
 			Handler:    _Root_Confirm_Handler,
 		},
+		{
+			MethodName: "GetConfirmationsChallenge",
+			Handler:    _Root_GetConfirmationsChallenge_Handler,
+		},
 		{
 			MethodName: "GetConfirmations",
 			Handler:    _Root_GetConfirmations_Handler,
@@ -1405,75 +2263,133 @@ var _Root_serviceDesc = grpc.ServiceDesc{
 			MethodName: "BlockHeight",
 			Handler:    _Root_BlockHeight_Handler,
 		},
+		{
+			MethodName: "GetNodeInfo",
+			Handler:    _Root_GetNodeInfo_Handler,
+		},
+		{
+			MethodName: "GetInclusionProof",
+			Handler:    _Root_GetInclusionProof_Handler,
+		},
+		{
+			MethodName: "EstimateFee",
+			Handler:    _Root_EstimateFee_Handler,
+		},
+		{
+			MethodName: "GetOwnConfirmations",
+			Handler:    _Root_GetOwnConfirmations_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportSnapshot",
+			Handler:       _Root_ExportSnapshot_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "root.proto",
 }
 
-func init() { proto.RegisterFile("root.proto", fileDescriptor_root_aef425b774122527) }
-
-var fileDescriptor_root_aef425b774122527 = []byte{
-	// 988 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xdd, 0x8e, 0xdb, 0x44,
-	0x14, 0x8e, 0x9d, 0x9f, 0x4d, 0x8e, 0xb3, 0x6d, 0x98, 0xa6, 0x5b, 0x2b, 0xac, 0x4a, 0x18, 0x55,
-	0xb0, 0x05, 0x75, 0xb5, 0x59, 0x24, 0x10, 0x2b, 0x71, 0xc1, 0x42, 0xd5, 0xae, 0x50, 0x77, 0xa5,
-	0x29, 0x2f, 0xe0, 0xc4, 0xd3, 0xc4, 0x6a, 0x32, 0x36, 0xf6, 0x98, 0x86, 0x1b, 0xae, 0x90, 0x78,
-	0x03, 0x78, 0x0e, 0xb8, 0xe5, 0x8d, 0x78, 0x8a, 0x6a, 0x7e, 0x3d, 0x49, 0x9c, 0xb6, 0xda, 0x3b,
-	0xcf, 0x39, 0xdf, 0x39, 0xfe, 0xce, 0x37, 0xe7, 0x1c, 0x1b, 0x20, 0x4f, 0x53, 0x7e, 0x9a, 0xe5,
-	0x29, 0x4f, 0x91, 0x9f, 0x4d, 0xf1, 0x1d, 0xe8, 0x3f, 0x5d, 0x65, 0xfc, 0x37, 0x42, 0x7f, 0x29,
-	0x69, 0xc1, 0xf1, 0x08, 0x3a, 0x97, 0xc9, 0xfc, 0x8a, 0x71, 0x34, 0x80, 0xe6, 0x82, 0xae, 0x43,
-	0x6f, 0xec, 0x9d, 0xf4, 0x88, 0x78, 0xc4, 0xff, 0x79, 0xd0, 0xbe, 0x62, 0x59, 0xc9, 0xd1, 0x10,
-	0xda, 0xe9, 0x1b, 0x46, 0x73, 0xe9, 0xed, 0x13, 0x75, 0x40, 0xa7, 0xd0, 0x8f, 0x69, 0x96, 0x16,
-	0x09, 0xbf, 0x4e, 0xd9, 0x8c, 0x86, 0xfe, 0xd8, 0x3b, 0x09, 0xce, 0xe1, 0x34, 0x9b, 0x9e, 0xaa,
-	0x9c, 0x64, 0xc3, 0x8f, 0x3e, 0x83, 0xee, 0x74, 0x99, 0xce, 0x5e, 0x5f, 0x97, 0xab, 0xb0, 0xb9,
-	0x83, 0xb5, 0x3e, 0x34, 0x86, 0x36, 0x5f, 0x5f, 0xc5, 0xeb, 0xb0, 0xb5, 0x03, 0x52, 0x0e, 0x84,
-	0xa1, 0x93, 0x96, 0x5c, 0x40, 0xda, 0x3b, 0x10, 0xed, 0xc1, 0x6b, 0xe8, 0xdc, 0x94, 0x5c, 0xb0,
-	0x1f, 0x41, 0x97, 0xd1, 0x37, 0x37, 0x4e, 0x01, 0xf6, 0x2c, 0x32, 0x45, 0xab, 0xb4, 0x64, 0xbc,
-	0x86, 0xbd, 0xf6, 0xec, 0xd4, 0xd9, 0x7c, 0x77, 0x9d, 0xf8, 0x4f, 0x0f, 0x82, 0x4b, 0x51, 0xcc,
-	0x73, 0x1a, 0xc5, 0x34, 0x47, 0x0f, 0x01, 0x56, 0x34, 0x7f, 0xbd, 0xa4, 0x24, 0x4d, 0xb9, 0x66,
-	0xe0, 0x58, 0xd0, 0x23, 0x38, 0xcc, 0x97, 0xd9, 0x8b, 0x0a, 0xe2, 0x4b, 0xc8, 0xa6, 0x51, 0x54,
-	0x91, 0xe5, 0xf4, 0xd7, 0xe7, 0x51, 0xb1, 0x90, 0x0c, 0xfa, 0xc4, 0x9e, 0xd1, 0x11, 0x74, 0x58,
-	0xb9, 0x9a, 0xd2, 0x5c, 0x4a, 0xd6, 0x22, 0xfa, 0x84, 0x7f, 0x84, 0xb6, 0x24, 0x82, 0x3e, 0x87,
-	0xce, 0x42, 0x92, 0x91, 0xaf, 0x0f, 0xce, 0xef, 0x4a, 0xf2, 0x15, 0x47, 0xa2, 0xdd, 0x08, 0x41,
-	0x6b, 0x21, 0xde, 0xa0, 0x28, 0xc8, 0x67, 0xfc, 0xb7, 0x0f, 0xc1, 0xcf, 0x79, 0xc4, 0x8a, 0x68,
-	0xc6, 0x93, 0x94, 0xa1, 0x4f, 0xa1, 0x93, 0x88, 0xb6, 0x38, 0xd3, 0xc9, 0x7a, 0x22, 0x99, 0x6c,
-	0x14, 0xa2, 0x1d, 0x22, 0x4d, 0x91, 0xcc, 0xcf, 0x4c, 0x1a, 0xf1, 0x6c, 0xc3, 0x26, 0x5a, 0xc0,
-	0x9d, 0xb0, 0x89, 0x0e, 0x9b, 0xc8, 0x2a, 0x54, 0xd8, 0x04, 0x3d, 0x82, 0x83, 0x54, 0xde, 0xe3,
-	0x99, 0x7b, 0xd9, 0xea, 0x6a, 0x89, 0x71, 0x55, 0xa8, 0x49, 0xd8, 0xd9, 0x87, 0x9a, 0xa0, 0x63,
-	0x68, 0xbe, 0xa2, 0x34, 0x3c, 0xd8, 0xb9, 0x40, 0x61, 0x16, 0x0a, 0xdb, 0xfe, 0xec, 0x4a, 0x1d,
-	0xab, 0x9e, 0x1c, 0x9a, 0x9e, 0xec, 0x8d, 0xbd, 0x93, 0x43, 0xdd, 0x87, 0x38, 0x81, 0xe1, 0x0f,
-	0x29, 0x7b, 0x95, 0xe4, 0x2b, 0x1a, 0xbb, 0x0a, 0x4d, 0x20, 0xe0, 0xd5, 0xd1, 0xd5, 0xdc, 0x41,
-	0x11, 0x17, 0x23, 0x9a, 0xa4, 0x48, 0xe6, 0x2c, 0xe2, 0x65, 0x4e, 0x8b, 0xd0, 0x1f, 0x37, 0x45,
-	0x93, 0x54, 0x16, 0xfc, 0x04, 0x3e, 0x7a, 0x46, 0xf9, 0x65, 0xb4, 0x8c, 0xd8, 0x8c, 0xea, 0xe9,
-	0x45, 0x21, 0x1c, 0x44, 0x71, 0x9c, 0xd3, 0xa2, 0xd0, 0x6d, 0x65, 0x8e, 0xf8, 0x02, 0x90, 0x0b,
-	0x2f, 0xb2, 0x94, 0x15, 0x54, 0xa8, 0x34, 0x55, 0x26, 0xcd, 0xc9, 0xd5, 0xc0, 0xb8, 0xf0, 0x4f,
-	0xf2, 0x55, 0x4a, 0xbb, 0xe2, 0xbd, 0xaf, 0x42, 0xc7, 0xd0, 0x2b, 0x32, 0xca, 0xe2, 0x68, 0xba,
-	0x54, 0x3b, 0xa0, 0x4b, 0x2a, 0x03, 0x8e, 0x25, 0x11, 0x9b, 0x4c, 0x13, 0xb9, 0x86, 0xfb, 0xb3,
-	0x1a, 0xe1, 0x44, 0xee, 0xe6, 0x49, 0x70, 0x1e, 0x0a, 0x5a, 0x75, 0xca, 0x92, 0xfa, 0x30, 0xfc,
-	0x18, 0xee, 0x8a, 0x72, 0xc5, 0x6d, 0x19, 0xc2, 0xd5, 0x4c, 0x78, 0x1b, 0x33, 0xf1, 0xbf, 0x07,
-	0x83, 0x0a, 0xab, 0xf9, 0x7c, 0x02, 0x6d, 0x79, 0xd5, 0x6e, 0x47, 0x2b, 0x84, 0xb2, 0xef, 0x27,
-	0xec, 0xdf, 0x8a, 0x30, 0xba, 0x80, 0xee, 0x8a, 0xf2, 0x28, 0x8e, 0x78, 0xa4, 0xc7, 0xe1, 0xa1,
-	0x48, 0xb1, 0x4d, 0x4c, 0x91, 0x78, 0x41, 0x79, 0x44, 0x2c, 0x7e, 0xf4, 0x18, 0x7a, 0xd6, 0x2c,
-	0xd4, 0x9f, 0xe5, 0x34, 0xe2, 0x34, 0xfe, 0x9e, 0xeb, 0x4a, 0x2b, 0x03, 0x7e, 0x0a, 0xc1, 0x4b,
-	0xca, 0x62, 0xa3, 0xc9, 0xd7, 0xd0, 0xb3, 0x74, 0x74, 0xa9, 0xfb, 0x99, 0x57, 0x50, 0xfc, 0x3b,
-	0xf4, 0x55, 0x1a, 0x2d, 0xd7, 0x2d, 0xf3, 0x88, 0xb8, 0x84, 0xcd, 0x96, 0x65, 0x21, 0xa6, 0xc2,
-	0xaf, 0xe2, 0x1c, 0xf8, 0x95, 0xf1, 0x93, 0x0a, 0x8a, 0xff, 0xf0, 0x60, 0x58, 0x87, 0x79, 0xef,
-	0x6a, 0x1d, 0x43, 0x60, 0x46, 0x58, 0x74, 0x82, 0x2f, 0xf5, 0x71, 0x4d, 0xe8, 0x0b, 0x18, 0x70,
-	0x37, 0x73, 0x4c, 0xd7, 0xf2, 0x42, 0x0e, 0xc9, 0x8e, 0x1d, 0xff, 0xe5, 0xc1, 0x1d, 0x5d, 0xa2,
-	0x51, 0x74, 0xeb, 0x05, 0xde, 0x87, 0xbd, 0xc0, 0xaf, 0x7f, 0x81, 0xd8, 0x40, 0x51, 0xc9, 0x17,
-	0x2f, 0xc5, 0xea, 0xd4, 0x3b, 0xde, 0x9c, 0x1d, 0x9f, 0xd9, 0x8f, 0xf6, 0x8c, 0xff, 0xf1, 0xe0,
-	0xc1, 0x33, 0xca, 0x35, 0xb7, 0x48, 0xb6, 0x98, 0x61, 0x38, 0x80, 0x66, 0x91, 0xcc, 0xb5, 0x36,
-	0xe2, 0x51, 0xec, 0x32, 0x66, 0x3f, 0xd8, 0x2d, 0xa2, 0x0e, 0xdb, 0x95, 0x34, 0x3f, 0xac, 0x92,
-	0xd6, 0x9e, 0x4a, 0xc6, 0x10, 0xa8, 0xa5, 0xab, 0x60, 0x6d, 0x09, 0x73, 0x4d, 0x98, 0x40, 0xb8,
-	0x4b, 0x59, 0xf7, 0x97, 0xab, 0x83, 0xf7, 0x0e, 0x1d, 0xfc, 0x2d, 0x1d, 0x9e, 0xc0, 0x3d, 0xfd,
-	0x51, 0x4b, 0xe6, 0x0b, 0x6e, 0xd3, 0x1d, 0x89, 0xaf, 0x9f, 0xb0, 0x98, 0x55, 0xa0, 0x4e, 0xe7,
-	0xff, 0x36, 0xa1, 0x25, 0xdb, 0xe4, 0x3b, 0x80, 0x6a, 0x5b, 0xa2, 0xfb, 0x66, 0x12, 0x37, 0x96,
-	0xed, 0xe8, 0x68, 0xdb, 0xac, 0xb2, 0xe3, 0x86, 0x0e, 0xd7, 0x3b, 0xce, 0x86, 0x6f, 0x2e, 0x50,
-	0x1b, 0xbe, 0xb5, 0x0a, 0x71, 0x03, 0x7d, 0x03, 0x5d, 0x33, 0xf7, 0xe8, 0xde, 0xe6, 0x16, 0x50,
-	0xa1, 0xc3, 0xba, 0xd5, 0x80, 0x1b, 0xe8, 0x4b, 0x68, 0x89, 0xb1, 0x44, 0xf2, 0xcb, 0xe2, 0xcc,
-	0xf9, 0x68, 0x50, 0x19, 0x2c, 0xf8, 0x5b, 0x38, 0xd0, 0x62, 0x23, 0xe4, 0xcc, 0xaa, 0x09, 0xd9,
-	0x3b, 0xbf, 0xb8, 0x81, 0x6e, 0xe4, 0xc6, 0xdc, 0xb8, 0x2a, 0xf4, 0xb1, 0xe6, 0x54, 0xd7, 0x73,
-	0xa3, 0xe3, 0x7a, 0xa7, 0xe5, 0x72, 0x61, 0x7f, 0x90, 0xc4, 0x3d, 0x20, 0x49, 0xd7, 0xfd, 0x2d,
-	0x1d, 0x3d, 0x70, 0xfe, 0x4f, 0xdc, 0xab, 0xc4, 0x8d, 0x69, 0x47, 0xfe, 0xcc, 0x7e, 0xf5, 0x36,
-	0x00, 0x00, 0xff, 0xff, 0x44, 0xbd, 0xa6, 0x42, 0xda, 0x0a, 0x00, 0x00,
+func init() { proto.RegisterFile("root.proto", fileDescriptor_root_f007dfff6d39e493) }
+
+var fileDescriptor_root_f007dfff6d39e493 = []byte{
+	// 1568 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x58, 0xdf, 0x6e, 0x1b, 0x45,
+	0x17, 0xcf, 0x7a, 0x6d, 0x27, 0x39, 0x8e, 0x93, 0x74, 0x92, 0xa6, 0xfb, 0xf9, 0xeb, 0xd7, 0xcf,
+	0x1d, 0x42, 0x49, 0x41, 0x8d, 0x92, 0x22, 0x81, 0x5a, 0x89, 0x8b, 0xfe, 0x09, 0x6d, 0x84, 0x9a,
+	0x56, 0x9b, 0xf2, 0x00, 0x63, 0xef, 0xc4, 0x5e, 0x6a, 0xcf, 0x2c, 0x3b, 0xe3, 0xd6, 0xbd, 0xe1,
+	0x0a, 0x81, 0x04, 0x12, 0x17, 0xbc, 0x04, 0xd7, 0x70, 0x09, 0x4f, 0xc2, 0x2b, 0xf0, 0x0c, 0x5c,
+	0xa0, 0xf9, 0xb3, 0xbb, 0xe3, 0xf5, 0x3a, 0xa9, 0x8a, 0x7a, 0xb7, 0xe7, 0xef, 0xfc, 0xce, 0x99,
+	0x73, 0xce, 0x1c, 0x1b, 0x20, 0xe5, 0x5c, 0xee, 0x27, 0x29, 0x97, 0x1c, 0xd5, 0x92, 0x1e, 0x5e,
+	0x87, 0xb5, 0xa3, 0x71, 0x22, 0x5f, 0x87, 0xf4, 0xeb, 0x09, 0x15, 0x12, 0x77, 0xa0, 0x79, 0x3f,
+	0x1e, 0x1c, 0x33, 0x89, 0x36, 0xc1, 0x1f, 0xd2, 0x69, 0xe0, 0x75, 0xbd, 0xbd, 0xd5, 0x50, 0x7d,
+	0xe2, 0x3f, 0x3c, 0x68, 0x1c, 0xb3, 0x64, 0x22, 0xd1, 0x36, 0x34, 0xf8, 0x2b, 0x46, 0x53, 0x2d,
+	0x5d, 0x0b, 0x0d, 0x81, 0xf6, 0x61, 0x2d, 0xa2, 0x09, 0x17, 0xb1, 0x3c, 0xe1, 0xac, 0x4f, 0x83,
+	0x5a, 0xd7, 0xdb, 0x6b, 0xdd, 0x86, 0xfd, 0xa4, 0xb7, 0x6f, 0x7c, 0x86, 0x33, 0x72, 0x74, 0x03,
+	0x56, 0x7a, 0x23, 0xde, 0x7f, 0x71, 0x32, 0x19, 0x07, 0xfe, 0x9c, 0x6e, 0x2e, 0x43, 0x5d, 0x68,
+	0xc8, 0xe9, 0x71, 0x34, 0x0d, 0xea, 0x73, 0x4a, 0x46, 0x80, 0x30, 0x34, 0xf9, 0x44, 0x2a, 0x95,
+	0xc6, 0x9c, 0x8a, 0x95, 0xe0, 0x29, 0x34, 0x9f, 0x4e, 0xa4, 0x42, 0xdf, 0x81, 0x15, 0x46, 0x5f,
+	0x3d, 0x75, 0x02, 0xc8, 0x69, 0xe5, 0x89, 0x8c, 0xf9, 0x84, 0xc9, 0x0a, 0xf4, 0x56, 0x32, 0x17,
+	0xa7, 0x7f, 0x7e, 0x9c, 0xf8, 0x7b, 0x0f, 0x5a, 0xf7, 0x55, 0x30, 0x8f, 0x29, 0x89, 0x68, 0x8a,
+	0xae, 0x01, 0x8c, 0x69, 0xfa, 0x62, 0x44, 0x43, 0xce, 0xa5, 0x45, 0xe0, 0x70, 0xd0, 0x2e, 0xb4,
+	0xd3, 0x51, 0xf2, 0xa4, 0x50, 0xa9, 0x69, 0x95, 0x59, 0xa6, 0x8a, 0x22, 0x49, 0xe9, 0xcb, 0xc7,
+	0x44, 0x0c, 0x35, 0x82, 0xb5, 0x30, 0xa7, 0xd1, 0x0e, 0x34, 0xd9, 0x64, 0xdc, 0xa3, 0xa9, 0x4e,
+	0x59, 0x3d, 0xb4, 0x14, 0x7e, 0x08, 0x0d, 0x0d, 0x04, 0x7d, 0x00, 0xcd, 0xa1, 0x06, 0xa3, 0x8f,
+	0x6f, 0xdd, 0xde, 0xd0, 0xe0, 0x0b, 0x8c, 0xa1, 0x15, 0x23, 0x04, 0xf5, 0xa1, 0x3a, 0xc1, 0x40,
+	0xd0, 0xdf, 0xf8, 0x27, 0x1f, 0x5a, 0xcf, 0x53, 0xc2, 0x04, 0xe9, 0xcb, 0x98, 0x33, 0x74, 0x1d,
+	0x9a, 0xb1, 0x2a, 0x8b, 0x03, 0xeb, 0x6c, 0x55, 0x39, 0xd3, 0x85, 0x12, 0x5a, 0x81, 0x72, 0x23,
+	0xe2, 0xc1, 0x41, 0xe6, 0x46, 0x7d, 0xe7, 0x66, 0x87, 0x81, 0x5f, 0x6d, 0x76, 0x68, 0xcd, 0x0e,
+	0x83, 0x7a, 0x6e, 0x76, 0x88, 0x76, 0x61, 0x99, 0xeb, 0x7b, 0x3c, 0x70, 0x2f, 0xdb, 0x5c, 0x6d,
+	0x98, 0x89, 0x0a, 0xad, 0xc3, 0xa0, 0xb9, 0x48, 0xeb, 0x10, 0x5d, 0x05, 0xff, 0x8c, 0xd2, 0x60,
+	0x79, 0xee, 0x02, 0x15, 0x5b, 0x65, 0x38, 0xaf, 0xcf, 0x15, 0x9d, 0xc7, 0x9c, 0x56, 0x1d, 0x60,
+	0x6a, 0x72, 0xb5, 0xeb, 0xed, 0xb5, 0xb3, 0x3a, 0x0c, 0x60, 0xf9, 0x25, 0x4d, 0x45, 0xcc, 0x59,
+	0x00, 0x9a, 0x9f, 0x91, 0x79, 0xb0, 0x22, 0x68, 0x75, 0xfd, 0xaa, 0x60, 0x85, 0x0d, 0x56, 0x04,
+	0x6b, 0x5d, 0xdf, 0x06, 0x2b, 0x8a, 0x30, 0x44, 0xd0, 0xee, 0xfa, 0x19, 0xc8, 0xd9, 0x30, 0x04,
+	0x8e, 0x61, 0xfb, 0x01, 0x67, 0x67, 0x71, 0x3a, 0xa6, 0x91, 0x7b, 0x31, 0x87, 0xd0, 0x92, 0x05,
+	0xe9, 0x5e, 0xb5, 0xa3, 0x15, 0xba, 0x3a, 0xaa, 0x36, 0x45, 0x3c, 0x60, 0x44, 0x4e, 0x52, 0x2a,
+	0x82, 0x9a, 0x86, 0xe2, 0x70, 0xf0, 0x2d, 0xb8, 0xf4, 0x88, 0xca, 0xfb, 0x64, 0x44, 0x58, 0x9f,
+	0xda, 0xa1, 0xa1, 0xc2, 0x26, 0x51, 0x94, 0x52, 0x21, 0x6c, 0x35, 0x67, 0x24, 0xbe, 0x0b, 0xc8,
+	0x55, 0x17, 0x09, 0x67, 0x82, 0xaa, 0xa8, 0x7a, 0x86, 0x65, 0x31, 0xb9, 0xa9, 0xcf, 0x44, 0xf8,
+	0x0b, 0x7d, 0x94, 0x89, 0x55, 0x5c, 0x78, 0x14, 0xba, 0x0a, 0xab, 0x22, 0xa1, 0x2c, 0x22, 0xbd,
+	0x91, 0x19, 0x3d, 0x2b, 0x61, 0xc1, 0xc0, 0x3f, 0x78, 0x80, 0x5c, 0x6f, 0x16, 0xc9, 0x09, 0x5c,
+	0xee, 0x57, 0x64, 0x4e, 0x39, 0x57, 0xd9, 0x0e, 0x14, 0xae, 0xaa, 0xd4, 0x86, 0xd5, 0x66, 0xaa,
+	0x75, 0xcd, 0xa5, 0x1c, 0xb3, 0x88, 0x4e, 0x6d, 0x06, 0xdb, 0xe1, 0x2c, 0x13, 0xdf, 0x84, 0x0d,
+	0x95, 0x15, 0x55, 0x4b, 0x59, 0x5c, 0x45, 0xc7, 0x7a, 0x33, 0x1d, 0xfb, 0x97, 0x07, 0x9b, 0x85,
+	0xae, 0x45, 0xfd, 0x7f, 0x68, 0xe8, 0x42, 0x74, 0xfb, 0xcd, 0x68, 0x18, 0xfe, 0xe2, 0xb0, 0x6a,
+	0x6f, 0x17, 0xd6, 0x5d, 0x58, 0x19, 0x53, 0x49, 0x22, 0x22, 0x89, 0x6d, 0xd6, 0x6b, 0xca, 0x45,
+	0x19, 0x98, 0x01, 0xf1, 0x84, 0x4a, 0x12, 0xe6, 0xfa, 0x9d, 0x9b, 0xb0, 0x9a, 0xb3, 0xd5, 0x25,
+	0xf5, 0x53, 0x4a, 0x24, 0x8d, 0xee, 0x49, 0x1b, 0x69, 0xc1, 0xc0, 0x47, 0xd0, 0x3a, 0xa5, 0x2c,
+	0xca, 0x72, 0xf2, 0x09, 0xac, 0xe6, 0x70, 0x6c, 0xa8, 0x8b, 0x91, 0x17, 0xaa, 0xf8, 0x1b, 0x58,
+	0x33, 0x6e, 0x6c, 0xba, 0xde, 0xd2, 0x8f, 0xb2, 0x8b, 0x59, 0x7f, 0x34, 0xd1, 0xfd, 0x5c, 0x2b,
+	0xec, 0x1c, 0xf5, 0xe3, 0x4c, 0x1e, 0x16, 0xaa, 0xf8, 0x5b, 0x0f, 0xb6, 0xab, 0x74, 0x2e, 0x1c,
+	0xfc, 0x5d, 0x68, 0x65, 0x03, 0x46, 0x55, 0x42, 0x4d, 0xe7, 0xc7, 0x65, 0xa1, 0x0f, 0x61, 0x53,
+	0xba, 0x9e, 0x23, 0x3a, 0xd5, 0x17, 0xd2, 0x0e, 0xe7, 0xf8, 0xf8, 0x37, 0x0f, 0xd6, 0x6d, 0x88,
+	0x59, 0x46, 0x4b, 0x07, 0x78, 0x6f, 0x76, 0x40, 0xad, 0xfa, 0x00, 0x35, 0x1f, 0xc9, 0x44, 0x0e,
+	0x4f, 0xd5, 0x60, 0xb7, 0x2f, 0x50, 0x46, 0x3b, 0xb2, 0x6c, 0x7a, 0xe7, 0xb4, 0x23, 0x13, 0x41,
+	0x43, 0x4f, 0x98, 0x9c, 0xc6, 0xbf, 0x7a, 0x70, 0xe5, 0x11, 0x95, 0x16, 0x37, 0xd1, 0xe5, 0x97,
+	0xa1, 0xdf, 0x04, 0x5f, 0xc4, 0x03, 0x9b, 0x37, 0xf5, 0x59, 0x8e, 0xc7, 0x7f, 0xb3, 0x78, 0xea,
+	0x0b, 0xe2, 0xe9, 0x42, 0xcb, 0xe9, 0x53, 0xfd, 0xba, 0xb4, 0x43, 0x97, 0xa5, 0xa6, 0x3e, 0xd3,
+	0x4f, 0x7e, 0xd3, 0xec, 0x3d, 0x9a, 0xc0, 0x3f, 0x7b, 0xd0, 0x2d, 0x63, 0x7e, 0x30, 0x24, 0xa3,
+	0x11, 0x65, 0x03, 0xfa, 0x6e, 0x52, 0x5f, 0x82, 0xea, 0xcf, 0x41, 0xc5, 0xbf, 0x78, 0x70, 0xfd,
+	0x1c, 0x50, 0xb6, 0x35, 0xf2, 0x80, 0x3c, 0x27, 0x20, 0xd5, 0xa5, 0x74, 0x9a, 0xc4, 0x29, 0x15,
+	0xf7, 0xa4, 0xad, 0xc2, 0x82, 0x81, 0xf6, 0x60, 0xa3, 0xcf, 0x99, 0x4c, 0x49, 0x5f, 0xde, 0xb3,
+	0xa3, 0xd8, 0xdc, 0x7e, 0x99, 0xad, 0xe6, 0x7c, 0x7f, 0x48, 0x62, 0x76, 0x1c, 0x55, 0xac, 0x6e,
+	0x99, 0x08, 0x33, 0x08, 0xe6, 0x6f, 0xdc, 0xe2, 0x73, 0x4b, 0xcc, 0x3b, 0xa7, 0xc4, 0x6a, 0xe7,
+	0x94, 0x98, 0x5f, 0x2a, 0xb1, 0xdf, 0x3d, 0xe8, 0xa8, 0xa7, 0xe0, 0x15, 0x7b, 0xbb, 0x2a, 0xfb,
+	0x77, 0x6d, 0x39, 0x9b, 0xdc, 0x7a, 0x39, 0xb9, 0xd7, 0x00, 0xf4, 0x3a, 0xe0, 0x96, 0xa0, 0xc3,
+	0xc1, 0xb7, 0x60, 0xcb, 0xae, 0x69, 0xf1, 0x60, 0x28, 0xf3, 0x3c, 0xed, 0xa8, 0x7d, 0x4e, 0x71,
+	0xb2, 0xe7, 0xc3, 0x50, 0xf8, 0xbb, 0x1a, 0xc0, 0xe9, 0x6b, 0xd6, 0x3f, 0x95, 0x44, 0x4e, 0x04,
+	0xba, 0x01, 0xeb, 0x54, 0x0e, 0x69, 0x4a, 0x27, 0xe3, 0xc7, 0xae, 0x7a, 0x89, 0xab, 0xae, 0x78,
+	0x44, 0x84, 0x7c, 0x68, 0xb6, 0xd8, 0x67, 0x7c, 0x34, 0xb2, 0x51, 0x97, 0xd9, 0xca, 0xa3, 0x62,
+	0x3d, 0x9f, 0x1e, 0x4d, 0xad, 0xa2, 0x69, 0xc2, 0x12, 0x57, 0xe5, 0x70, 0x44, 0x24, 0x15, 0xe6,
+	0xc1, 0xb0, 0x71, 0xbb, 0x2c, 0xb4, 0x0f, 0x48, 0xd9, 0x9c, 0x4e, 0x7a, 0xe3, 0x58, 0x4a, 0x1a,
+	0x19, 0xc5, 0x86, 0x56, 0xac, 0x90, 0xa8, 0xd2, 0x4d, 0x29, 0x89, 0x5e, 0xeb, 0x5e, 0x5c, 0x09,
+	0x0d, 0xa1, 0x12, 0x91, 0x52, 0x22, 0x38, 0xd3, 0x4b, 0xdd, 0x6a, 0x68, 0x29, 0xfc, 0xa7, 0x07,
+	0x5b, 0x8f, 0xa8, 0x3c, 0xe1, 0x11, 0x3d, 0x66, 0x67, 0x3c, 0x4f, 0x5c, 0x45, 0x31, 0x7b, 0xd5,
+	0xc5, 0xbc, 0x07, 0x1b, 0x3c, 0xa1, 0x29, 0x91, 0x3c, 0xcd, 0x34, 0x4d, 0xd5, 0x95, 0xd9, 0xee,
+	0x16, 0xe8, 0x6b, 0x10, 0x19, 0x89, 0xf6, 0x01, 0x44, 0x7e, 0x1b, 0xb6, 0x27, 0xd6, 0x55, 0x4f,
+	0x14, 0x77, 0x14, 0x3a, 0x1a, 0x6e, 0x03, 0x35, 0x16, 0x37, 0xd0, 0x50, 0x37, 0x50, 0xfe, 0xcc,
+	0x3c, 0x4b, 0x39, 0x3f, 0x7b, 0x27, 0x63, 0x07, 0x0f, 0xe0, 0x3f, 0x15, 0x27, 0xd9, 0x54, 0x76,
+	0xe7, 0xb7, 0xcd, 0xb5, 0xd9, 0xe5, 0x72, 0x1b, 0x1a, 0x89, 0x32, 0xb1, 0x89, 0x33, 0x84, 0xda,
+	0x7b, 0x53, 0xf5, 0x1e, 0x9a, 0x21, 0xa2, 0xbf, 0xf1, 0x8f, 0x1e, 0x6c, 0x1d, 0x09, 0x19, 0x8f,
+	0x89, 0xa4, 0x9f, 0xd3, 0x62, 0x5e, 0xd9, 0x85, 0xdd, 0xab, 0x5e, 0xd8, 0x77, 0xa1, 0x2d, 0xc8,
+	0x38, 0x19, 0xd9, 0x12, 0x11, 0xb6, 0x68, 0x67, 0x99, 0xe8, 0x00, 0xb6, 0x2c, 0x63, 0x66, 0x35,
+	0x32, 0x75, 0x5b, 0x25, 0xc2, 0x04, 0x5a, 0x47, 0x69, 0xca, 0xd3, 0x87, 0x54, 0x92, 0x78, 0xe4,
+	0xd4, 0x98, 0xe7, 0xd6, 0x98, 0x1a, 0x3a, 0x43, 0x22, 0xf4, 0x52, 0x6f, 0x17, 0xd0, 0x9c, 0x2e,
+	0xf5, 0xb5, 0x3f, 0xd7, 0xd7, 0xef, 0x41, 0xfb, 0x94, 0x91, 0x44, 0x0c, 0xb9, 0x7c, 0x30, 0x9c,
+	0xb0, 0x17, 0x2a, 0x2b, 0x7a, 0xdd, 0x32, 0x69, 0xd4, 0xdf, 0xb7, 0xff, 0x6e, 0x42, 0x5d, 0x2f,
+	0x0a, 0x9f, 0x01, 0x14, 0x6b, 0x35, 0xba, 0x9c, 0xed, 0x62, 0x33, 0x5b, 0x79, 0x67, 0xa7, 0xcc,
+	0x36, 0x39, 0xc4, 0x4b, 0xd6, 0xdc, 0xee, 0xc2, 0xb9, 0xf9, 0xec, 0xa6, 0xdd, 0xd9, 0x29, 0xb3,
+	0x73, 0xf3, 0x4f, 0x61, 0x25, 0xdb, 0xfc, 0xd0, 0xd6, 0xec, 0x1e, 0x68, 0x4c, 0xb7, 0xab, 0x96,
+	0x43, 0xbc, 0x84, 0x3e, 0x82, 0xba, 0x5a, 0xcc, 0x90, 0xfe, 0x09, 0xe2, 0x6c, 0x7a, 0x9d, 0xcd,
+	0x82, 0x91, 0x2b, 0xdf, 0x81, 0x65, 0x3b, 0x9f, 0x11, 0x72, 0xb6, 0xb5, 0xcc, 0x64, 0xe1, 0x06,
+	0x87, 0x97, 0xd0, 0x57, 0xba, 0x4c, 0xab, 0x9f, 0x3e, 0xb4, 0x6b, 0xc1, 0x9d, 0xfb, 0x5c, 0x77,
+	0xde, 0xbf, 0x40, 0x2b, 0x87, 0xf9, 0x14, 0x36, 0xcb, 0x6a, 0xe8, 0xbf, 0x55, 0xc6, 0x99, 0xe7,
+	0xab, 0xd5, 0xc2, 0xdc, 0xe1, 0xdd, 0xfc, 0xcf, 0x02, 0x3d, 0x8a, 0x75, 0x6a, 0xdc, 0xbf, 0x68,
+	0x3a, 0x57, 0x9c, 0xdf, 0xea, 0xee, 0x23, 0x60, 0x6c, 0x9d, 0x21, 0xb7, 0xc8, 0xb6, 0x62, 0x0e,
+	0xe2, 0x25, 0x14, 0xc2, 0xa5, 0xb9, 0xde, 0x46, 0x19, 0xd8, 0xca, 0xe1, 0xd2, 0xf9, 0xdf, 0x02,
+	0xa9, 0x8b, 0xc7, 0xe9, 0xe2, 0x45, 0x78, 0x2a, 0x1a, 0x1d, 0x2f, 0xa1, 0x2f, 0x61, 0xab, 0xe2,
+	0x95, 0x46, 0xd9, 0x0f, 0x8f, 0x05, 0xcf, 0xf7, 0x85, 0xe9, 0xbd, 0x03, 0xeb, 0x47, 0xd3, 0x84,
+	0xa7, 0x32, 0x6b, 0xb7, 0x0a, 0x54, 0x97, 0x74, 0x39, 0xba, 0xed, 0x88, 0x97, 0x0e, 0xbc, 0x5e,
+	0x53, 0xff, 0x6d, 0xf6, 0xf1, 0x3f, 0x03, 0x00, 0xbc, 0xe6, 0x56, 0xbe, 0x44, 0x13, 0x00, 0x00,
 }
@@ -43,6 +43,9 @@ func DeserializeConfirmedTxs(confirmedTransactions []*pb.ConfirmedTransaction) (
 }
 
 func SerializeTx(tx *chain.Transaction) (*pb.Transaction) {
+	if tx.IsMulti() {
+		return serializeMultiTx(tx)
+	}
 	return &pb.Transaction{
 		Input0:   SerializeInput(tx.Input0),
 		Sig0:     tx.Sig0[:],
@@ -56,7 +59,30 @@ func SerializeTx(tx *chain.Transaction) (*pb.Transaction) {
 	}
 }
 
+func serializeMultiTx(tx *chain.Transaction) (*pb.Transaction) {
+	result := &pb.Transaction{
+		Version:  uint32(tx.Version),
+		Fee:      SerializeBig(tx.Fee),
+		BlockNum: tx.BlkNum,
+		TxIdx:    tx.TxIdx,
+	}
+	for _, input := range tx.Inputs {
+		result.Inputs = append(result.Inputs, SerializeInput(input))
+	}
+	for _, sig := range tx.Sigs {
+		result.Sigs = append(result.Sigs, append([]byte{}, sig[:]...))
+	}
+	for _, output := range tx.Outputs {
+		result.Outputs = append(result.Outputs, SerializeOutput(output))
+	}
+	return result
+}
+
 func DeserializeTx(tx *pb.Transaction) (*chain.Transaction) {
+	if tx != nil && tx.Version == uint32(chain.TxVersionMulti) {
+		return deserializeMultiTx(tx)
+	}
+
 	result := chain.ZeroTransaction()
 	if tx != nil {
 		result.Input0 = DeserializeInput(tx.Input0)
@@ -73,13 +99,36 @@ func DeserializeTx(tx *pb.Transaction) (*chain.Transaction) {
 	return result
 }
 
+func deserializeMultiTx(tx *pb.Transaction) (*chain.Transaction) {
+	inputs := make([]*chain.Input, len(tx.Inputs))
+	for i, input := range tx.Inputs {
+		inputs[i] = DeserializeInput(input)
+	}
+	sigs := make([]chain.Signature, len(tx.Sigs))
+	for i, sig := range tx.Sigs {
+		copy(sigs[i][:], sig)
+	}
+	outputs := make([]*chain.Output, len(tx.Outputs))
+	for i, output := range tx.Outputs {
+		outputs[i] = DeserializeOutput(output)
+	}
+
+	result := chain.NewMultiTransaction(inputs, sigs, outputs, DeserializeBig(tx.Fee))
+	result.BlkNum = tx.BlockNum
+	result.TxIdx = tx.TxIdx
+	return result
+}
+
 func SerializeConfirmedTx(confirmed *chain.ConfirmedTransaction) (*pb.ConfirmedTransaction) {
 	result := &pb.ConfirmedTransaction{
 		Transaction: SerializeTx(&confirmed.Transaction),
 	}
-	result.Signatures = make([][]byte, 2)
-	result.Signatures[0] = append(result.Signatures[0], confirmed.Signatures[0][:]...)
-	result.Signatures[1] = append(result.Signatures[1], confirmed.Signatures[1][:]...)
+	numInputs := confirmed.Transaction.NumInputs()
+	result.Signatures = make([][]byte, numInputs)
+	for i := uint8(0); i < numInputs; i++ {
+		sig := confirmed.ConfirmSigAt(i)
+		result.Signatures[i] = append(result.Signatures[i], sig[:]...)
+	}
 
 	return result;
 }
@@ -88,9 +137,11 @@ func DeserializeConfirmedTx(confirmed *pb.ConfirmedTransaction) (*chain.Confirme
 	result := &chain.ConfirmedTransaction{
 		Transaction: *DeserializeTx(confirmed.Transaction),
 	}
-	copy(result.Signatures[0][:], confirmed.Signatures[0][0:65])
-	if len(confirmed.Signatures) > 1 {
-		copy(result.Signatures[1][:], confirmed.Signatures[1][0:65])
+	result.Signatures = make([]chain.Signature, result.Transaction.NumInputs())
+	for i := range result.Signatures {
+		if i < len(confirmed.Signatures) {
+			copy(result.Signatures[i][:], confirmed.Signatures[i])
+		}
 	}
 	return result;
 }